@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+)
+
+const knowledgeUsage = `usage: slack-bot knowledge lint [-format text|junit] [-output <file>] [-strict] <dir>`
+
+// errLintFailed is returned when the knowledge assets have problems. The problems are in the report.
+var errLintFailed = errors.New("the knowledge assets have problems")
+
+// runKnowledge runs the knowledge subcommands. `knowledge lint` checks the knowledge assets in a directory and
+// reports the problems as text or JUnit XML.
+func runKnowledge(args []string) error {
+	if len(args) == 0 || args[0] != "lint" {
+		return errors.New(knowledgeUsage)
+	}
+	flags := flag.NewFlagSet("knowledge lint", flag.ExitOnError)
+	format := flags.String("format", "text", "The format of the report, text or junit")
+	output := flags.String("output", "", "The file the report is written to. Defaults to stdout.")
+	strict := flags.Bool("strict", false, "Fail on warnings, such as assets without examples or which match the examples of other assets")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(knowledgeUsage)
+	}
+	if *format != "text" && *format != "junit" {
+		return fmt.Errorf("unknown format %q, expected text or junit", *format)
+	}
+
+	report, err := knowledge.Lint(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if len(*output) > 0 {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("unable to create report: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if *format == "junit" {
+		err = report.WriteJUnit(w, *strict)
+	} else {
+		err = report.WriteText(w, *strict)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write report: %v", err)
+	}
+	if report.Failed(*strict) {
+		return errLintFailed
+	}
+	return nil
+}
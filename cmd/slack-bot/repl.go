@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/commands"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/fakeslack"
+	"github.com/openshift-splat-team/splat-bot/pkg/plugins"
+	slackutil "github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	replBotUserID = "U0SPLATBOT"
+
+	replHelp = `Messages are sent to the current channel as the current user. Start a message with @bot to mention the bot.
+  /user <id>       send messages as another user
+  /channel <id>    send messages to another channel. channels starting with D are DMs
+  /thread [<ts>]   reply in the thread of a message, or leave the thread
+  /help            show this help
+  /quit            exit`
+)
+
+// runREPL reads messages from stdin, handles them with the commands of the enabled plugins in an in-memory
+// workspace and prints the responses of the bot. No Slack tokens are needed.
+func runREPL(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("repl", flag.ExitOnError)
+	user := flags.String("user", "U0REPL", "The user ID messages are sent as")
+	channel := flags.String("channel", "C0REPL", "The channel ID messages are sent to")
+	enabled := flags.String("plugins", "core,knowledge", "Comma separated plugins to enable. The ci plugin requires a cluster.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// logs would be interleaved with the conversation
+	log.SetOutput(os.Stderr)
+
+	if len(cfg.Slack.BotUserID) == 0 {
+		cfg.Slack.BotUserID = replBotUserID
+	}
+	slackutil.SetSlackConfig(cfg.Slack)
+	slackutil.SetLLMConfig(cfg.LLM)
+	slackutil.SetJiraConfig(cfg.Jira)
+
+	workspace := fakeslack.NewWorkspace(cfg.Slack.BotUserID)
+	workspace.Watch(func(action fakeslack.Action, msg fakeslack.Message) {
+		if msg.User == workspace.BotUserID || action != fakeslack.ActionPosted {
+			printMessage(os.Stdout, action, msg)
+		}
+	})
+
+	if err := commands.Initialize(ctx, workspace, cfg); err != nil {
+		return fmt.Errorf("unable to initialize commands: %v", err)
+	}
+	manager, err := plugins.NewManager(availablePlugins(workspace), strings.Split(*enabled, ","))
+	if err != nil {
+		return fmt.Errorf("unable to enable plugins: %v", err)
+	}
+	if err := manager.Start(ctx, cfg); err != nil {
+		return err
+	}
+	defer func() {
+		if err := manager.Stop(context.WithoutCancel(ctx)); err != nil {
+			log.Warnf("%v", err)
+		}
+	}()
+
+	fmt.Println(replHelp)
+	threadTS := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		prompt := fmt.Sprintf("%s in %s", *user, *channel)
+		if len(threadTS) > 0 {
+			prompt = fmt.Sprintf("%s thread %s", prompt, threadTS)
+		}
+		fmt.Printf("%s> ", prompt)
+		if !scanner.Scan() || ctx.Err() != nil {
+			fmt.Println()
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			fields := strings.Fields(line)
+			switch {
+			case fields[0] == "/quit":
+				return nil
+			case fields[0] == "/user" && len(fields) == 2:
+				*user = fields[1]
+			case fields[0] == "/channel" && len(fields) == 2:
+				*channel = fields[1]
+				threadTS = ""
+			case fields[0] == "/thread" && len(fields) <= 2:
+				threadTS = ""
+				if len(fields) == 2 {
+					threadTS = fields[1]
+				}
+			default:
+				fmt.Println(replHelp)
+			}
+			continue
+		}
+
+		if mention, found := strings.CutPrefix(line, "@bot"); found {
+			line = fmt.Sprintf("<@%s>%s", workspace.BotUserID, mention)
+		}
+		evt := workspace.UserMessage(*channel, *user, line, threadTS)
+		if err := commands.Handler(ctx, workspace, evt); err != nil {
+			fmt.Printf("error: %v\n", err)
+		}
+	}
+}
+
+// printMessage prints a change to a message made by the bot.
+func printMessage(w io.Writer, action fakeslack.Action, msg fakeslack.Message) {
+	var where []string
+	if len(msg.ThreadTimestamp) > 0 {
+		where = append(where, "thread "+msg.ThreadTimestamp)
+	}
+	if len(msg.EphemeralTo) > 0 {
+		where = append(where, "only visible to "+msg.EphemeralTo)
+	}
+	if len(msg.ResponseURL) > 0 {
+		where = append(where, "response URL")
+	}
+	header := fmt.Sprintf("[%s %s] %s %s", msg.Channel, msg.Timestamp, msg.User, action)
+	if len(where) > 0 {
+		header = fmt.Sprintf("%s (%s)", header, strings.Join(where, ", "))
+	}
+
+	switch action {
+	case fakeslack.ActionDeleted:
+		fmt.Fprintln(w, header)
+	case fakeslack.ActionReacted:
+		var reactions []string
+		for _, reaction := range msg.Reactions {
+			reactions = append(reactions, fmt.Sprintf(":%s:", reaction.Name))
+		}
+		fmt.Fprintf(w, "%s: %s\n", header, strings.Join(reactions, " "))
+	default:
+		fmt.Fprintf(w, "%s:\n%s\n", header, indent(fakeslack.Render(msg)))
+	}
+}
+
+func indent(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -4,25 +4,36 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slack-go/slack"
 
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
 	"github.com/openshift-splat-team/splat-bot/pkg/commands"
-	_ "github.com/openshift-splat-team/splat-bot/pkg/controllers"
-	_ "github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+	"github.com/openshift-splat-team/splat-bot/pkg/plugins"
 	slackutil "github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack/socketmode"
 
 	events "github.com/slack-go/slack/slackevents"
 )
 
+// shutdownTimeout how long plugins are given to stop once the bot is shutting down.
+const shutdownTimeout = 30 * time.Second
+
 type CustomFormatter struct{}
 
 func (f *CustomFormatter) Format(entry *log.Entry) ([]byte, error) {
@@ -35,11 +46,25 @@ func (f *CustomFormatter) Format(entry *log.Entry) ([]byte, error) {
 	return []byte(logMessage), nil
 }
 
+// availablePlugins returns the plugins which may be enabled. Plugins which post on their own use client.
+func availablePlugins(client slackutil.SlackClientInterface) []plugins.Plugin {
+	return []plugins.Plugin{
+		commands.NewCorePlugin(),
+		commands.NewGitHubPlugin(),
+		commands.NewCIPlugin(),
+		knowledge.NewPlugin(),
+		commands.NewSchedulerPlugin(client),
+	}
+}
+
 func main() {
-	ctx := context.TODO()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Define a flag for log level
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error, fatal, panic)")
+	configPath := flag.String("config", os.Getenv("SPLAT_BOT_CONFIG"), "Path to the configuration file. Environment variables override its values.")
+	printConfig := flag.Bool("print-config", false, "Print the configuration, with secrets redacted, and exit")
 	flag.Parse()
 
 	// Parse and set the log level
@@ -52,26 +77,111 @@ func main() {
 	log.SetFormatter(&CustomFormatter{})
 	log.SetOutput(os.Stdout)
 
+	// linting knowledge assets needs no configuration
+	if flag.Arg(0) == "knowledge" {
+		if err := runKnowledge(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	// the REPL doesn't connect to Slack so the configuration isn't validated
+	if flag.Arg(0) == "repl" {
+		if err := runREPL(ctx, cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	slackutil.SetSlackConfig(cfg.Slack)
+	slackutil.SetLLMConfig(cfg.LLM)
+	slackutil.SetJiraConfig(cfg.Jira)
+
 	client, err := slackutil.GetClient()
 	if err != nil {
 		log.Debugf("unable to get slack client: %v", err)
 		os.Exit(1)
 	}
 
-	err = commands.Initialize()
+	// calls made on behalf of commands are retried when Slack rate limits them
+	api := slackutil.NewRetryingClient(ctx, client)
+
+	err = commands.Initialize(ctx, api, cfg)
 	if err != nil {
-		log.Debugf("unable to get users in group")
-		os.Exit(1)
+		log.Fatalf("unable to initialize commands: %v", err)
+	}
+
+	err = audit.Initialize(cfg.Audit)
+	if err != nil {
+		log.Fatalf("unable to initialize audit log: %v", err)
 	}
+	audit.Start()
+
+	pluginManager, err := plugins.NewManager(availablePlugins(api), cfg.Plugins)
+	if err != nil {
+		log.Fatalf("unable to enable plugins: %v", err)
+	}
+	err = pluginManager.Start(ctx, cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	commands.StartWorkers(ctx, cfg.Commands)
+
+	// the bot is ready once Socket Mode is connected
+	var connected, everConnected atomic.Bool
+	metrics.AddReadinessCheck("socket-mode", func() error {
+		if !connected.Load() {
+			return errors.New("not connected to Slack")
+		}
+		return nil
+	})
+	go func() {
+		if err := metrics.Serve(ctx, cfg.Metrics.BindAddress); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}()
 
 	go func() {
 		for evt := range client.Events {
+			// Socket Mode redelivers envelopes which aren't acknowledged in time
+			if evt.Request != nil && commands.IsDuplicateDelivery(evt.Request.EnvelopeID) {
+				log.Debugf("discarding redelivered envelope %s", evt.Request.EnvelopeID)
+				client.Ack(*evt.Request)
+				continue
+			}
 			switch evt.Type {
 			case socketmode.EventTypeConnecting:
+				connected.Store(false)
 				log.Infof("Connecting to Slack with Socket Mode...")
 			case socketmode.EventTypeConnectionError:
+				connected.Store(false)
 				log.Infof("Connection failed. Retrying later...")
+			case socketmode.EventTypeDisconnect:
+				connected.Store(false)
+				log.Infof("Disconnected from Slack.")
 			case socketmode.EventTypeConnected:
+				connected.Store(true)
+				if everConnected.Swap(true) {
+					metrics.SocketModeReconnected()
+				}
 				log.Infof("Connected to Slack with Socket Mode.")
 			case socketmode.EventTypeEventsAPI:
 				eventsAPIEvent, ok := evt.Data.(events.EventsAPIEvent)
@@ -83,40 +193,79 @@ func main() {
 				log.Debugf("event received: %+v\n", eventsAPIEvent)
 
 				client.Ack(*evt.Request)
-				err = commands.Handler(ctx, client, eventsAPIEvent)
+				err = commands.Handler(ctx, api, eventsAPIEvent)
 				if err != nil {
 					log.Warnf("error encountered while processing event: %v", err)
 				}
 			case socketmode.EventTypeInteractive:
-				log.Debugf("GOT INTERACTIVE EVENT: %v\n", evt)
-				client.Ack(*evt.Request)
-
-				data := evt.Data.(slack.InteractionCallback)
+				interaction, ok := evt.Data.(slack.InteractionCallback)
+				if !ok {
+					log.Warnf("ignored %+v\n", evt)
+					continue
+				}
 
 				// This outputs the event data for debugging
 				buffer := bytes.NewBuffer([]byte{})
-				if err := json.NewEncoder(buffer).Encode(data); err != nil {
+				if err := json.NewEncoder(buffer).Encode(interaction); err != nil {
 					log.Warnf("Error: %v", err)
 				} else {
 					log.Debugln(buffer.String())
 				}
 
-				// For now, only close if text of action
-				if data.ActionCallback.BlockActions[0].Text.Text == "Close" {
-					_, _, err = client.PostMessage(data.Channel.ID, slack.MsgOptionDeleteOriginal(data.ResponseURL))
+				// view submissions are acknowledged with the response of the handler so that
+				// validation errors can be shown in the modal.
+				if interaction.Type == slack.InteractionTypeViewSubmission {
+					response, err := commands.ViewSubmissionHandler(ctx, api, &interaction)
 					if err != nil {
-						log.Warnf("Error occurred handling interative event: %v", err)
+						log.Warnf("error encountered while processing view submission: %v", err)
 					}
+					if response != nil {
+						client.Ack(*evt.Request, response)
+					} else {
+						client.Ack(*evt.Request)
+					}
+					continue
+				}
+
+				client.Ack(*evt.Request)
+				err = commands.InteractionHandler(ctx, api, &interaction)
+				if err != nil {
+					log.Warnf("Error occurred handling interative event: %v", err)
 				}
 			case socketmode.EventTypeSlashCommand:
+				cmd, ok := evt.Data.(slack.SlashCommand)
+				if !ok {
+					log.Warnf("ignored %+v\n", evt)
+					continue
+				}
+
+				log.Debugf("slash command received: %+v\n", cmd)
+
+				client.Ack(*evt.Request)
+				err = commands.SlashCommandHandler(ctx, api, cmd)
+				if err != nil {
+					log.Warnf("error encountered while processing slash command: %v", err)
+				}
 			default:
 				log.Warnf("Unexpected event type received: %s\n", evt.Type)
 			}
 		}
 	}()
 
-	err = client.Run()
-	if err != nil {
+	err = client.RunContext(ctx)
+	if err != nil && ctx.Err() == nil {
 		log.Fatalf("error encountered while running client: %v", err)
 	}
+
+	// running commands are cancelled when the context is done. wait for them to notify their users.
+	commands.WaitForWorkers()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := audit.Stop(stopCtx); err != nil {
+		log.Warnf("%v", err)
+	}
+	if err := pluginManager.Stop(stopCtx); err != nil {
+		log.Warnf("%v", err)
+	}
 }
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"context"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
+)
+
+// ActionCallback is invoked when a user interacts with a Block Kit element such as a button or menu. The
+// interaction carries the original message, the user and the channel.
+type ActionCallback func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error)
+
+// ViewSubmissionCallback is invoked when a user submits a modal. The returned response, if any, is sent
+// to Slack when the submission is acknowledged. For example, to report validation errors.
+type ViewSubmissionCallback func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback) (*slack.ViewSubmissionResponse, error)
+
+// ActionAttributes define how to handle a Block Kit interaction
+type ActionAttributes struct {
+	// ActionID the action_id of the element which invokes Callback. Elements may append context to the
+	// action_id after a colon. e.g. `pool-cordon:pool-1` invokes the `pool-cordon` action.
+	ActionID string
+	// CallbackID the callback_id of the view which invokes ViewSubmission.
+	CallbackID string
+	// Callback function called when an element with ActionID is used.
+	Callback ActionCallback
+	// ViewSubmission function called when a view with CallbackID is submitted.
+	ViewSubmission ViewSubmissionCallback
+	// Commands the command path used to authorize the interaction against the command policy. e.g. an action
+	// which cordons a pool should be authorized as `ci pools cordon`.
+	Commands []string
+	// AllowNonSplatUsers by default, only members of @splat-team can interact with the bot
+	AllowNonSplatUsers bool
+	// ReplaceOriginal when true, the response replaces the message containing the element. Otherwise, the
+	// response is posted as an ephemeral message to the user.
+	ReplaceOriginal bool
+}
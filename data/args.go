@@ -0,0 +1,99 @@
+package data
+
+import (
+	"context"
+	"strconv"
+)
+
+// ArgType the type of the value of an argument.
+type ArgType string
+
+const (
+	ArgTypeString ArgType = "string"
+	ArgTypeInt    ArgType = "int"
+	ArgTypeBool   ArgType = "bool"
+)
+
+// Argument describes a positional argument or a key=value flag of a command.
+type Argument struct {
+	// Name of the argument. For flags, this is the key.
+	Name string
+	// Type of the value. Defaults to ArgTypeString.
+	Type ArgType
+	// Default value applied when the argument is not provided.
+	Default string
+	// Enum when set, the value must be one of these values.
+	Enum []string
+	// Required when true, the argument must be provided.
+	Required bool
+	// Help describes the argument in help and error messages.
+	Help string
+}
+
+type parsedArgsKey struct{}
+
+// ParsedArgs the validated values of the arguments and flags of a command.
+type ParsedArgs struct {
+	values map[string]any
+	set    map[string]bool
+}
+
+// NewParsedArgs returns an empty set of parsed arguments.
+func NewParsedArgs() *ParsedArgs {
+	return &ParsedArgs{
+		values: map[string]any{},
+		set:    map[string]bool{},
+	}
+}
+
+// Set sets the value of an argument. explicit is true when the user provided the value.
+func (p *ParsedArgs) Set(name string, value any, explicit bool) {
+	p.values[name] = value
+	if explicit {
+		p.set[name] = true
+	}
+}
+
+// IsSet returns true if the user provided the argument.
+func (p *ParsedArgs) IsSet(name string) bool {
+	return p.set[name]
+}
+
+// String returns the value of a string argument.
+func (p *ParsedArgs) String(name string) string {
+	switch value := p.values[name].(type) {
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+// Int returns the value of an int argument.
+func (p *ParsedArgs) Int(name string) int {
+	value, _ := p.values[name].(int)
+	return value
+}
+
+// Bool returns the value of a bool argument.
+func (p *ParsedArgs) Bool(name string) bool {
+	value, _ := p.values[name].(bool)
+	return value
+}
+
+// GetParsedArgs returns the validated arguments of the command being invoked. Arguments are only parsed for
+// commands which declare Arguments or Flags.
+func GetParsedArgs(ctx context.Context) *ParsedArgs {
+	if parsed, ok := ctx.Value(parsedArgsKey{}).(*ParsedArgs); ok {
+		return parsed
+	}
+	return NewParsedArgs()
+}
+
+// WithParsedArgs returns a context which holds the validated arguments of a command.
+func WithParsedArgs(ctx context.Context, parsed *ParsedArgs) context.Context {
+	return context.WithValue(ctx, parsedArgsKey{}, parsed)
+}
@@ -0,0 +1,38 @@
+package data
+
+import "time"
+
+const (
+	// AuditOutcomeSuccess the command ran without error.
+	AuditOutcomeSuccess = "success"
+	// AuditOutcomeError the command returned an error.
+	AuditOutcomeError = "error"
+	// AuditOutcomeInvalid the command was invoked with invalid arguments.
+	AuditOutcomeInvalid = "invalid"
+	// AuditOutcomeDenied the user isn't allowed to run the command.
+	AuditOutcomeDenied = "denied"
+	// AuditOutcomeRateLimited the user or channel exceeded the command's rate limit.
+	AuditOutcomeRateLimited = "rate_limited"
+)
+
+// AuditRecord records an invocation of a command.
+type AuditRecord struct {
+	// Time the command was invoked.
+	Time time.Time `json:"time"`
+	// User the Slack user ID of the user who invoked the command.
+	User string `json:"user"`
+	// Channel the Slack channel ID the command was invoked in.
+	Channel string `json:"channel"`
+	// Thread the timestamp of the thread the command was invoked in, if any.
+	Thread string `json:"thread,omitempty"`
+	// Command the command path. e.g. `ci lease`
+	Command string `json:"command"`
+	// Args the arguments of the command including the command path.
+	Args []string `json:"args"`
+	// Outcome one of the AuditOutcome values.
+	Outcome string `json:"outcome"`
+	// Duration the time the command took to run.
+	Duration time.Duration `json:"duration"`
+	// Error the error returned by the command, if any.
+	Error string `json:"error,omitempty"`
+}
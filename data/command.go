@@ -2,7 +2,9 @@ package data
 
 import (
 	"context"
+	"time"
 
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -18,18 +20,28 @@ type Attributes struct {
 	Commands []string
 	// MessageOfInterest supercedes Commands. If MessageOfInterest is set, Commands is ignored.  This is useful for more complex matching.
 	MessageOfInterest MessageOfInterest
-	// The number of arguments a command must have. var args are not supported.
+	// The number of arguments a command must have. var args are not supported. Ignored if Arguments or Flags are set.
 	RequiredArgs int
-	// MaxArgs The maximum number of allowed arguments
+	// MaxArgs The maximum number of allowed arguments. Ignored if Arguments or Flags are set.
 	MaxArgs int
+	// Arguments the positional arguments which follow Commands. When Arguments or Flags are set, the arguments
+	// are validated before Callback is invoked and help is generated from them.
+	Arguments []Argument
+	// Flags the key=value flags which may follow Commands.
+	Flags []Argument
+	// Description describes the command in help. Used with Arguments and Flags in place of HelpMarkdown.
+	Description string
 	// Callback function called when the attributes are met
 	Callback Callback
-	// Rank: Future - in a situation where multiple regexes match, this allows a priority to be assigned.
+	// Rank in a situation where multiple commands match, this allows a priority to be assigned. The highest
+	// rank wins. When ranks are equal, the command with the most specific (longest) Commands wins.
 	Rank int64
 	// RequireMention when true, @splat-bot must be used to invoke the command.
 	RequireMention bool
 	// HelpMarkdown is markdown that is contributed with the bot shows help.
 	HelpMarkdown string
+	// HelpArea groups the command in help. e.g. ci, jira or github. Defaults to the first of Commands.
+	HelpArea string
 	// RespondInDM responds in a DM to the user.
 	RespondInDM bool
 	// RequireInChannel the attribute will only be recognized in a given channel(s).
@@ -38,6 +50,9 @@ type Attributes struct {
 	MustBeInThread bool
 	// AllowNonSplatUsers by default, only members of @splat-team can interact with the bot
 	AllowNonSplatUsers bool
+	// AdminOnly when true, only SPLAT admins may run the command unless a rule in the command policy applies.
+	// Nobody may run it if no admins are configured.
+	AdminOnly bool
 	// This command will not be included in the help message.
 	ExcludeFromHelp bool
 	// DontGlobQuotes when true, quotes are not globbed.  This is useful for knowledge commands that need discrete tokens.
@@ -46,6 +61,26 @@ type Attributes struct {
 	RespondInChannel bool
 	// ResponseIsEphemeral specifies if the response should be ephemeral.
 	ResponseIsEphemeral bool
+	// TrackEdits when true, the response is updated when the message which triggered it is edited and deleted
+	// when that message is deleted. Only use for commands which are safe to run again, such as knowledge.
+	// Ephemeral responses can't be tracked.
+	TrackEdits bool
+	// Mutating when true, the command changes state, such as acquiring a lease, and refuses to run twice for the
+	// same message. Slack may deliver a message more than once.
+	Mutating bool
+	// UserRateLimit limits how often a user may run the command. Defaults to RATE_LIMIT_PER_USER.
+	UserRateLimit config.RateLimit
+	// ChannelRateLimit limits how often the command may run in a channel. Defaults to RATE_LIMIT_PER_CHANNEL.
+	ChannelRateLimit config.RateLimit
+	// Async when true, Callback runs in a worker pool rather than on the event loop. The message is acknowledged
+	// immediately and the acknowledgement is updated with the response. Use for slow commands.
+	Async bool
+	// AsyncWhen when set, Callback runs in the worker pool, as with Async, for the messages AsyncWhen returns true
+	// for. Use for commands, such as knowledge, which are only slow for some messages.
+	AsyncWhen func(evt *slackevents.MessageEvent, args []string) bool
+	// Timeout the time an async Callback may run. Defaults to 5 minutes. The context passed to Callback is
+	// cancelled when the timeout elapses or the bot shuts down.
+	Timeout time.Duration
 	// ShouldMatch is a list of strings that should match
 	ShouldMatch []string `yaml:"should_match"`
 	// ShouldntMatch is a list of strings that shouldnt match
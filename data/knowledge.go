@@ -1,6 +1,19 @@
 package data
 
-import "github.com/expr-lang/expr/vm"
+import (
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+)
+
+const (
+	// KnowledgeFeedbackHelpful the answer helped.
+	KnowledgeFeedbackHelpful = "helpful"
+	// KnowledgeFeedbackNotHelpful the answer was on topic but didn't help.
+	KnowledgeFeedbackNotHelpful = "not_helpful"
+	// KnowledgeFeedbackWrongTopic the asset shouldn't have answered the message.
+	KnowledgeFeedbackWrongTopic = "wrong_topic"
+)
 
 // Knowledge defines a peice of knowledge that the bot can respond with
 type Knowledge struct {
@@ -28,7 +41,8 @@ type KnowledgeAsset struct {
 	// attached to a reasonable default message.
 	URLS []string `yaml:"urls"`
 
-	// when true, the message is sent to an LLM to construct an answer.
+	// when true, the message is sent to an LLM to construct an answer from MarkdownPrompt and the content
+	// of URLS. The answer cites URLS. MarkdownPrompt is used when the LLM fails.
 	InvokeLLM bool `yaml:"invoke_llm"`
 
 	// When the prompt is matched
@@ -55,6 +69,16 @@ type KnowledgeAsset struct {
 
 	// RequireInChannel the attribute will only be recognized in a given channel(s).
 	RequireInChannel []string `yaml:"must_be_in_channels"`
+
+	// Priority assets with a higher priority are preferred when several assets match a message. Assets
+	// with the same priority are ranked by how specifically they matched.
+	Priority int `yaml:"priority"`
+
+	// Exclusive when true and the asset matches, it's the only answer and other matching assets are left out.
+	Exclusive bool `yaml:"exclusive"`
+
+	// Path the file the asset was loaded from. Platform context terms are added to On based on the path.
+	Path string `yaml:"-"`
 }
 
 type ChannelContext struct {
@@ -73,3 +97,19 @@ type TokenMatch struct {
 	Expr         string `yaml:"expr"`
 	Satisfied    bool
 }
+
+// KnowledgeFeedback records a user's rating of a knowledge answer.
+type KnowledgeFeedback struct {
+	// Time the answer was rated.
+	Time time.Time `json:"time"`
+	// Asset the name of the asset which answered.
+	Asset string `json:"asset"`
+	// Rating one of the KnowledgeFeedback values.
+	Rating string `json:"rating"`
+	// User the Slack user ID of the user who rated the answer.
+	User string `json:"user"`
+	// Channel the Slack channel ID the answer was posted in.
+	Channel string `json:"channel"`
+	// Message the text of the message which was answered.
+	Message string `json:"message"`
+}
@@ -0,0 +1,24 @@
+package data
+
+// CommandPolicy defines which Slack principals may invoke which commands.
+type CommandPolicy struct {
+	// Rules the rules which make up the policy. When multiple rules apply to a command, the rule with the
+	// most specific (longest) command path is used.
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule grants access to a command path. A user is allowed when they are listed in Users, are a member of
+// one of Groups or when the command is invoked from one of Channels.
+type PolicyRule struct {
+	// Command the space separated command path the rule applies to. e.g. `ci pools cordon`
+	Command string `yaml:"command"`
+
+	// Users Slack user IDs which are allowed to invoke the command.
+	Users []string `yaml:"users"`
+
+	// Groups Slack user group IDs or handles, such as splat-team, whose members are allowed to invoke the command.
+	Groups []string `yaml:"groups"`
+
+	// Channels Slack channel IDs in which anyone may invoke the command.
+	Channels []string `yaml:"channels"`
+}
@@ -2,3 +2,4 @@
 echo "Running Unit Tests Against SPLAT Bot Doc"
 go clean -testcache
 PROMPT_PATH=$(pwd)/../splat-bot-doc/knowledge_prompts go test ./... -v
+go run ./cmd/slack-bot knowledge lint $(pwd)/../splat-bot-doc/knowledge_prompts
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+// Sink stores audit records.
+type Sink interface {
+	// Record stores a record.
+	Record(ctx context.Context, record data.AuditRecord) error
+	// Query returns the stored records which match the filter.
+	Query(ctx context.Context, filter Filter) ([]data.AuditRecord, error)
+}
+
+// Filter selects audit records.
+type Filter struct {
+	// User when set, only records for this Slack user ID are returned.
+	User string
+	// Command when set, only records whose command path begins with Command are returned.
+	Command string
+	// Since when set, only records at or after Since are returned.
+	Since time.Time
+	// Limit the maximum number of records to return. The most recent records are returned.
+	Limit int
+}
+
+// Matches returns true if the record is selected by the filter.
+func (f Filter) Matches(record data.AuditRecord) bool {
+	if len(f.User) > 0 && record.User != f.User {
+		return false
+	}
+	if len(f.Command) > 0 && record.Command != f.Command && !strings.HasPrefix(record.Command, f.Command+" ") {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// apply returns the records selected by the filter, most recent first, up to the filter's limit.
+func (f Filter) apply(records []data.AuditRecord) []data.AuditRecord {
+	var selected []data.AuditRecord
+	for _, record := range records {
+		if f.Matches(record) {
+			selected = append(selected, record)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Time.After(selected[j].Time)
+	})
+	if f.Limit > 0 && len(selected) > f.Limit {
+		selected = selected[:f.Limit]
+	}
+	return selected
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// AddSink adds a sink which receives every record. The first sink added is used for queries.
+func AddSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, sink)
+}
+
+// Initialize configures the sinks. Records are appended to the JSONL file at LogPath and stored in ConfigMap,
+// in the form <namespace>/<name>, when they're set.
+func Initialize(cfg config.AuditConfig) error {
+	if len(cfg.LogPath) > 0 {
+		AddSink(NewFileSink(cfg.LogPath))
+		log.Infof("recording audit log to %s", cfg.LogPath)
+	}
+	if len(cfg.ConfigMap) > 0 {
+		namespace, name, found := strings.Cut(cfg.ConfigMap, "/")
+		if !found {
+			return fmt.Errorf("AUDIT_CONFIGMAP must be in the form <namespace>/<name>")
+		}
+		sink, err := NewConfigMapSink(namespace, name, cfg.ConfigMapMaxRecords)
+		if err != nil {
+			return fmt.Errorf("unable to create ConfigMap audit sink: %v", err)
+		}
+		AddSink(sink)
+		log.Infof("recording audit log to ConfigMap %s", cfg.ConfigMap)
+	}
+	return nil
+}
+
+// recordBuffer the number of records which may wait to be written. Records are dropped while the buffer is full.
+const recordBuffer = 1000
+
+var (
+	writerMu sync.RWMutex
+	writer   *recordWriter
+)
+
+// recordWriter writes records to the sinks in the background, so slow sinks such as a ConfigMap don't hold up the
+// commands being recorded.
+type recordWriter struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	records chan data.AuditRecord
+	done    chan struct{}
+}
+
+// Start writes records to the sinks in the background. Until it's started, records are written as they're recorded.
+func Start() {
+	writerMu.Lock()
+	defer writerMu.Unlock()
+	if writer != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	writer = &recordWriter{
+		ctx:     ctx,
+		cancel:  cancel,
+		records: make(chan data.AuditRecord, recordBuffer),
+		done:    make(chan struct{}),
+	}
+	go func(w *recordWriter) {
+		defer close(w.done)
+		for record := range w.records {
+			write(w.ctx, record)
+		}
+	}(writer)
+}
+
+// Stop writes the records waiting in the buffer and stops writing in the background. Records which aren't written
+// by the time ctx is done are lost.
+func Stop(ctx context.Context) error {
+	writerMu.Lock()
+	w := writer
+	writer = nil
+	if w != nil {
+		close(w.records)
+	}
+	writerMu.Unlock()
+	if w == nil {
+		return nil
+	}
+
+	defer w.cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d audit records were not written: %v", len(w.records), ctx.Err())
+	}
+}
+
+// write sends the record to every sink. Failures are logged since auditing must not prevent commands from
+// running.
+func write(ctx context.Context, record data.AuditRecord) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, sink := range sinks {
+		if err := sink.Record(ctx, record); err != nil {
+			log.Warnf("failed recording audit record for %s: %v", record.Command, err)
+		}
+	}
+}
+
+// Record sends the record to every sink. Once the writer is started, the record is written in the background.
+func Record(ctx context.Context, record data.AuditRecord) {
+	writerMu.RLock()
+	defer writerMu.RUnlock()
+	if writer == nil {
+		write(ctx, record)
+		return
+	}
+	select {
+	case writer.records <- record:
+	default:
+		log.Warnf("dropping audit record for %s, %d records are waiting to be written", record.Command, recordBuffer)
+	}
+}
+
+// Query returns the records which match the filter from the first sink.
+func Query(ctx context.Context, filter Filter) ([]data.AuditRecord, error) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	if len(sinks) == 0 {
+		return nil, errors.New("the audit log is not configured")
+	}
+	return sinks[0].Query(ctx, filter)
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+func testRecords() []data.AuditRecord {
+	now := time.Now()
+	return []data.AuditRecord{
+		{Time: now.Add(-48 * time.Hour), User: "U1", Command: "ci lease", Args: []string{"ci", "lease", "acquire"}, Outcome: data.AuditOutcomeSuccess},
+		{Time: now.Add(-time.Hour), User: "U1", Command: "ci pools", Args: []string{"ci", "pools", "cordon", "pool-1"}, Outcome: data.AuditOutcomeDenied},
+		{Time: now, User: "U2", Command: "jira create", Args: []string{"jira", "create", "summary"}, Outcome: data.AuditOutcomeError, Error: "failed"},
+	}
+}
+
+func testSink(t *testing.T, sink Sink) {
+	ctx := context.TODO()
+	for _, record := range testRecords() {
+		if err := sink.Record(ctx, record); err != nil {
+			t.Fatalf("unable to record: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{name: "most recent first", filter: Filter{}, expected: []string{"jira create", "ci pools", "ci lease"}},
+		{name: "user", filter: Filter{User: "U1"}, expected: []string{"ci pools", "ci lease"}},
+		{name: "command prefix", filter: Filter{Command: "ci"}, expected: []string{"ci pools", "ci lease"}},
+		{name: "since", filter: Filter{Since: time.Now().Add(-24 * time.Hour)}, expected: []string{"jira create", "ci pools"}},
+		{name: "limit", filter: Filter{Limit: 1}, expected: []string{"jira create"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := sink.Query(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("unable to query: %v", err)
+			}
+			if len(records) != len(tc.expected) {
+				t.Fatalf("expected %d records, got %d", len(tc.expected), len(records))
+			}
+			for idx, record := range records {
+				if record.Command != tc.expected[idx] {
+					t.Errorf("expected %s at %d, got %s", tc.expected[idx], idx, record.Command)
+				}
+			}
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	testSink(t, NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl")))
+}
+
+func TestConfigMapSink(t *testing.T) {
+	testSink(t, newConfigMapSink(fake.NewClientBuilder().Build(), "splat", "audit", config.DEFAULT_AUDIT_CONFIGMAP_RECORDS))
+
+	// older records are dropped once the ConfigMap is full
+	sink := newConfigMapSink(fake.NewClientBuilder().Build(), "splat", "audit", 2)
+	for _, record := range testRecords() {
+		if err := sink.Record(context.TODO(), record); err != nil {
+			t.Fatalf("unable to record: %v", err)
+		}
+	}
+	records, err := sink.Query(context.TODO(), Filter{})
+	if err != nil {
+		t.Fatalf("unable to query: %v", err)
+	}
+	if len(records) != 2 || records[1].Command != "ci pools" {
+		t.Errorf("expected the oldest record to be dropped: %+v", records)
+	}
+}
+
+// blockingSink holds each record until it's released.
+type blockingSink struct {
+	*FileSink
+	release chan struct{}
+}
+
+func (s *blockingSink) Record(ctx context.Context, record data.AuditRecord) error {
+	<-s.release
+	return s.FileSink.Record(ctx, record)
+}
+
+func TestBackgroundWriter(t *testing.T) {
+	sink := &blockingSink{FileSink: NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl")), release: make(chan struct{})}
+	sinksMu.Lock()
+	previous := sinks
+	sinks = []Sink{sink}
+	sinksMu.Unlock()
+	t.Cleanup(func() {
+		sinksMu.Lock()
+		defer sinksMu.Unlock()
+		sinks = previous
+	})
+
+	Start()
+	// recording doesn't wait for the sink
+	for _, record := range testRecords() {
+		Record(context.TODO(), record)
+	}
+	close(sink.release)
+
+	if err := Stop(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := Query(context.TODO(), Filter{})
+	if err != nil {
+		t.Fatalf("unable to query: %v", err)
+	}
+	if len(records) != len(testRecords()) {
+		t.Errorf("expected the buffered records to be written when the writer stops, got %d", len(records))
+	}
+}
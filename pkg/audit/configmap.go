@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/configmap"
+)
+
+const (
+	configMapKey = "audit.jsonl"
+)
+
+// ConfigMapSink stores the most recent records in a ConfigMap as JSONL.
+type ConfigMapSink struct {
+	records *configmap.JSONL[data.AuditRecord]
+}
+
+// NewConfigMapSink returns a sink which stores records in the ConfigMap namespace/name. The ConfigMap is
+// created if it doesn't exist.
+func NewConfigMapSink(namespace, name string, maxRecords int) (*ConfigMapSink, error) {
+	store, err := configmap.NewStore(namespace, name, configMapKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigMapSink{records: configmap.NewJSONL[data.AuditRecord](store, maxRecords)}, nil
+}
+
+func newConfigMapSink(k8sclient client.Client, namespace, name string, maxRecords int) *ConfigMapSink {
+	store := configmap.NewStoreWithClient(k8sclient, namespace, name, configMapKey)
+	return &ConfigMapSink{records: configmap.NewJSONL[data.AuditRecord](store, maxRecords)}
+}
+
+func (s *ConfigMapSink) Record(ctx context.Context, record data.AuditRecord) error {
+	return s.records.Append(ctx, record)
+}
+
+func (s *ConfigMapSink) Query(ctx context.Context, filter Filter) ([]data.AuditRecord, error) {
+	records, err := s.records.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filter.apply(records), nil
+}
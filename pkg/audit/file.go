@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+// FileSink appends records to a JSONL file, one record per line.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink returns a sink which appends to the file at path. The file is created if it doesn't exist.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Record(ctx context.Context, record data.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("unable to marshal record: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %v", s.path, err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("unable to write to %s: %v", s.path, err)
+	}
+	return nil
+}
+
+func (s *FileSink) Query(ctx context.Context, filter Filter) ([]data.AuditRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %v", s.path, err)
+	}
+	defer file.Close()
+
+	var records []data.AuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record data.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			log.Warnf("skipping malformed audit record in %s: %v", s.path, err)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", s.path, err)
+	}
+	return filter.apply(records), nil
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// CloseActionID the action_id of a button which deletes the message containing it.
+	CloseActionID = "close"
+)
+
+var (
+	actions         = map[string]data.ActionAttributes{}
+	viewSubmissions = map[string]data.ActionAttributes{}
+)
+
+// AddAction adds a handler for a Block Kit interaction. Handlers are keyed by ActionID for element
+// interactions and by CallbackID for view submissions.
+func AddAction(action data.ActionAttributes) {
+	attributeMu.Lock()
+	defer attributeMu.Unlock()
+	if len(action.ActionID) > 0 {
+		log.Printf("adding action: %s", action.ActionID)
+		actions[action.ActionID] = action
+	}
+	if len(action.CallbackID) > 0 {
+		log.Printf("adding view submission: %s", action.CallbackID)
+		viewSubmissions[action.CallbackID] = action
+	}
+}
+
+// getAction returns the handler for an action_id. Context appended to the action_id after a colon is ignored.
+func getAction(actionID string) (data.ActionAttributes, bool) {
+	attributeMu.Lock()
+	defer attributeMu.Unlock()
+	action, ok := actions[strings.SplitN(actionID, ":", 2)[0]]
+	return action, ok
+}
+
+func getViewSubmission(callbackID string) (data.ActionAttributes, bool) {
+	attributeMu.Lock()
+	defer attributeMu.Unlock()
+	action, ok := viewSubmissions[callbackID]
+	return action, ok
+}
+
+// isAllowedInteraction checks if the user may perform the interaction. Interactions are authorized the same
+// way as the command identified by the action's Commands.
+func isAllowedInteraction(action data.ActionAttributes, interaction *slack.InteractionCallback) error {
+	attribute := data.Attributes{
+		Commands:           action.Commands,
+		AllowNonSplatUsers: action.AllowNonSplatUsers,
+	}
+	return isAllowedUser(attribute, action.Commands, interactionMessage(interaction))
+}
+
+// interactionMessage describes the user and channel of an interaction as a message.
+func interactionMessage(interaction *slack.InteractionCallback) *slackevents.MessageEvent {
+	return &slackevents.MessageEvent{
+		User:     interaction.User.ID,
+		Username: interaction.User.Name,
+		Channel:  interaction.Channel.ID,
+	}
+}
+
+func respondToInteraction(client util.SlackClientInterface, interaction *slack.InteractionCallback, action data.ActionAttributes, response []slack.MsgOption) error {
+	if action.ReplaceOriginal {
+		response = append(response, slack.MsgOptionReplaceOriginal(interaction.ResponseURL))
+	} else {
+		response = append(response, slack.MsgOptionResponseURL(interaction.ResponseURL, slack.ResponseTypeEphemeral))
+	}
+	_, _, err := client.PostMessage(interaction.Channel.ID, response...)
+	if err != nil {
+		return fmt.Errorf("failed responding to interaction: %v", err)
+	}
+	return nil
+}
+
+// InteractionHandler dispatches Block Kit element interactions to the registered actions.
+func InteractionHandler(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback) error {
+	if interaction.Type != slack.InteractionTypeBlockActions {
+		log.Debugf("interaction type: %s discarded", interaction.Type)
+		return nil
+	}
+
+	for _, blockAction := range interaction.ActionCallback.BlockActions {
+		action, ok := getAction(blockAction.ActionID)
+		if !ok {
+			log.Warnf("no action registered for action_id: %s", blockAction.ActionID)
+			continue
+		}
+
+		msg := interactionMessage(interaction)
+		args := append(append([]string{}, action.Commands...), blockAction.Value)
+		attribute := data.Attributes{Commands: action.Commands}
+		if err := isAllowedInteraction(action, interaction); err != nil {
+			recordInvocation(ctx, attribute, msg, args, time.Now(), data.AuditOutcomeDenied, err)
+			postErr := respondToInteraction(client, interaction, data.ActionAttributes{}, util.StringToBlock(err.Error(), false))
+			if postErr != nil {
+				log.Warnf("failed notifying user of denied action: %v", postErr)
+			}
+			return fmt.Errorf("user %s with id %s is not allowed: %v", interaction.User.Name, interaction.User.ID, err)
+		}
+
+		start := time.Now()
+		response, err := action.Callback(ctx, client, interaction, blockAction)
+		outcome := data.AuditOutcomeSuccess
+		if err != nil {
+			outcome = data.AuditOutcomeError
+			log.Warnf("failed processing action %s: %v", blockAction.ActionID, err)
+		}
+		recordInvocation(ctx, attribute, msg, args, start, outcome, err)
+		if len(response) > 0 {
+			err = respondToInteraction(client, interaction, action, response)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ViewSubmissionHandler dispatches a view submission to the registered action. The returned response should
+// be sent to Slack when acknowledging the submission.
+func ViewSubmissionHandler(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback) (*slack.ViewSubmissionResponse, error) {
+	action, ok := getViewSubmission(interaction.View.CallbackID)
+	if !ok {
+		return nil, fmt.Errorf("no view submission registered for callback_id: %s", interaction.View.CallbackID)
+	}
+
+	if err := isAllowedInteraction(action, interaction); err != nil {
+		return nil, fmt.Errorf("user %s with id %s is not allowed: %v", interaction.User.Name, interaction.User.ID, err)
+	}
+	return action.ViewSubmission(ctx, client, interaction)
+}
+
+var CloseActionAttributes = data.ActionAttributes{
+	ActionID:           CloseActionID,
+	AllowNonSplatUsers: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		_, _, err := client.PostMessage(interaction.Channel.ID, slack.MsgOptionDeleteOriginal(interaction.ResponseURL))
+		if err != nil {
+			return nil, fmt.Errorf("unable to close message: %v", err)
+		}
+		return nil, nil
+	},
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestInteractionHandler(t *testing.T) {
+	var invokedWith string
+	AddAction(data.ActionAttributes{
+		ActionID:           "test-action",
+		AllowNonSplatUsers: true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+			invokedWith = action.Value
+			return nil, nil
+		},
+	})
+
+	interaction := &slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockActions,
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{
+				{ActionID: "unknown-action"},
+				{ActionID: "test-action:pool-1", Value: "pool-1"},
+			},
+		},
+	}
+	if err := InteractionHandler(context.TODO(), &util.StubInterface{}, interaction); err != nil {
+		t.Fatalf("expected no error: %v", err)
+	}
+	if invokedWith != "pool-1" {
+		t.Errorf("expected action to be invoked with pool-1, got %q", invokedWith)
+	}
+
+	// an interaction without any actions must not panic
+	if err := InteractionHandler(context.TODO(), &util.StubInterface{}, &slack.InteractionCallback{Type: slack.InteractionTypeBlockActions}); err != nil {
+		t.Errorf("expected no error: %v", err)
+	}
+}
+
+func TestViewSubmissionHandler(t *testing.T) {
+	interaction := &slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		View: slack.View{CallbackID: "unknown-view"},
+	}
+	if _, err := ViewSubmissionHandler(context.TODO(), &util.StubInterface{}, interaction); err == nil {
+		t.Errorf("expected an error for an unregistered view")
+	}
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+// hasArgumentSchema returns true if the command declares its arguments.
+func hasArgumentSchema(attribute data.Attributes) bool {
+	return len(attribute.Arguments) > 0 || len(attribute.Flags) > 0
+}
+
+// convertArg validates a value against the argument and converts it to the argument's type.
+func convertArg(arg data.Argument, value string) (any, error) {
+	if len(arg.Enum) > 0 && !contains(arg.Enum, value) {
+		return nil, fmt.Errorf("invalid value `%s` for `%s`: expected one of %s", value, arg.Name, strings.Join(arg.Enum, ", "))
+	}
+	switch arg.Type {
+	case data.ArgTypeInt:
+		converted, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value `%s` for `%s`: expected an integer", value, arg.Name)
+		}
+		return converted, nil
+	case data.ArgTypeBool:
+		converted, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value `%s` for `%s`: expected true or false", value, arg.Name)
+		}
+		return converted, nil
+	}
+	return value, nil
+}
+
+func findFlag(flags []data.Argument, name string) (data.Argument, bool) {
+	for _, flag := range flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return data.Argument{}, false
+}
+
+// parseArgs validates the tokens of a message against the arguments and flags declared by the command.
+func parseArgs(attribute data.Attributes, args []string) (*data.ParsedArgs, error) {
+	parsed := data.NewParsedArgs()
+
+	var tokens []string
+	if len(args) > len(attribute.Commands) {
+		tokens = args[len(attribute.Commands):]
+	}
+
+	var positional []string
+	for _, token := range tokens {
+		if len(token) == 0 {
+			continue
+		}
+		key, value, isFlag := strings.Cut(token, "=")
+		if !isFlag || len(attribute.Flags) == 0 {
+			positional = append(positional, token)
+			continue
+		}
+		flag, ok := findFlag(attribute.Flags, key)
+		if !ok {
+			var names []string
+			for _, flag := range attribute.Flags {
+				names = append(names, flag.Name)
+			}
+			return nil, fmt.Errorf("unknown flag `%s`. valid flags are: %s", key, strings.Join(names, ", "))
+		}
+		converted, err := convertArg(flag, strings.Trim(value, "\""))
+		if err != nil {
+			return nil, err
+		}
+		parsed.Set(flag.Name, converted, true)
+	}
+
+	if len(positional) > len(attribute.Arguments) {
+		return nil, fmt.Errorf("unexpected argument `%s`. if an argument is greater than one word, be sure to wrap that argument in quotes", positional[len(attribute.Arguments)])
+	}
+
+	for idx, arg := range attribute.Arguments {
+		if idx < len(positional) {
+			converted, err := convertArg(arg, positional[idx])
+			if err != nil {
+				return nil, err
+			}
+			parsed.Set(arg.Name, converted, true)
+			continue
+		}
+		if arg.Required {
+			return nil, fmt.Errorf("missing required argument `%s`", arg.Name)
+		}
+	}
+
+	// apply defaults to anything not provided
+	for _, arg := range append(append([]data.Argument{}, attribute.Arguments...), attribute.Flags...) {
+		if parsed.IsSet(arg.Name) {
+			continue
+		}
+		if len(arg.Default) == 0 {
+			parsed.Set(arg.Name, zeroValue(arg), false)
+			continue
+		}
+		converted, err := convertArg(arg, arg.Default)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default for `%s`: %v", arg.Name, err)
+		}
+		parsed.Set(arg.Name, converted, false)
+	}
+	return parsed, nil
+}
+
+func zeroValue(arg data.Argument) any {
+	switch arg.Type {
+	case data.ArgTypeInt:
+		return 0
+	case data.ArgTypeBool:
+		return false
+	}
+	return ""
+}
+
+func describeArgType(arg data.Argument) string {
+	if len(arg.Enum) > 0 {
+		return strings.Join(arg.Enum, "|")
+	}
+	if len(arg.Type) > 0 {
+		return string(arg.Type)
+	}
+	return string(data.ArgTypeString)
+}
+
+// usage returns the usage of a command generated from its arguments and flags. e.g.
+// `ci lease [action] [cpus=int]`
+func usage(attribute data.Attributes) string {
+	parts := append([]string{}, attribute.Commands...)
+	for _, arg := range attribute.Arguments {
+		if arg.Required {
+			parts = append(parts, fmt.Sprintf("<%s>", arg.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[%s]", arg.Name))
+		}
+	}
+	for _, flag := range attribute.Flags {
+		if flag.Required {
+			parts = append(parts, fmt.Sprintf("%s=<%s>", flag.Name, describeArgType(flag)))
+		} else {
+			parts = append(parts, fmt.Sprintf("[%s=<%s>]", flag.Name, describeArgType(flag)))
+		}
+	}
+	return fmt.Sprintf("`%s`", strings.Join(parts, " "))
+}
+
+// argumentHelp returns a line describing each argument and flag of a command.
+func argumentHelp(attribute data.Attributes) []string {
+	var lines []string
+	for _, arg := range append(append([]data.Argument{}, attribute.Arguments...), attribute.Flags...) {
+		line := fmt.Sprintf("`%s` (%s)", arg.Name, describeArgType(arg))
+		if len(arg.Help) > 0 {
+			line = fmt.Sprintf("%s: %s", line, arg.Help)
+		}
+		if len(arg.Default) > 0 {
+			line = fmt.Sprintf("%s. default: `%s`", line, arg.Default)
+		}
+		if arg.Required {
+			line = fmt.Sprintf("%s. required", line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// helpMarkdown returns the help for a command. Commands which declare their arguments have help generated
+// from the declaration.
+func helpMarkdown(attribute data.Attributes) string {
+	if !hasArgumentSchema(attribute) {
+		return attribute.HelpMarkdown
+	}
+	if len(attribute.Description) == 0 {
+		return usage(attribute)
+	}
+	return fmt.Sprintf("%s: %s", attribute.Description, usage(attribute))
+}
+
+// usageError returns a message describing an argument error along with the usage of the command.
+func usageError(attribute data.Attributes, err error) string {
+	message := fmt.Sprintf("%v\nusage: %s", err, usage(attribute))
+	if lines := argumentHelp(attribute); len(lines) > 0 {
+		message = fmt.Sprintf("%s\n- %s", message, strings.Join(lines, "\n- "))
+	}
+	return message
+}
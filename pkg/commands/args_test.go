@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{name: "defaults", args: []string{"ci", "lease"}},
+		{name: "flags", args: []string{"ci", "lease", "acquire", "cpus=4", "pools=\"pool-1\""}},
+		{name: "invalid enum", args: []string{"ci", "lease", "borrow"}, expectedErr: "expected one of list, acquire, renew, release"},
+		{name: "invalid int", args: []string{"ci", "lease", "acquire", "cpus=four"}, expectedErr: "expected an integer"},
+		{name: "unknown flag", args: []string{"ci", "lease", "acquire", "gpus=1"}, expectedErr: "unknown flag `gpus`"},
+		{name: "unexpected argument", args: []string{"ci", "lease", "acquire", "now"}, expectedErr: "unexpected argument `now`"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseArgs(LeasesAttributes, tc.args)
+			if len(tc.expectedErr) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+
+	parsed, err := parseArgs(LeasesAttributes, []string{"ci", "lease", "acquire", "cpus=4", "pools=\"pool-1\""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String("action") != "acquire" || parsed.Int("cpus") != 4 || parsed.Int("memory") != 96 || parsed.String("pools") != "pool-1" {
+		t.Errorf("unexpected values: action=%s cpus=%d memory=%d pools=%s",
+			parsed.String("action"), parsed.Int("cpus"), parsed.Int("memory"), parsed.String("pools"))
+	}
+	if !parsed.IsSet("cpus") || parsed.IsSet("memory") {
+		t.Errorf("expected only provided arguments to be set")
+	}
+
+	parsed, err = parseArgs(LeasesAttributes, tokenize("ci lease acquire cpus=4 pools=\"pool-1 pool-2\"", true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String("pools") != "pool-1 pool-2" {
+		t.Errorf("expected both pools, got %s", parsed.String("pools"))
+	}
+
+	_, err = parseArgs(ProwAttributes, []string{"prow", "results", "vsphere"})
+	if err == nil || !strings.Contains(err.Error(), "missing required argument `version`") {
+		t.Errorf("expected missing argument error, got %v", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	expected := "`ci lease [action] [cpus=<int>] [memory=<int>] [networks=<int>] [pools=<string>]`"
+	if actual := usage(LeasesAttributes); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	if help := helpMarkdown(ProwAttributes); !strings.HasPrefix(help, "retrieve prow results: `prow results <platform> <version> <state>`") {
+		t.Errorf("unexpected help: %s", help)
+	}
+}
@@ -6,25 +6,25 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 
 	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
+// docQueryURL the service questions are sent to.
+var docQueryURL = config.DEFAULT_DOC_QUERY_URL
+
 var AskDocsAttributes = data.Attributes{
 	Commands:            []string{"ask-docs"},
 	RequireMention:      true,
 	ResponseIsEphemeral: false,
 	AllowNonSplatUsers:  true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		url := os.Getenv("DOC_QUERY_URL")
-		if url == "" {
-			url = "http://localhost:8000/"
-		}
+		url := docQueryURL
 
 		log.Debugf("question: %v\n", args)
 		question := args[1]
@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// DEFAULT_ASYNC_TIMEOUT the time an async command may run when the command doesn't set a Timeout.
+	DEFAULT_ASYNC_TIMEOUT = 5 * time.Minute
+
+	asyncPlaceholder = ":hourglass_flowing_sand: working on it..."
+	asyncReaction    = "hourglass_flowing_sand"
+	asyncCancelled   = "the command was cancelled because the bot is shutting down. please try again later."
+)
+
+var workers *workerPool
+
+// workerPool runs async commands with a bounded number of workers.
+type workerPool struct {
+	ctx          context.Context
+	jobs         chan func()
+	mu           sync.Mutex
+	perUser      map[string]int
+	perUserLimit int
+	wg           sync.WaitGroup
+}
+
+// StartWorkers starts the workers which run async commands. Running commands are cancelled when ctx is done.
+// Until the workers are started, async commands run synchronously.
+func StartWorkers(ctx context.Context, cfg config.CommandsConfig) {
+	workers = newWorkerPool(ctx, cfg.Workers, cfg.WorkersPerUser)
+}
+
+// WaitForWorkers blocks until the workers have stopped. Workers stop once the context passed to StartWorkers
+// is done.
+func WaitForWorkers() {
+	if workers != nil {
+		workers.wg.Wait()
+	}
+}
+
+func newWorkerPool(ctx context.Context, count, perUserLimit int) *workerPool {
+	pool := &workerPool{
+		ctx:          ctx,
+		jobs:         make(chan func(), count*4),
+		perUser:      map[string]int{},
+		perUserLimit: perUserLimit,
+	}
+	for i := 0; i < count; i++ {
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					// queued jobs are run so their users are told the command was cancelled
+					for {
+						select {
+						case job := <-pool.jobs:
+							job()
+						default:
+							return
+						}
+					}
+				case job := <-pool.jobs:
+					job()
+				}
+			}
+		}()
+	}
+	return pool
+}
+
+// submit queues a job for the user. An error is returned if the user has too many jobs or the queue is full.
+func (p *workerPool) submit(user string, job func(ctx context.Context)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ctx.Err() != nil {
+		return fmt.Errorf(asyncCancelled)
+	}
+	if p.perUser[user] >= p.perUserLimit {
+		return fmt.Errorf("you already have %d commands running. please wait for them to finish.", p.perUser[user])
+	}
+
+	wrapped := func() {
+		defer func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.perUser[user]--
+			if p.perUser[user] <= 0 {
+				delete(p.perUser, user)
+			}
+		}()
+		job(p.ctx)
+	}
+	select {
+	case p.jobs <- wrapped:
+		p.perUser[user]++
+		return nil
+	default:
+		return fmt.Errorf("the bot is busy. please try again in a few minutes.")
+	}
+}
+
+// invokeWithTimeout invokes the command and waits until it completes, its timeout elapses or ctx is done. A command
+// which ignores its context may still be running when the response is returned. finished is closed once it
+// returns, so callers in the worker pool keep their worker, and the user's slot, until then.
+func invokeWithTimeout(ctx context.Context, client util.SlackClientInterface, attribute data.Attributes, msg *slackevents.MessageEvent, args []string) (response []slack.MsgOption, finished <-chan struct{}) {
+	timeout := attribute.Timeout
+	if timeout == 0 {
+		timeout = DEFAULT_ASYNC_TIMEOUT
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	exited := make(chan struct{})
+	if ctx.Err() != nil {
+		cancel()
+		close(exited)
+		return util.StringToBlock(asyncCancelled, false), exited
+	}
+	done := make(chan []slack.MsgOption, 1)
+	go func() {
+		defer close(exited)
+		defer cancel()
+		done <- invokeCommand(ctx, client, attribute, msg, args)
+	}()
+
+	select {
+	case response := <-done:
+		if len(response) == 0 {
+			return util.StringToBlock("no results.", false), exited
+		}
+		return response, exited
+	case <-ctx.Done():
+		go func() {
+			select {
+			case <-exited:
+			case <-time.After(timeout):
+				log.Warnf("%v is still running %s after it was cancelled", attribute.Commands, timeout)
+			}
+		}()
+		if ctx.Err() == context.DeadlineExceeded {
+			return util.StringToBlock(fmt.Sprintf("the command timed out after %s.", timeout), false), exited
+		}
+		return util.StringToBlock(asyncCancelled, false), exited
+	}
+}
+
+// isAsync returns true if the command runs in the worker pool for the message.
+func isAsync(attribute data.Attributes, msg *slackevents.MessageEvent, args []string) bool {
+	return attribute.Async || attribute.AsyncWhen != nil && attribute.AsyncWhen(msg, args)
+}
+
+// runAsync acknowledges a message and runs the command in the worker pool. Commands are acknowledged with a
+// placeholder message which is updated with the response. Ephemeral responses can't be updated so the message
+// is acknowledged with a reaction which is removed when the response is posted.
+func runAsync(ctx context.Context, client util.SlackClientInterface, attribute data.Attributes, msg *slackevents.MessageEvent, args []string) error {
+	channel, threadTS := replyTarget(client, attribute, msg)
+	item := slack.NewRefToMessage(msg.Channel, msg.TimeStamp)
+	placeholderTS := ""
+	if attribute.ResponseIsEphemeral {
+		if err := client.AddReaction(asyncReaction, item); err != nil {
+			log.Warnf("failed acknowledging message: %v", err)
+		}
+	} else {
+		var err error
+		channel, placeholderTS, err = client.PostMessage(channel, append(util.StringToBlock(asyncPlaceholder, false), inThread(threadTS)...)...)
+		if err != nil {
+			log.Warnf("failed posting placeholder: %v", err)
+		}
+		if len(threadTS) == 0 {
+			threadTS = placeholderTS
+		}
+		trackResponse(attribute, msg, indexedResponse{channel: channel, timestamp: placeholderTS, thread: threadTS})
+	}
+
+	deliver := func(response []slack.MsgOption) error {
+		if attribute.ResponseIsEphemeral {
+			if err := client.RemoveReaction(asyncReaction, item); err != nil {
+				log.Warnf("failed removing acknowledgement: %v", err)
+			}
+		}
+		if len(placeholderTS) == 0 {
+			return respondToMessage(client, attribute, msg, response)
+		}
+		placeholder := indexedResponse{channel: channel, timestamp: placeholderTS, thread: threadTS}
+		placeholder, err := replaceResponse(client, placeholder, response)
+		trackResponse(attribute, msg, placeholder)
+		return err
+	}
+
+	err := workers.submit(msg.User, func(workerCtx context.Context) {
+		response, finished := invokeWithTimeout(workerCtx, client, attribute, msg, args)
+		if err := deliver(response); err != nil {
+			log.Warnf("failed delivering response to %v: %v", attribute.Commands, err)
+		}
+		<-finished
+	})
+	if err != nil {
+		if deliverErr := deliver(util.StringToBlock(err.Error(), false)); deliverErr != nil {
+			log.Warnf("failed notifying user of rejected command: %v", deliverErr)
+		}
+		return fmt.Errorf("unable to run %v for user %s: %v", attribute.Commands, msg.User, err)
+	}
+	return nil
+}
+
+// replaceAsync runs the command for an edited message in the worker pool and replaces the reply to the message
+// with the response. The reply is kept, and the rate limit tokens returned, if the command can't be run.
+func replaceAsync(client util.SlackClientInterface, attribute data.Attributes, edited *slackevents.MessageEvent, args []string, previous indexedResponse, reservation *rateReservation) error {
+	err := workers.submit(edited.User, func(workerCtx context.Context) {
+		response, finished := invokeWithTimeout(workerCtx, client, attribute, edited, args)
+		defer func() { <-finished }()
+		replaced, err := replaceResponse(client, previous, response)
+		responses.put(edited.Channel, edited.TimeStamp, replaced)
+		if err != nil {
+			log.Warnf("failed replacing response to %v: %v", describeAttribute(attribute), err)
+		}
+	})
+	if err != nil {
+		reservation.cancel()
+		return fmt.Errorf("unable to run %s for user %s: %v", describeAttribute(attribute), edited.User, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// asyncClient records the messages posted and updated by async commands.
+type asyncClient struct {
+	util.StubInterface
+	updated chan string
+}
+
+func (c *asyncClient) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	return channelID, "1234.5678", nil
+}
+
+func (c *asyncClient) UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", "", "", err
+	}
+	c.updated <- values.Get("text")
+	return channelID, timestamp, "", nil
+}
+
+func TestRunAsync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	workers = newWorkerPool(ctx, 1, 1)
+	defer func() {
+		workers = nil
+	}()
+
+	release := make(chan struct{})
+	attribute := data.Attributes{
+		Commands: []string{"slow"},
+		Async:    true,
+		Timeout:  time.Second,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			select {
+			case <-release:
+				return util.StringToBlock("done", false), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+	client := &asyncClient{updated: make(chan string, 4)}
+	msg := &slackevents.MessageEvent{User: "U1", Channel: "C1", TimeStamp: "1"}
+
+	if err := runAsync(ctx, client, attribute, msg, []string{"slow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the user's second command exceeds the per-user limit
+	if err := runAsync(ctx, client, attribute, msg, []string{"slow"}); err == nil {
+		t.Errorf("expected the per-user limit to be enforced")
+	}
+	if rejected := <-client.updated; !strings.Contains(rejected, "you already have 1 commands running") {
+		t.Errorf("unexpected rejection: %s", rejected)
+	}
+
+	close(release)
+	if response := <-client.updated; !strings.Contains(response, "done") {
+		t.Errorf("expected the placeholder to be updated with the response: %s", response)
+	}
+
+	// the timeout elapses before the command completes
+	attribute.Callback = func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	attribute.Timeout = 10 * time.Millisecond
+	if err := runAsync(ctx, client, attribute, msg, []string{"slow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response := <-client.updated; !strings.Contains(response, "timed out") {
+		t.Errorf("expected the command to time out: %s", response)
+	}
+	waitForSlot(t, msg.User)
+
+	// a command which ignores its context keeps the user's slot until it returns
+	stuck := make(chan struct{})
+	attribute.Callback = func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		<-stuck
+		return nil, nil
+	}
+	if err := runAsync(ctx, client, attribute, msg, []string{"slow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response := <-client.updated; !strings.Contains(response, "timed out") {
+		t.Errorf("expected the command to time out: %s", response)
+	}
+	if err := runAsync(ctx, client, attribute, msg, []string{"slow"}); err == nil {
+		t.Errorf("expected the command which timed out to count against the per-user limit")
+	}
+	<-client.updated
+	close(stuck)
+	waitForSlot(t, msg.User)
+
+	// commands are cancelled when the bot shuts down
+	attribute.Callback = func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	attribute.Timeout = time.Minute
+	if err := runAsync(ctx, client, attribute, msg, []string{"slow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	if response := <-client.updated; !strings.Contains(response, "shutting down") {
+		t.Errorf("expected the command to be cancelled: %s", response)
+	}
+	WaitForWorkers()
+}
+
+// waitForSlot waits until the user has no commands running in the worker pool.
+func waitForSlot(t *testing.T, user string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		workers.mu.Lock()
+		running := workers.perUser[user]
+		workers.mu.Unlock()
+		if running == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the slot of %s to be released once the command returned", user)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
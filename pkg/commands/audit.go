@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/audit"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// auditQueryLimit the number of records shown by `splat audit`.
+	auditQueryLimit = 25
+)
+
+var userMentionRegex = regexp.MustCompile(`^<@([A-Z0-9]+)(\|[^>]*)?>$`)
+
+// recordInvocation records an invocation of a command in the metrics and the audit log. Catch-alls such as
+// knowledge see every message and aren't recorded in the audit log.
+func recordInvocation(ctx context.Context, attribute data.Attributes, msg *slackevents.MessageEvent, args []string, start time.Time, outcome string, err error) {
+	metrics.CommandInvoked(describeAttribute(attribute), outcome)
+	if outcome == data.AuditOutcomeSuccess || outcome == data.AuditOutcomeError {
+		metrics.ObserveCallback(describeAttribute(attribute), time.Since(start))
+	}
+	if len(attribute.Commands) == 0 {
+		return
+	}
+	record := data.AuditRecord{
+		Time:     start,
+		User:     msg.User,
+		Channel:  msg.Channel,
+		Thread:   msg.ThreadTimeStamp,
+		Command:  strings.Join(attribute.Commands, " "),
+		Args:     args,
+		Outcome:  outcome,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	audit.Record(ctx, record)
+}
+
+// parseSince parses the period covered by `splat audit`. In addition to Go durations, days such as `7d` are
+// accepted.
+func parseSince(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid period `%s`", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	since, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid period `%s`. use a duration such as `12h` or `7d`", value)
+	}
+	return since, nil
+}
+
+// getAuditFilter builds a filter from the arguments of `splat audit`. The first argument may be a user
+// mention, a command path or the period to cover.
+func getAuditFilter(parsed *data.ParsedArgs) (audit.Filter, error) {
+	filter := audit.Filter{Limit: auditQueryLimit}
+	subject := parsed.String("subject")
+	period := parsed.String("since")
+	if !parsed.IsSet("since") {
+		if _, err := parseSince(subject); err == nil {
+			period = subject
+			subject = ""
+		}
+	}
+
+	if match := userMentionRegex.FindStringSubmatch(subject); match != nil {
+		filter.User = match[1]
+	} else {
+		filter.Command = subject
+	}
+
+	since, err := parseSince(period)
+	if err != nil {
+		return filter, err
+	}
+	filter.Since = time.Now().Add(-since)
+	return filter, nil
+}
+
+func formatAuditRecord(record data.AuditRecord) string {
+	line := fmt.Sprintf("`%s` <@%s> `%s` %s (%s) in <#%s>", record.Time.UTC().Format("2006-01-02 15:04"), record.User,
+		strings.Join(record.Args, " "), record.Outcome, record.Duration.Round(time.Millisecond), record.Channel)
+	if len(record.Error) > 0 {
+		line = fmt.Sprintf("%s: %s", line, record.Error)
+	}
+	return line
+}
+
+var AuditAttributes = data.Attributes{
+	Commands:       []string{"splat", "audit"},
+	RequireMention: true,
+	AdminOnly:      true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		filter, err := getAuditFilter(data.GetParsedArgs(ctx))
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), err
+		}
+		records, err := audit.Query(ctx, filter)
+		if err != nil {
+			return util.StringToBlock(fmt.Sprintf("unable to query the audit log: %v", err), false), err
+		}
+		if len(records) == 0 {
+			return util.StringToBlock("no commands were run in that period.", false), nil
+		}
+
+		var lines []string
+		for _, record := range records {
+			lines = append(lines, formatAuditRecord(record))
+		}
+		return util.StringToBlock(strings.Join(lines, "\n"), false), nil
+	},
+	Description: "show recent command invocations",
+	Arguments: []data.Argument{
+		{Name: "subject", Help: "a user mention or a command path, such as `\"ci lease\"`"},
+		{Name: "since", Default: "24h", Help: "the period to cover, such as `12h` or `7d`"},
+	},
+	ResponseIsEphemeral: true,
+	RespondInChannel:    true,
+	ShouldMatch: []string{
+		"splat audit",
+		"splat audit <@U01234> 7d",
+		"splat audit \"ci lease\"",
+	},
+	ShouldntMatch: []string{
+		"jira create-with-summary PROJECT bug",
+		"splat",
+	},
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+func TestGetAuditFilter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		user    string
+		command string
+		since   time.Duration
+	}{
+		{name: "defaults", args: []string{"splat", "audit"}, since: 24 * time.Hour},
+		{name: "user and days", args: []string{"splat", "audit", "<@U01234|someone>", "7d"}, user: "U01234", since: 7 * 24 * time.Hour},
+		{name: "command", args: []string{"splat", "audit", "ci lease"}, command: "ci lease", since: 24 * time.Hour},
+		{name: "only a period", args: []string{"splat", "audit", "2h"}, since: 2 * time.Hour},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parseArgs(AuditAttributes, tc.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			filter, err := getAuditFilter(parsed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if filter.User != tc.user || filter.Command != tc.command {
+				t.Errorf("unexpected filter: %+v", filter)
+			}
+			if since := time.Since(filter.Since).Round(time.Minute); since != tc.since {
+				t.Errorf("expected the filter to cover %s, covers %s", tc.since, since)
+			}
+		})
+	}
+
+	parsed, _ := parseArgs(AuditAttributes, []string{"splat", "audit", "ci", "yesterday"})
+	if _, err := getAuditFilter(parsed); err == nil {
+		t.Errorf("expected an invalid period to be rejected")
+	}
+}
+
+func TestAuditRequiresAdmin(t *testing.T) {
+	defer func() {
+		adminUsers = map[string]bool{}
+		commandPolicy = nil
+	}()
+
+	args := []string{"splat", "audit"}
+	// nobody may query the audit log when no admins are configured
+	if err := isAllowedUser(AuditAttributes, args, &slackevents.MessageEvent{User: "U1"}); err == nil {
+		t.Errorf("expected the audit log to require an admin")
+	}
+
+	adminUsers = map[string]bool{"U1": true}
+	if err := isAllowedUser(AuditAttributes, args, &slackevents.MessageEvent{User: "U1"}); err != nil {
+		t.Errorf("expected an admin to be allowed: %v", err)
+	}
+	err := isAllowedUser(AuditAttributes, args, &slackevents.MessageEvent{User: "U2"})
+	if err == nil || !strings.Contains(err.Error(), "only SPLAT admins may run this command") {
+		t.Errorf("expected a user who isn't an admin to be denied: %v", err)
+	}
+
+	// a rule in the command policy replaces the default
+	commandPolicy = &data.CommandPolicy{Rules: []data.PolicyRule{{Command: "splat audit", Users: []string{"U2"}}}}
+	if err := isAllowedUser(AuditAttributes, args, &slackevents.MessageEvent{User: "U2"}); err != nil {
+		t.Errorf("expected the policy to allow the user: %v", err)
+	}
+}
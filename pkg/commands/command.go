@@ -3,10 +3,10 @@ package commands
 import (
 	"context"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
@@ -14,14 +14,20 @@ import (
 
 	"github.com/openshift-splat-team/splat-bot/data"
 	"github.com/openshift-splat-team/splat-bot/pkg/chat"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 )
 
 var (
-	attributeMu        sync.Mutex
-	attributes         = []data.Attributes{}
-	allowedUsers       = map[string]bool{}
-	enableChatResponse = false
+	attributeMu          sync.Mutex
+	attributes           = []data.Attributes{}
+	allowedUsers         = map[string]bool{}
+	allowedGroups        = []string{}
+	adminUsers           = map[string]bool{}
+	adminGroups          = []string{}
+	userGroupResolver    *util.UserGroupResolver
+	enableChatResponse   = false
+	debugCommandDispatch = false
 )
 
 // AddCommand adds a handler to the list of handlers. Matching of the message can be overriden
@@ -48,55 +54,127 @@ func getAttributes() []data.Attributes {
 	return newAttributes
 }
 
-func init() {
-	_enableChatResponse := os.Getenv("ENABLE_CHAT_RESPONSE")
-	if _enableChatResponse != "" {
-		enableChatResponse = strings.ToLower(_enableChatResponse) == "true"
+// Initialize configures the commands. The command policy is watched for changes until ctx is done.
+func Initialize(ctx context.Context, client util.SlackClientInterface, cfg *config.Config) error {
+	enableChatResponse = cfg.Commands.EnableChatResponse
+	debugCommandDispatch = cfg.Commands.DebugDispatch
+
+	if len(cfg.Slack.AllowedUsers) == 0 && len(cfg.Slack.AllowedGroups) == 0 {
+		log.Warnf("Disabling user enforcement.  Please configure SLACK_ALLOWED_USERS or SLACK_ALLOWED_GROUPS if you wish to enforce allowed users on certain commands.")
 	}
-	AddCommand(CreateAttributes)
-	AddCommand(HelpAttributes)
-	AddCommand(ProwAttributes)
-	AddCommand(ProwGraphAttributes)
-	AddCommand(CreateJiraWithThreadAttributes)
-}
+	for _, user := range cfg.Slack.AllowedUsers {
+		allowedUsers[user] = true
+		log.Infof("user id %s is allowed", user)
+	}
+	for _, group := range cfg.Slack.AllowedGroups {
+		allowedGroups = append(allowedGroups, group)
+		log.Infof("members of user group %s are allowed", group)
+	}
+	for _, user := range cfg.Slack.AdminUsers {
+		adminUsers[user] = true
+	}
+	adminGroups = append(adminGroups, cfg.Slack.AdminGroups...)
 
-func Initialize() error {
-	// TODO:  Global allowed users means we cannot make some actions available to some users while others not.  This could
-	//        be beefed up in the future to be allowed users per command from config provided by a yaml file or something of
-	//        that nature.
-	allowed := os.Getenv("SLACK_ALLOWED_USERS")
-	if len(allowed) == 0 {
-		log.Warnf("Disabling user enforcement.  Please configure SLACK_ALLOWED_USERS if you wish to enforce allowed users on certain commands.")
-	} else {
-		allowedUsersIDs := strings.Split(allowed, ",")
-		for _, user := range allowedUsersIDs {
-			allowedUsers[user] = true
-			log.Infof("user id %s is allowed", user)
+	// user groups are resolved with the Slack API and cached. the cache is updated when Slack reports
+	// that the members of a group changed.
+	userGroupResolver = util.NewUserGroupResolver(client, cfg.Slack.GroupCacheTTL)
+	SetGroupMembershipResolver(userGroupResolver)
+
+	// commands may be rate limited per user and per channel. commands may override the defaults.
+	defaultUserRateLimit = cfg.Commands.RateLimitPerUser
+	defaultChannelRateLimit = cfg.Commands.RateLimitPerChannel
+
+	// commands may be restricted to specific users, groups and channels with a policy file. commands without
+	// a rule in the policy fall back to SLACK_ALLOWED_USERS.
+	policyPath := cfg.Slack.CommandPolicyPath
+	if len(policyPath) > 0 {
+		err := loadPolicy(policyPath)
+		if err != nil {
+			return fmt.Errorf("unable to load command policy: %v", err)
+		}
+		err = watchPolicy(ctx, policyPath)
+		if err != nil {
+			return fmt.Errorf("unable to watch command policy: %v", err)
 		}
 	}
 	return nil
 }
 
-func isAllowedUser(evt *slackevents.MessageEvent) error {
-	log.Debugf("User size: %d\n", len(allowedUsers))
-	if _, found := allowedUsers[evt.User]; !found && len(allowedUsers) > 0 {
-		return fmt.Errorf("user %s with id %s is not allowed", evt.Username, evt.User)
+// isAllowedUser checks if the user may invoke the command. If a rule in the command policy applies to the
+// command, the rule is used. Otherwise, admin commands require the user to be in SLACK_ADMIN_USERS or a member of
+// a group in SLACK_ADMIN_GROUPS and, unless the command allows non-SPLAT users, other commands require the user
+// to be in SLACK_ALLOWED_USERS or a member of a group in SLACK_ALLOWED_GROUPS.
+func isAllowedUser(attribute data.Attributes, args []string, evt *slackevents.MessageEvent) error {
+	if rule := getPolicyRule(attribute, args); rule != nil {
+		return authorizeRule(rule, evt)
 	}
-	return nil
+	if attribute.AdminOnly {
+		if adminUsers[evt.User] || isGroupMember(adminGroups, evt.User) {
+			return nil
+		}
+		return &permissionError{
+			command:     strings.Join(attribute.Commands, " "),
+			requirement: adminRequirement(),
+		}
+	}
+	if attribute.AllowNonSplatUsers {
+		return nil
+	}
+	log.Debugf("User size: %d, Group size: %d\n", len(allowedUsers), len(allowedGroups))
+	if len(allowedUsers) == 0 && len(allowedGroups) == 0 {
+		return nil
+	}
+	if _, found := allowedUsers[evt.User]; found {
+		return nil
+	}
+	if isGroupMember(allowedGroups, evt.User) {
+		return nil
+	}
+
+	return &permissionError{
+		command:     strings.Join(attribute.Commands, " "),
+		requirement: defaultRequirement(),
+	}
+}
+
+// adminRequirement describes who may run admin commands which aren't covered by the command policy.
+func adminRequirement() string {
+	if len(adminGroups) > 0 {
+		var mentions []string
+		for _, group := range adminGroups {
+			mentions = append(mentions, util.GroupMention(group))
+		}
+		return fmt.Sprintf("only members of %s may run this command.", strings.Join(mentions, ", "))
+	}
+	return "only SPLAT admins may run this command."
 }
+
+// defaultRequirement describes who may run commands which aren't covered by the command policy.
+func defaultRequirement() string {
+	if len(allowedGroups) > 0 {
+		var mentions []string
+		for _, group := range allowedGroups {
+			mentions = append(mentions, util.GroupMention(group))
+		}
+		return fmt.Sprintf("only members of %s may run this command.", strings.Join(mentions, ", "))
+	}
+	return "only SPLAT members may run this command."
+}
+
 func tokenize(msgText string, glob bool) []string {
 	msgText = strings.ReplaceAll(msgText, "\n", " ")
 	var tokens []string
 	if glob {
-		re := regexp.MustCompile(`"([^"]*?)"|(\S+)`)
+		// a quoted value of a flag, such as pools="pool-1 pool-2", stays with its key=
+		re := regexp.MustCompile(`(\S*?=)?"([^"]*?)"|(\S+)`)
 		matches := re.FindAllStringSubmatch(msgText, -1)
 
 		for _, match := range matches {
-			if match[1] != "" {
+			if match[3] == "" {
 				// Remove leading and trailing quotation marks
-				tokens = append(tokens, strings.Trim(match[1], "\""))
+				tokens = append(tokens, match[1]+match[2])
 			} else {
-				tokens = append(tokens, match[2])
+				tokens = append(tokens, match[3])
 			}
 		}
 		return tokens
@@ -117,8 +195,55 @@ func getDMChannelID(client util.SlackClientInterface, evt *slackevents.MessageEv
 	return channel.Latest.Channel, nil
 }
 
+// invokeCommand validates the arguments of a command and, if valid, invokes the callback. If the arguments are
+// not valid, a response describing the expected arguments is returned. The invocation is recorded in the audit log.
+func invokeCommand(ctx context.Context, client util.SlackClientInterface, attribute data.Attributes, msg *slackevents.MessageEvent, args []string) []slack.MsgOption {
+	start := time.Now()
+	response, outcome, err := runCommand(ctx, client, attribute, msg, args)
+	if outcome == data.AuditOutcomeError {
+		log.Warnf("failed processing message: %v, %v", err, response)
+	}
+	recordInvocation(ctx, attribute, msg, args, start, outcome, err)
+	return response
+}
+
+// runCommand validates the arguments and invokes the callback. The outcome of the invocation is returned.
+func runCommand(ctx context.Context, client util.SlackClientInterface, attribute data.Attributes, msg *slackevents.MessageEvent, args []string) ([]slack.MsgOption, string, error) {
+	if hasArgumentSchema(attribute) {
+		parsed, err := parseArgs(attribute, args)
+		if err != nil {
+			return util.StringToBlock(usageError(attribute, err), false), data.AuditOutcomeInvalid, err
+		}
+		return callbackOutcome(attribute.Callback(data.WithParsedArgs(ctx, parsed), client, msg, args))
+	}
+
+	maxExceeded := false
+	if attribute.MaxArgs > 0 && len(args) > attribute.MaxArgs {
+		maxExceeded = true
+	}
+	minRequired := attribute.RequiredArgs > 0 && len(args) < attribute.RequiredArgs
+
+	if len(args) < attribute.RequiredArgs {
+		return []slack.MsgOption{
+			slack.MsgOptionText(fmt.Sprintf("command requires %d arguments.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
+		}, data.AuditOutcomeInvalid, nil
+	} else if minRequired || maxExceeded {
+		return []slack.MsgOption{
+			slack.MsgOptionText(fmt.Sprintf("command requires %d arguments. if an argument is greater than one word, be sure to wrap that argument in quotes.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
+		}, data.AuditOutcomeInvalid, nil
+	}
+	return callbackOutcome(attribute.Callback(ctx, client, msg, args))
+}
+
+func callbackOutcome(response []slack.MsgOption, err error) ([]slack.MsgOption, string, error) {
+	if err != nil {
+		return response, data.AuditOutcomeError, err
+	}
+	return response, data.AuditOutcomeSuccess, nil
+}
+
 func Handler(ctx context.Context, client util.SlackClientInterface, evt slackevents.EventsAPIEvent) error {
-	isAppMentionEvent := false
+	source := sourceMessage
 
 	switch evt.Type {
 	case "message":
@@ -127,11 +252,15 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 		log.Warnf("event type: %s discarded", evt.Type)
 		return nil
 	}
+	if isDuplicateEvent(evt) {
+		log.Debugf("discarding redelivered event")
+		return nil
+	}
 
 	msg := &slackevents.MessageEvent{}
 	switch ev := evt.InnerEvent.Data.(type) {
 	case *slackevents.AppMentionEvent:
-		isAppMentionEvent = true
+		source = sourceAppMention
 		appMentionEvent := evt.InnerEvent.Data.(*slackevents.AppMentionEvent)
 		msg = &slackevents.MessageEvent{
 			Channel:         appMentionEvent.Channel,
@@ -144,7 +273,18 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 		log.Debugf("AppMentionEvent: %s; %s;\n%s", appMentionEvent.User, appMentionEvent.Channel, appMentionEvent.Text)
 	case *slackevents.MessageEvent:
 		msg = evt.InnerEvent.Data.(*slackevents.MessageEvent)
+		switch msg.SubType {
+		case messageChanged:
+			return handleMessageChanged(ctx, client, msg)
+		case messageDeleted:
+			return handleMessageDeleted(client, msg)
+		}
 		log.Debugf("MessageEvent: %s; %s;\n%s", msg.User, msg.Channel, msg.Text)
+	case *slackevents.SubteamMembersChangedEvent:
+		if userGroupResolver != nil {
+			userGroupResolver.HandleMembersChanged(ev)
+		}
+		return nil
 	default:
 		return fmt.Errorf("received an unknown event type: %T", ev)
 	}
@@ -153,117 +293,58 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 		log.Warnf("throwing away message from bot: %s", msg.BotID)
 		return nil
 	}
+	if isDuplicateMessage(msg) {
+		log.Debugf("message %s in %s was already handled", msg.TimeStamp, msg.Channel)
+		return nil
+	}
 
-	var args []string
 	var response []slack.MsgOption
-	for _, attribute := range getAttributes() {
-		log.Debugf("checking command: %v", attribute.Commands)
-
-		// For app mention logic, there are two scenarios: 1.) Channel Msg.  2.) Direct Message
-		// For Channel messages, we want the event to be an AppMention if attribute.RequireMention.
-		// For Direct messages, we will want event to be Message, Channel = "im", and ContainsBotMention
-		// Note, for AppMessage, InnerEvent is AppMessageEvent, for Message, its MessageEvent.
-		if attribute.RequireMention {
-			if isAppMentionEvent && !util.ContainsBotMention(msg.Text) {
-				log.Warnf("command requires a mention: %s", msg.Text)
-				continue
-			} else if !isAppMentionEvent {
-				ieData := evt.InnerEvent.Data.(*slackevents.MessageEvent)
-				channelType := ieData.ChannelType
-
-				if !util.ContainsBotMention(msg.Text) && channelType == slack.TYPE_CHANNEL {
-					log.Warnf("message is targeting a %s and doesnt contain a bot mention: %s", channelType, msg.Text)
-					continue
-				}
-				if channelType == slack.TYPE_IM && !util.ContainsBotMention(msg.Text) {
-					log.Warnf("message is targeting %s and doesnt contain a bot mention: %s", channelType, msg.Text)
-					continue
-				}
+	candidates, rejections := collectCandidates(msg, source)
+	reportCandidates(msg, candidates, rejections)
+	for _, candidate := range candidates {
+		attribute := candidate.attribute
+		args := candidate.args
+		log.Debugf("found command: %v", attribute.Commands)
+		// Now that we found command, make sure it can be used by current user.
+		if err := isAllowedUser(attribute, args, msg); err != nil {
+			recordInvocation(ctx, attribute, msg, args, time.Now(), data.AuditOutcomeDenied, err)
+			_, postErr := client.PostEphemeral(msg.Channel, msg.User, slack.MsgOptionText(err.Error(), false))
+			if postErr != nil {
+				log.Warnf("failed notifying user of denied command: %v", postErr)
 			}
+			return fmt.Errorf("user %s with id %s is not allowed: %v", msg.Username, msg.User, err)
 		}
 
-		if len(attribute.RequireInChannel) > 0 {
-			allowedInChannel := false
-			for _, channel := range attribute.RequireInChannel {
-				if allowedInChannel = channel == msg.Channel; allowedInChannel {
-					break
-				}
-			}
-			if !allowedInChannel {
-				log.Warnf("message must be in a DM: %s", msg.Text)
+		// the tokens are returned when nothing is posted, so catch-alls, such as knowledge, which see every message
+		// are only limited when they respond
+		reservation, err := reserveRateLimit(attribute, msg)
+		if err != nil {
+			if len(attribute.Commands) == 0 {
+				log.Debugf("not responding with %s: %v", describeAttribute(attribute), err)
 				continue
 			}
+			recordInvocation(ctx, attribute, msg, args, time.Now(), data.AuditOutcomeRateLimited, err)
+			_, postErr := client.PostEphemeral(msg.Channel, msg.User, slack.MsgOptionText(err.Error(), false))
+			if postErr != nil {
+				log.Warnf("failed notifying user of rate limited command: %v", postErr)
+			}
+			return fmt.Errorf("user %s with id %s is rate limited: %v", msg.Username, msg.User, err)
 		}
 
-		args = tokenize(msg.Text, !attribute.DontGlobQuotes)
-		if util.ContainsBotMention(msg.Text) {
-			args = args[1:]
+		if isDuplicateInvocation(attribute, msg) {
+			reservation.cancel()
+			return nil
+		}
+		if isAsync(attribute, msg, args) && workers != nil {
+			return runAsync(ctx, client, attribute, msg, args)
 		}
-		log.Warnf("msg: %v\n", msg)
-		if len(msg.Type) > 0 && checkForCommand(args, attribute, msg.Channel) {
-			log.Debugf("found command: %v", attribute.Commands)
-			// Now that we found command, make sure it can be used by current user.
-			if !attribute.AllowNonSplatUsers {
-				err := isAllowedUser(msg)
-				if err != nil {
-					return fmt.Errorf("user not allowed: %v", err)
-				}
-			}
-
-			var err error
-			inThread := len(util.GetThreadUrl(msg)) > 0
-			if attribute.MustBeInThread && !inThread {
-				log.Warnf("message must be in a thread, but isnt: %s", msg.Text)
-				continue
-			}
-
-			maxExceeded := false
-			if attribute.MaxArgs > 0 && len(args) > attribute.MaxArgs {
-				maxExceeded = true
-			}
-			minRequired := attribute.RequiredArgs > 0 && len(args) < attribute.RequiredArgs
-
-			if len(args) < attribute.RequiredArgs {
-				response = []slack.MsgOption{
-					slack.MsgOptionText(fmt.Sprintf("command requires %d arguments.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
-				}
-			} else if minRequired || maxExceeded {
-				response = []slack.MsgOption{
-					slack.MsgOptionText(fmt.Sprintf("command requires %d arguments. if an argument is greater than one word, be sure to wrap that argument in quotes.\n%s\n", attribute.RequiredArgs, attribute.HelpMarkdown), true),
-				}
-			} else {
-				response, err = attribute.Callback(ctx, client, msg, args)
-				if err != nil {
-					log.Warnf("failed processing message: %v, %v", err, response)
-				}
-			}
-			if len(response) > 0 {
-				log.Debugf("responding to message: %v", response)
-				if attribute.RespondInDM {
-					channelID, err := getDMChannelID(client, msg)
-					if err != nil {
-						log.Warnf("failed getting channel ID: %v", err)
-					}
-					msg.Channel = channelID
-				} else if !attribute.RespondInChannel {
-					response = append(response, slack.MsgOptionTS(msg.TimeStamp))
-				} else if len(util.GetThreadUrl(msg)) > 0 {
-					response = append(response, slack.MsgOptionTS(msg.ThreadTimeStamp))
-				}
 
-				log.Debugf("responding to message in channel: %s", msg.Channel)
-				if attribute.ResponseIsEphemeral {
-					_, err = client.PostEphemeral(msg.Channel, msg.User, response...)
-				} else {
-					_, _, err = client.PostMessage(msg.Channel, response...)
-				}
-				if err != nil {
-					return fmt.Errorf("failed responding to message: %v", err)
-				}
-				return nil
-			}
-			log.Debugf("finished processing command")
+		response = invokeCommand(ctx, client, attribute, msg, args)
+		if len(response) > 0 {
+			return respondToMessage(client, attribute, msg, response)
 		}
+		reservation.cancel()
+		log.Debugf("finished processing command")
 	}
 
 	// if the message isn't handled, check to see if this is an IM message
@@ -276,10 +357,11 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 			if err != nil {
 				log.Warnf("failed processing message: %v", err)
 			}
-			if len(response) > 0 {
-				_, _, err = client.PostMessage(msg.Channel, response...)
+			for _, page := range util.SplitResponse(response) {
+				_, _, err = client.PostMessage(msg.Channel, page...)
 				if err != nil {
 					log.Warnf("failed posting message: %v", err)
+					break
 				}
 			}
 		}
@@ -287,10 +369,71 @@ func Handler(ctx context.Context, client util.SlackClientInterface, evt slackeve
 	return nil
 }
 
+// replyTarget returns the channel and thread used to reply to a message as configured by the attribute. The
+// thread is empty when the reply isn't posted in a thread.
+func replyTarget(client util.SlackClientInterface, attribute data.Attributes, msg *slackevents.MessageEvent) (string, string) {
+	channel := msg.Channel
+	threadTS := ""
+	if attribute.RespondInDM {
+		channelID, err := getDMChannelID(client, msg)
+		if err != nil {
+			log.Warnf("failed getting channel ID: %v", err)
+		}
+		channel = channelID
+	} else if !attribute.RespondInChannel {
+		threadTS = msg.TimeStamp
+	} else if len(util.GetThreadUrl(msg)) > 0 {
+		threadTS = msg.ThreadTimeStamp
+	}
+	return channel, threadTS
+}
+
+// inThread returns the options which post a message in a thread.
+func inThread(threadTS string) []slack.MsgOption {
+	if len(threadTS) == 0 {
+		return nil
+	}
+	return []slack.MsgOption{slack.MsgOptionTS(threadTS)}
+}
+
+// respondToMessage posts the response to a message. Responses which don't fit in one message are continued in
+// the thread of the reply.
+func respondToMessage(client util.SlackClientInterface, attribute data.Attributes, msg *slackevents.MessageEvent, response []slack.MsgOption) error {
+	log.Debugf("responding to message: %v", response)
+	channel, threadTS := replyTarget(client, attribute, msg)
+	pages := util.SplitResponse(response)
+
+	log.Debugf("responding to message in channel: %s", channel)
+	if attribute.ResponseIsEphemeral {
+		for _, page := range pages {
+			if _, err := client.PostEphemeral(channel, msg.User, append(page, inThread(threadTS)...)...); err != nil {
+				return fmt.Errorf("failed responding to message: %v", err)
+			}
+		}
+		return nil
+	}
+
+	channel, timestamp, err := client.PostMessage(channel, append(pages[0], inThread(threadTS)...)...)
+	if err != nil {
+		return fmt.Errorf("failed responding to message: %v", err)
+	}
+	if len(threadTS) == 0 {
+		threadTS = timestamp
+	}
+	continuations, err := postContinuations(client, channel, threadTS, pages[1:])
+	trackResponse(attribute, msg, indexedResponse{
+		channel:       channel,
+		timestamp:     timestamp,
+		thread:        threadTS,
+		continuations: continuations,
+	})
+	return err
+}
+
 func checkForCommand(args []string, attribute data.Attributes, channel string) bool {
 	match := true
 	for index, command := range attribute.Commands {
-		if command != args[index] {
+		if index >= len(args) || command != args[index] {
 			match = false
 			break
 		}
@@ -9,10 +9,30 @@ import (
 	"time"
 
 	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/fakeslack"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
+	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
+// TestMain adds the commands and actions of the plugins in this package, which main adds when the plugins
+// are enabled. The CI plugin isn't started so no cluster is needed.
+func TestMain(m *testing.M) {
+	for _, plugin := range []interface {
+		Commands() []data.Attributes
+		Actions() []data.ActionAttributes
+	}{NewCorePlugin(), NewCIPlugin()} {
+		for _, attribute := range plugin.Commands() {
+			AddCommand(attribute)
+		}
+		for _, action := range plugin.Actions() {
+			AddAction(action)
+		}
+	}
+	os.Exit(m.Run())
+}
+
 type AttributesTestCase struct {
 	name       string
 	attributes data.Attributes
@@ -137,8 +157,12 @@ func checkNotRequireMention(tokens []string, client util.SlackClientInterface, a
 
 func TestHandler(t *testing.T) {
 	mockClient := &util.StubInterface{}
-	os.Setenv("SPLAT_BOT_USER_ID", SPLAT_BOT_USER_ID)
-	os.Setenv("SLACK_ALLOWED_USERS", SLACK_ALLOWED_USERS)
+	util.SetSlackConfig(config.SlackConfig{BotUserID: SPLAT_BOT_USER_ID, AllowedUsers: []string{SLACK_ALLOWED_USERS}})
+	// admin commands respond like any other to an admin
+	adminUsers = map[string]bool{"test": true}
+	defer func() {
+		adminUsers = map[string]bool{}
+	}()
 	for _, attribute := range attributes {
 		if err := checkRequireMention(attribute.Commands, mockClient, attribute); err != nil {
 			t.Errorf("test failed for %v: %v", attribute.Commands, err)
@@ -187,3 +211,185 @@ func TestCommands(t *testing.T) {
 		}
 	})
 }
+
+func TestRankCandidates(t *testing.T) {
+	candidates := []candidate{
+		{attribute: data.Attributes{}, order: 0},
+		{attribute: data.Attributes{Commands: []string{"jira"}}, order: 1},
+		{attribute: data.Attributes{Commands: []string{"jira", "create"}}, order: 2},
+		{attribute: data.Attributes{Commands: []string{"jira", "create-with-thread"}}, order: 3},
+		{attribute: data.Attributes{Commands: []string{"ci"}, Rank: 1}, order: 4},
+	}
+	rankCandidates(candidates)
+
+	expected := []string{"ci", "jira create", "jira create-with-thread", "jira", "<catch-all>"}
+	for idx, c := range candidates {
+		if describeAttribute(c.attribute) != expected[idx] {
+			t.Errorf("expected %s at position %d, got %s", expected[idx], idx, describeAttribute(c.attribute))
+		}
+	}
+}
+
+func TestCheckForCommandShortMessage(t *testing.T) {
+	if checkForCommand([]string{"ci"}, LeasesAttributes, "testchannel") {
+		t.Errorf("expected a message shorter than the command not to match")
+	}
+}
+
+func TestSlashCommandHandler(t *testing.T) {
+	mockClient := &util.StubInterface{}
+	err := SlashCommandHandler(context.TODO(), mockClient, slack.SlashCommand{
+		Command:   "/splat",
+		Text:      "help",
+		UserID:    "test",
+		ChannelID: "testchannel",
+	})
+	if err == nil || err.Error() != "failed responding to slash command: PostMessage" {
+		t.Errorf("expected help to respond through the response URL: %v", err)
+	}
+
+	if slashCommandResponseType(HelpAttributes) != slack.ResponseTypeEphemeral {
+		t.Errorf("expected ephemeral commands to respond ephemerally")
+	}
+	if slashCommandResponseType(data.Attributes{RespondInChannel: true}) != slack.ResponseTypeInChannel {
+		t.Errorf("expected non-ephemeral channel commands to respond in channel")
+	}
+}
+
+func TestHandlerWorkspace(t *testing.T) {
+	util.SetSlackConfig(config.SlackConfig{BotUserID: SPLAT_BOT_USER_ID})
+	previousAllowedUsers := allowedUsers
+	allowedUsers = map[string]bool{SLACK_ALLOWED_USERS: true}
+	defer func() {
+		allowedUsers = previousAllowedUsers
+	}()
+	workspace := fakeslack.NewWorkspace(SPLAT_BOT_USER_ID)
+
+	if err := Handler(context.TODO(), workspace, workspace.UserMessage("C1", SLACK_ALLOWED_USERS, fmt.Sprintf("<@%s> help", SPLAT_BOT_USER_ID), "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := Handler(context.TODO(), workspace, workspace.UserMessage("C1", "U1", fmt.Sprintf("<@%s> ci pools", SPLAT_BOT_USER_ID), ""))
+	if err == nil {
+		t.Errorf("expected users who aren't allowed to be denied")
+	}
+
+	posted := workspace.Posted()
+	if len(posted) != 2 {
+		t.Fatalf("expected help and a denial, got %+v", posted)
+	}
+	if posted[0].EphemeralTo != SLACK_ALLOWED_USERS || !strings.Contains(fakeslack.Render(posted[0]), "use `help <command>`") {
+		t.Errorf("expected help to be shown only to the user: %+v", posted[0])
+	}
+	if posted[1].EphemeralTo != "U1" || !strings.Contains(posted[1].Text, "not allowed") {
+		t.Errorf("expected the denial to be shown only to the user: %+v", posted[1])
+	}
+}
+
+func TestHandlerCatchAll(t *testing.T) {
+	util.SetSlackConfig(config.SlackConfig{BotUserID: SPLAT_BOT_USER_ID})
+	previousAttributes := attributes
+	ctx, cancel := context.WithCancel(context.Background())
+	workers = newWorkerPool(ctx, 1, 1)
+	defer func() {
+		cancel()
+		WaitForWorkers()
+		workers = nil
+		attributes = previousAttributes
+		limiters = newRateLimiter()
+	}()
+
+	invoked := 0
+	attributes = []data.Attributes{{
+		AllowNonSplatUsers: true,
+		UserRateLimit:      config.RateLimit{Requests: 1, Per: time.Hour},
+		MessageOfInterest: func(args []string, attribute data.Attributes, channel string) bool {
+			return true
+		},
+		AsyncWhen: func(evt *slackevents.MessageEvent, args []string) bool {
+			return strings.Contains(evt.Text, "slow")
+		},
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			invoked++
+			if strings.Contains(evt.Text, "question") {
+				return util.StringToBlock("the answer", false), nil
+			}
+			return nil, nil
+		},
+	}}
+	workspace := fakeslack.NewWorkspace(SPLAT_BOT_USER_ID)
+
+	// the token is returned when the catch-all doesn't respond, so the user's question is answered
+	for _, text := range []string{"hello", "a question", "another question"} {
+		if err := Handler(ctx, workspace, workspace.UserMessage("C1", "U1", text, "")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if posted := workspace.Posted(); len(posted) != 1 || !strings.Contains(fakeslack.Render(posted[0]), "the answer") {
+		t.Fatalf("expected only the first question to be answered, got %+v", posted)
+	}
+	if invoked != 2 {
+		t.Errorf("expected the catch-all not to be invoked once the user is limited, invoked %d times", invoked)
+	}
+
+	// slow answers are posted by the worker pool
+	limiters = newRateLimiter()
+	if err := Handler(ctx, workspace, workspace.UserMessage("C1", "U1", "a slow question", "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForSlot(t, "U1")
+	posted := workspace.Posted()
+	if len(posted) != 2 || !strings.Contains(fakeslack.Render(posted[1]), "the answer") {
+		t.Errorf("expected the placeholder to be replaced with the answer, got %+v", posted)
+	}
+}
+
+func TestSlashCommandCatchAll(t *testing.T) {
+	previousAttributes := attributes
+	defer func() {
+		attributes = previousAttributes
+		limiters = newRateLimiter()
+	}()
+
+	invoked := 0
+	attributes = []data.Attributes{{
+		AllowNonSplatUsers: true,
+		UserRateLimit:      config.RateLimit{Requests: 1, Per: time.Hour},
+		MessageOfInterest: func(args []string, attribute data.Attributes, channel string) bool {
+			return true
+		},
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			invoked++
+			if strings.Contains(evt.Text, "question") {
+				return util.StringToBlock("the answer", false), nil
+			}
+			return nil, nil
+		},
+	}}
+	workspace := fakeslack.NewWorkspace(SPLAT_BOT_USER_ID)
+
+	// the token is returned when the catch-all doesn't respond, and a limited catch-all is skipped
+	for _, text := range []string{"hello", "a question", "another question"} {
+		err := SlashCommandHandler(context.TODO(), workspace, slack.SlashCommand{
+			Command:     "/splat",
+			Text:        text,
+			UserID:      "U1",
+			ChannelID:   "C1",
+			ResponseURL: "https://hooks.slack.com/commands/1",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	posted := workspace.Posted()
+	if len(posted) != 3 {
+		t.Fatalf("expected three responses, got %+v", posted)
+	}
+	for idx, expected := range []string{"I don't know how to help", "the answer", "I don't know how to help"} {
+		if !strings.Contains(fakeslack.Render(posted[idx]), expected) {
+			t.Errorf("expected response %d to contain %q: %s", idx, expected, fakeslack.Render(posted[idx]))
+		}
+	}
+	if invoked != 2 {
+		t.Errorf("expected the catch-all not to be invoked once the user is limited, invoked %d times", invoked)
+	}
+}
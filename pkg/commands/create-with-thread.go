@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/openshift-splat-team/jira-bot/cmd/issue"
 	"github.com/openshift-splat-team/splat-bot/data"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack"
@@ -13,6 +12,7 @@ import (
 
 var CreateJiraWithThreadAttributes = data.Attributes{
 	Commands:            []string{"jira", "create-with-thread"},
+	Mutating:            true,
 	RequireMention:      true,
 	ResponseIsEphemeral: true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
@@ -21,16 +21,20 @@ var CreateJiraWithThreadAttributes = data.Attributes{
 		if len(url) > 0 {
 			description = fmt.Sprintf("%s\n\ncreated from thread: %s", description, url)
 		}
-		issue, err := issue.CreateIssue(args[2], "follow up on slack thread", description, args[3])
+		parsed := data.GetParsedArgs(ctx)
+		issue, err := util.CreateJiraIssue(parsed.String("project"), "follow up on slack thread", description, parsed.String("type"))
 		if err != nil {
 			return util.WrapErrorToBlock(err, "error creating issue"), nil
 		}
 		issueKey := issue.Key
-		issueURL := fmt.Sprintf("%s/browse/%s", JIRA_BASE_URL, issueKey)
+		issueURL := util.JiraIssueURL(issueKey)
 		return util.StringToBlock(fmt.Sprintf("issue <%s|%s> created", issueURL, issueKey), false), nil
 	},
-	RequiredArgs: 4,
-	HelpMarkdown: "create a Jira issue with a summary of the thread: `jira create-with-thread [project] [type]`",
+	Description: "create a Jira issue with a summary of the thread",
+	Arguments: []data.Argument{
+		{Name: "project", Required: true, Help: "Jira project key. e.g. SPLAT"},
+		{Name: "type", Required: true, Help: "Jira issue type. e.g. Task"},
+	},
 	ShouldMatch: []string{
 		"jira create-with-thread PROJECT bug",
 		"jira create-with-thread PROJECT Todo",
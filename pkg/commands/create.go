@@ -9,7 +9,6 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/openshift-splat-team/jira-bot/cmd/issue"
 	"github.com/openshift-splat-team/splat-bot/data"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack"
@@ -64,6 +63,7 @@ func invokeTemplate(t *template.Template, data any) (string, error) {
 
 var CreateAttributes = data.Attributes{
 	Commands:       []string{"jira", "create"},
+	Mutating:       true,
 	RequireMention: true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
 		var description string
@@ -78,11 +78,12 @@ var CreateAttributes = data.Attributes{
 		}
 		url := util.GetThreadUrl(evt)
 		log.Debugf("%v", args)
-		summary := args[2]
+		parsed := data.GetParsedArgs(ctx)
+		summary := parsed.String("summary")
 
-		if len(args) >= 4 {
-			assistantCtx.Goal = args[2]
-			assistantCtx.Outcome = args[3]
+		if parsed.IsSet("outcome") {
+			assistantCtx.Goal = summary
+			assistantCtx.Outcome = parsed.String("outcome")
 		}
 
 		// Execute the template and write the result into the buffer
@@ -95,17 +96,19 @@ var CreateAttributes = data.Attributes{
 			description = fmt.Sprintf("%s\n\ncreated from thread: %s", description, url)
 		}
 
-		issue, err := issue.CreateIssue("SPLAT", summary, description, "Task")
+		issue, err := util.CreateJiraIssue("SPLAT", summary, description, "Task")
 		if err != nil {
 			return util.WrapErrorToBlock(err, "error creating issue"), nil
 		}
 		issueKey := issue.Key
-		issueURL := fmt.Sprintf("%s/browse/%s", JIRA_BASE_URL, issueKey)
+		issueURL := util.JiraIssueURL(issueKey)
 		return util.StringToBlock(fmt.Sprintf("issue <%s|%s> created", issueURL, issueKey), false), nil
 	},
-	RequiredArgs: 3,
-	MaxArgs:      4,
-	HelpMarkdown: "create a Jira issue: `jira create \"[description]\"`",
+	Description: "create a Jira issue",
+	Arguments: []data.Argument{
+		{Name: "summary", Required: true, Help: "summary of the issue. wrap in quotes if more than one word"},
+		{Name: "outcome", Help: "outcome of the user story"},
+	},
 	ShouldMatch: []string{
 		"jira create description",
 		"jira create description",
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+const (
+	// DEFAULT_DEDUP_TTL how long deliveries and invocations are remembered. Slack gives up redelivering an
+	// event well within this period.
+	DEFAULT_DEDUP_TTL = 10 * time.Minute
+
+	// maxDedupEntries bounds the number of keys remembered by a cache.
+	maxDedupEntries = 10000
+)
+
+var (
+	// deliveries remembers the events and messages which have been handled.
+	deliveries = newDedupCache(DEFAULT_DEDUP_TTL)
+	// invocations remembers the messages which have invoked a mutating command.
+	invocations = newDedupCache(DEFAULT_DEDUP_TTL)
+)
+
+// dedupCache remembers keys for a TTL.
+type dedupCache struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[string]time.Time
+}
+
+func newDedupCache(ttl time.Duration) *dedupCache {
+	return &dedupCache{
+		ttl:  ttl,
+		seen: map[string]time.Time{},
+	}
+}
+
+// firstSeen records the key and returns true if it wasn't seen within the TTL.
+func (d *dedupCache) firstSeen(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if seenAt, ok := d.seen[key]; ok && now.Sub(seenAt) <= d.ttl {
+		return false
+	}
+	if len(d.seen) >= maxDedupEntries {
+		for existing, seenAt := range d.seen {
+			if now.Sub(seenAt) > d.ttl {
+				delete(d.seen, existing)
+			}
+		}
+		// if everything is recent, forget arbitrary keys rather than grow without bound
+		for existing := range d.seen {
+			if len(d.seen) < maxDedupEntries {
+				break
+			}
+			delete(d.seen, existing)
+		}
+	}
+	d.seen[key] = now
+	return true
+}
+
+// IsDuplicateDelivery returns true if a Socket Mode envelope was already received. Socket Mode redelivers an
+// envelope with the same ID when it isn't acknowledged in time.
+func IsDuplicateDelivery(envelopeID string) bool {
+	if len(envelopeID) == 0 {
+		return false
+	}
+	return !deliveries.firstSeen(fmt.Sprintf("envelope/%s", envelopeID))
+}
+
+// isDuplicateEvent returns true if the event was already handled.
+func isDuplicateEvent(evt slackevents.EventsAPIEvent) bool {
+	callback, ok := evt.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok || len(callback.EventID) == 0 {
+		return false
+	}
+	return !deliveries.firstSeen(fmt.Sprintf("event/%s", callback.EventID))
+}
+
+// isDuplicateMessage returns true if the message was already handled. A mention in a channel is delivered both
+// as an app_mention and a message event.
+func isDuplicateMessage(msg *slackevents.MessageEvent) bool {
+	if len(msg.TimeStamp) == 0 {
+		return false
+	}
+	return !deliveries.firstSeen(fmt.Sprintf("message/%s/%s", msg.Channel, msg.TimeStamp))
+}
+
+// isDuplicateInvocation returns true if a mutating command was already invoked by the message.
+func isDuplicateInvocation(attribute data.Attributes, msg *slackevents.MessageEvent) bool {
+	if !attribute.Mutating || len(msg.TimeStamp) == 0 {
+		return false
+	}
+	key := fmt.Sprintf("%s/%s/%s", describeAttribute(attribute), msg.Channel, msg.TimeStamp)
+	if invocations.firstSeen(key) {
+		return false
+	}
+	log.Warnf("refusing to run %s again for message %s in %s", describeAttribute(attribute), msg.TimeStamp, msg.Channel)
+	return true
+}
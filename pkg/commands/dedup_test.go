@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestDedupCache(t *testing.T) {
+	cache := newDedupCache(DEFAULT_DEDUP_TTL)
+	if !cache.firstSeen("a") {
+		t.Errorf("expected the first delivery to be new")
+	}
+	if cache.firstSeen("a") {
+		t.Errorf("expected the second delivery to be a duplicate")
+	}
+
+	expired := newDedupCache(0)
+	expired.firstSeen("a")
+	if !expired.firstSeen("a") {
+		t.Errorf("expected the key to be forgotten after the TTL")
+	}
+}
+
+func TestDuplicateDeliveries(t *testing.T) {
+	attributeMu.Lock()
+	saved := attributes
+	attributes = []data.Attributes{}
+	attributeMu.Unlock()
+	defer func() {
+		attributeMu.Lock()
+		attributes = saved
+		attributeMu.Unlock()
+		deliveries = newDedupCache(DEFAULT_DEDUP_TTL)
+		invocations = newDedupCache(DEFAULT_DEDUP_TTL)
+	}()
+
+	invoked := 0
+	AddCommand(data.Attributes{
+		Commands:           []string{"ci", "lease"},
+		AllowNonSplatUsers: true,
+		Mutating:           true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			invoked++
+			return nil, nil
+		},
+	})
+
+	ctx := context.TODO()
+	client := &util.StubInterface{}
+	msg := &slackevents.MessageEvent{Type: "message", Channel: "C1", User: "U1", Text: "ci lease acquire", TimeStamp: "1000.0001"}
+	redelivered := slackevents.EventsAPIEvent{
+		Type: "event_callback",
+		Data: &slackevents.EventsAPICallbackEvent{EventID: "Ev1"},
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: "message",
+			Data: msg,
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if err := Handler(ctx, client, redelivered); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the mention is also delivered as an app_mention with another event ID
+	mention := slackevents.EventsAPIEvent{
+		Type: "event_callback",
+		Data: &slackevents.EventsAPICallbackEvent{EventID: "Ev2"},
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: "app_mention",
+			Data: &slackevents.AppMentionEvent{Channel: "C1", User: "U1", Text: "ci lease acquire", TimeStamp: "1000.0001"},
+		},
+	}
+	if err := Handler(ctx, client, mention); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if invoked != 1 {
+		t.Errorf("expected the command to be invoked once, invoked %d times", invoked)
+	}
+
+	// mutating commands refuse to run twice for a message even if it gets past the delivery checks
+	attribute := getAttributes()[0]
+	msg = &slackevents.MessageEvent{Channel: "C1", TimeStamp: "1000.0002"}
+	if isDuplicateInvocation(attribute, msg) {
+		t.Errorf("expected the first invocation to be allowed")
+	}
+	if !isDuplicateInvocation(attribute, msg) {
+		t.Errorf("expected the second invocation to be refused")
+	}
+
+	if IsDuplicateDelivery("envelope-1") || !IsDuplicateDelivery("envelope-1") {
+		t.Errorf("expected the redelivered envelope to be a duplicate")
+	}
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// candidate is an attribute which matched an incoming message along with the
+// arguments tokenized for that attribute.
+type candidate struct {
+	attribute data.Attributes
+	args      []string
+	// order is the registration order of the attribute. it is used as the final tie-breaker.
+	order int
+}
+
+// messageSource describes how a message reached the bot.
+type messageSource int
+
+const (
+	// sourceMessage a message event in a channel or DM the bot is a member of.
+	sourceMessage messageSource = iota
+	// sourceAppMention an app_mention event.
+	sourceAppMention
+	// sourceSlashCommand a slash command. slash commands are addressed to the bot so a mention is not required.
+	sourceSlashCommand
+	// sourceScheduledJob the command of a scheduled job. a mention is not required.
+	sourceScheduledJob
+)
+
+// rejection records why an attribute was not considered for a message.
+type rejection struct {
+	attribute data.Attributes
+	reason    string
+}
+
+func describeAttribute(attribute data.Attributes) string {
+	if len(attribute.Commands) == 0 {
+		return "<catch-all>"
+	}
+	return strings.Join(attribute.Commands, " ")
+}
+
+// evaluateAttribute checks if an attribute is applicable to a message. If it is not, the reason is returned.
+func evaluateAttribute(attribute data.Attributes, msg *slackevents.MessageEvent, source messageSource) ([]string, string) {
+	// For app mention logic, there are two scenarios: 1.) Channel Msg.  2.) Direct Message
+	// For Channel messages, we want the event to be an AppMention if attribute.RequireMention.
+	// For Direct messages, we will want event to be Message, Channel = "im", and ContainsBotMention
+	// Note, for AppMessage, InnerEvent is AppMessageEvent, for Message, its MessageEvent.
+	if attribute.RequireMention && source != sourceSlashCommand && source != sourceScheduledJob {
+		if source == sourceAppMention && !util.ContainsBotMention(msg.Text) {
+			return nil, "command requires a mention"
+		} else if source == sourceMessage {
+			channelType := msg.ChannelType
+
+			if !util.ContainsBotMention(msg.Text) && channelType == slack.TYPE_CHANNEL {
+				return nil, fmt.Sprintf("message is targeting a %s and doesnt contain a bot mention", channelType)
+			}
+			if channelType == slack.TYPE_IM && !util.ContainsBotMention(msg.Text) {
+				return nil, fmt.Sprintf("message is targeting %s and doesnt contain a bot mention", channelType)
+			}
+		}
+	}
+
+	if len(attribute.RequireInChannel) > 0 {
+		allowedInChannel := false
+		for _, channel := range attribute.RequireInChannel {
+			if allowedInChannel = channel == msg.Channel; allowedInChannel {
+				break
+			}
+		}
+		if !allowedInChannel {
+			return nil, fmt.Sprintf("message must be in one of channels %v", attribute.RequireInChannel)
+		}
+	}
+
+	args := tokenize(msg.Text, !attribute.DontGlobQuotes)
+	if util.ContainsBotMention(msg.Text) {
+		args = args[1:]
+	}
+
+	if len(msg.Type) == 0 {
+		return nil, "message has no type"
+	}
+	if !checkForCommand(args, attribute, msg.Channel) {
+		return nil, "command does not match"
+	}
+
+	inThread := len(util.GetThreadUrl(msg)) > 0
+	if attribute.MustBeInThread && !inThread {
+		return nil, "message must be in a thread, but isnt"
+	}
+	return args, ""
+}
+
+// rankCandidates orders candidates so the best match is first. Candidates with a higher Rank win. When
+// ranks are equal, the candidate with the most specific command (the longest Commands) wins. Registration
+// order breaks any remaining ties.
+func rankCandidates(candidates []candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.attribute.Rank != b.attribute.Rank {
+			return a.attribute.Rank > b.attribute.Rank
+		}
+		if len(a.attribute.Commands) != len(b.attribute.Commands) {
+			return len(a.attribute.Commands) > len(b.attribute.Commands)
+		}
+		return a.order < b.order
+	})
+}
+
+// collectCandidates returns the attributes which match a message in the order in which they should
+// be dispatched.
+func collectCandidates(msg *slackevents.MessageEvent, source messageSource) ([]candidate, []rejection) {
+	var candidates []candidate
+	var rejections []rejection
+	for order, attribute := range getAttributes() {
+		args, reason := evaluateAttribute(attribute, msg, source)
+		if len(reason) > 0 {
+			log.Debugf("skipping command %s: %s", describeAttribute(attribute), reason)
+			rejections = append(rejections, rejection{attribute: attribute, reason: reason})
+			continue
+		}
+		candidates = append(candidates, candidate{attribute: attribute, args: args, order: order})
+	}
+	rankCandidates(candidates)
+	return candidates, rejections
+}
+
+// reportCandidates logs every attribute considered for a message and why it was or wasn't chosen. Reporting
+// is enabled by setting DEBUG_COMMAND_DISPATCH=true.
+func reportCandidates(msg *slackevents.MessageEvent, candidates []candidate, rejections []rejection) {
+	if !debugCommandDispatch {
+		return
+	}
+	log.Infof("dispatch report for message: %s", msg.Text)
+	for idx, c := range candidates {
+		verdict := "chosen"
+		if idx > 0 {
+			verdict = fmt.Sprintf("outranked by %s, used only if higher ranked commands do not respond", describeAttribute(candidates[0].attribute))
+		}
+		log.Infof("  candidate %s (rank: %d, specificity: %d): %s", describeAttribute(c.attribute), c.attribute.Rank, len(c.attribute.Commands), verdict)
+	}
+	for _, r := range rejections {
+		log.Infof("  not a candidate %s: %s", describeAttribute(r.attribute), r.reason)
+	}
+}
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/openshift-splat-team/splat-bot/data"
@@ -10,33 +12,185 @@ import (
 	"github.com/slack-go/slack/slackevents"
 )
 
-func compileHelp() slack.MsgOption {
-	helpText := strings.Builder{}
+// helpArea returns the area a command is grouped under in help.
+func helpArea(attribute data.Attributes) string {
+	if len(attribute.HelpArea) > 0 {
+		return attribute.HelpArea
+	}
+	if len(attribute.Commands) > 0 {
+		return attribute.Commands[0]
+	}
+	return "other"
+}
+
+// getHelpAttributes returns the commands which are shown in help to the user.
+func getHelpAttributes(evt *slackevents.MessageEvent) []data.Attributes {
+	var visible []data.Attributes
 	for _, attribute := range getAttributes() {
 		if attribute.ExcludeFromHelp {
 			continue
 		}
-		helpText.WriteString("- ")
-		helpText.WriteString(attribute.HelpMarkdown)
-		helpText.WriteString("\n")
+		if isAllowedUser(attribute, attribute.Commands, evt) != nil {
+			continue
+		}
+		visible = append(visible, attribute)
+	}
+	return visible
+}
+
+// compileHelp returns help for each command the user is allowed to run, grouped by area.
+func compileHelp(evt *slackevents.MessageEvent) slack.MsgOption {
+	areas := map[string][]string{}
+	for _, attribute := range getHelpAttributes(evt) {
+		area := helpArea(attribute)
+		areas[area] = append(areas[area], helpMarkdown(attribute))
 	}
 
+	var names []string
+	for name := range areas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	helpText := strings.Builder{}
+	for _, name := range names {
+		helpText.WriteString(fmt.Sprintf("*%s*\n", name))
+		for _, line := range areas[name] {
+			helpText.WriteString("- ")
+			helpText.WriteString(line)
+			helpText.WriteString("\n")
+		}
+	}
+	helpText.WriteString("\nuse `help <command>` for details about a command.")
+
 	return util.StringsToBlockUnfurl([]string{helpText.String()}, false, false)[0]
 }
 
+// hasCommandPrefix returns true if the attribute's Commands begin with path.
+func hasCommandPrefix(attribute data.Attributes, path []string) bool {
+	if len(attribute.Commands) < len(path) {
+		return false
+	}
+	for idx, token := range path {
+		if attribute.Commands[idx] != token {
+			return false
+		}
+	}
+	return true
+}
+
+// describePermissions describes who may run a command.
+func describePermissions(attribute data.Attributes) string {
+	if rule := getPolicyRule(attribute, attribute.Commands); rule != nil {
+		return fmt.Sprintf("requires %s", ruleRequirement(rule))
+	}
+	if attribute.AdminOnly {
+		return adminRequirement()
+	}
+	if attribute.AllowNonSplatUsers || (len(allowedUsers) == 0 && len(allowedGroups) == 0) {
+		return "anyone may run this command"
+	}
+	return defaultRequirement()
+}
+
+// commandHelpPage returns the full help for a command. Only the subcommands in visible are listed.
+func commandHelpPage(attribute data.Attributes, visible []data.Attributes) string {
+	page := strings.Builder{}
+	page.WriteString(fmt.Sprintf("*%s*\n", strings.Join(attribute.Commands, " ")))
+	if len(attribute.Description) > 0 {
+		page.WriteString(fmt.Sprintf("%s\n", attribute.Description))
+	} else if len(attribute.HelpMarkdown) > 0 {
+		page.WriteString(fmt.Sprintf("%s\n", attribute.HelpMarkdown))
+	}
+	if hasArgumentSchema(attribute) {
+		page.WriteString(fmt.Sprintf("\n*usage:* %s\n", usage(attribute)))
+		for _, line := range argumentHelp(attribute) {
+			page.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+	}
+
+	var subcommands []string
+	for _, other := range visible {
+		if len(other.Commands) > len(attribute.Commands) && hasCommandPrefix(other, attribute.Commands) {
+			subcommands = append(subcommands, fmt.Sprintf("`%s`", strings.Join(other.Commands, " ")))
+		}
+	}
+	if len(subcommands) > 0 {
+		page.WriteString(fmt.Sprintf("\n*subcommands:* %s\n", strings.Join(subcommands, ", ")))
+	}
+
+	page.WriteString(fmt.Sprintf("\n*permissions:* %s\n", describePermissions(attribute)))
+	if attribute.RequireMention {
+		page.WriteString("- the bot must be mentioned\n")
+	}
+	if attribute.MustBeInThread {
+		page.WriteString("- must be used in a thread\n")
+	}
+	if len(attribute.RequireInChannel) > 0 {
+		var channels []string
+		for _, channel := range attribute.RequireInChannel {
+			channels = append(channels, fmt.Sprintf("<#%s>", channel))
+		}
+		page.WriteString(fmt.Sprintf("- only available in %s\n", strings.Join(channels, ", ")))
+	}
+
+	if len(attribute.ShouldMatch) > 0 {
+		page.WriteString("\n*examples:*\n")
+		for _, example := range attribute.ShouldMatch {
+			page.WriteString(fmt.Sprintf("- `%s`\n", example))
+		}
+	}
+	return page.String()
+}
+
+// compileCommandHelp returns help for a command path. A path which exactly matches a command shows its full help.
+// Otherwise, the commands beginning with the path, or in the area named by the path, are listed. Only the commands
+// the user is allowed to run are shown.
+func compileCommandHelp(evt *slackevents.MessageEvent, path []string) slack.MsgOption {
+	visible := getHelpAttributes(evt)
+	var pages []string
+	var listed []string
+	for _, attribute := range visible {
+		if len(attribute.Commands) == len(path) && hasCommandPrefix(attribute, path) {
+			pages = append(pages, commandHelpPage(attribute, visible))
+		}
+	}
+	if len(pages) == 0 {
+		for _, attribute := range visible {
+			if hasCommandPrefix(attribute, path) || (len(path) == 1 && helpArea(attribute) == path[0]) {
+				listed = append(listed, fmt.Sprintf("- %s", helpMarkdown(attribute)))
+			}
+		}
+		if len(listed) > 0 {
+			pages = append(pages, fmt.Sprintf("*%s*\n%s", strings.Join(path, " "), strings.Join(listed, "\n")))
+		}
+	}
+	if len(pages) == 0 {
+		pages = append(pages, fmt.Sprintf("no help found for `%s`. use `help` to list commands.", strings.Join(path, " ")))
+	}
+
+	return util.StringsToBlockUnfurl(pages, false, false)[0]
+}
+
 var HelpAttributes = data.Attributes{
 	Commands:        []string{"help"},
 	RequireMention:  true,
 	ExcludeFromHelp: true,
-	Callback: func(ctx context.Context, client util.SlackClientInterface, eventsAPIEvent *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		if len(args) > 1 {
+			return []slack.MsgOption{
+				compileCommandHelp(evt, args[1:]),
+			}, nil
+		}
 		return []slack.MsgOption{
-			compileHelp(),
+			compileHelp(evt),
 		}, nil
 	},
 	ResponseIsEphemeral: true,
 	RespondInChannel:    true,
 	ShouldMatch: []string{
 		"help",
+		"help ci lease",
 	},
 	ShouldntMatch: []string{
 		"jira create-with-summary PROJECT bug",
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestCommandHelpPage(t *testing.T) {
+	page := commandHelpPage(LeasesAttributes, getAttributes())
+	for _, expected := range []string{
+		"*ci lease*",
+		"*usage:* `ci lease [action]",
+		"`cpus` (int): vCPUs to lease. default: `24`",
+		"*permissions:*",
+		"- the bot must be mentioned",
+		"- `ci lease release`",
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected help page to contain %q:\n%s", expected, page)
+		}
+	}
+}
+
+func TestHelpHidesUnauthorizedCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	if err := loadPolicy(path); err != nil {
+		t.Fatalf("unable to load policy: %v", err)
+	}
+	defer func() {
+		commandPolicy = nil
+	}()
+
+	isVisible := func(user string) bool {
+		for _, attribute := range getHelpAttributes(&slackevents.MessageEvent{User: user}) {
+			if strings.Join(attribute.Commands, " ") == "ci pools" {
+				return true
+			}
+		}
+		return false
+	}
+	if !isVisible("U1") {
+		t.Errorf("expected `ci pools` to be shown to an allowed user")
+	}
+	if isVisible("U3") {
+		t.Errorf("expected `ci pools` to be hidden from a user who isn't allowed")
+	}
+
+	helpText := func(user string, path ...string) string {
+		_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", compileCommandHelp(&slackevents.MessageEvent{User: user}, path))
+		return values.Get("blocks") + values.Get("text")
+	}
+	if page := helpText("U3", "ci", "pools"); !strings.Contains(page, "no help found for `ci pools`") {
+		t.Errorf("expected no help for a command the user isn't allowed to run:\n%s", page)
+	}
+	if page := helpText("U1", "ci", "pools"); !strings.Contains(page, "*ci pools*") {
+		t.Errorf("expected help for a command the user is allowed to run:\n%s", page)
+	}
+	if !strings.Contains(describePermissions(PoolsAttributes), "being explicitly listed in the policy") {
+		t.Errorf("unexpected permissions: %s", describePermissions(PoolsAttributes))
+	}
+}
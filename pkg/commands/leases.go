@@ -4,10 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 
@@ -16,8 +13,30 @@ import (
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 )
 
-func init() {
-	AddCommand(LeasesAttributes)
+var LeaseRenewActionAttributes = data.ActionAttributes{
+	ActionID:        controllers.LeaseRenewActionID,
+	Commands:        []string{"ci", "lease", "renew"},
+	ReplaceOriginal: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		expires, err := controllers.RenewLease(ctx, interaction.User.ID)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to renew lease: %w", err)
+		}
+		return util.StringToBlock(fmt.Sprintf("Your lease has been renewed. It expires at %s", expires), false), nil
+	},
+}
+
+var LeaseReleaseActionAttributes = data.ActionAttributes{
+	ActionID:        controllers.LeaseReleaseActionID,
+	Commands:        []string{"ci", "lease", "release"},
+	ReplaceOriginal: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		err := controllers.RemoveLease(ctx, interaction.User.ID)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to release lease: %w", err)
+		}
+		return util.StringToBlock("Your lease(s) and associated resources are being deleted. You will receive a notification when this is complete.", false), nil
+	},
 }
 
 type leaseOptions struct {
@@ -27,37 +46,12 @@ type leaseOptions struct {
 	pool     string
 }
 
-func getLeaseOptions(args []string) leaseOptions {
-	cpus := 24
-	memory := 96
-	networks := 1
-	pools := ""
-	log.Printf("lease args: %v", args)
-	if len(args) >= 4 {
-		log.Printf("applying options to lease")
-		for _, arg := range args[3:] {
-			parts := strings.Split(arg, "=")
-			if len(parts) != 2 {
-				continue
-			}
-			switch parts[0] {
-			case "cpus":
-				cpus, _ = strconv.Atoi(parts[1])
-			case "memory":
-				memory, _ = strconv.Atoi(parts[1])
-			case "networks":
-				networks, _ = strconv.Atoi(parts[1])
-			case "pools":
-				// Need to remove the double quotes if added for multiple pools
-				pools = strings.Replace(parts[1], "\"", "", -1)
-			}
-		}
-	}
+func getLeaseOptions(args *data.ParsedArgs) leaseOptions {
 	return leaseOptions{
-		cpus:     cpus,
-		memory:   memory,
-		networks: networks,
-		pool:     pools,
+		cpus:     args.Int("cpus"),
+		memory:   args.Int("memory"),
+		networks: args.Int("networks"),
+		pool:     args.String("pools"),
 	}
 }
 
@@ -86,54 +80,62 @@ func validateLeaseOptions(ctx context.Context, options leaseOptions) error {
 
 var LeasesAttributes = data.Attributes{
 	Commands:       []string{"ci", "lease"},
+	Mutating:       true,
 	RequireMention: true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
 		result := ""
 		var err error
-		if len(args) > 2 {
-			switch args[2] {
-			case "acquire":
-				options := getLeaseOptions(args)
+		parsed := data.GetParsedArgs(ctx)
+		switch parsed.String("action") {
+		case "acquire":
+			options := getLeaseOptions(parsed)
 
-				if err = validateLeaseOptions(ctx, options); err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
-				}
+			if err = validateLeaseOptions(ctx, options); err != nil {
+				return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
+			}
 
-				_, err := controllers.AcquireLease(ctx, evt.User, options.cpus, options.memory, options.pool, options.networks)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
-				}
-				result = "Lease(s) have been created. Once fulfilled by the vSphere capacity manager you will receive a direct message " +
-					"with further details. This could take a few minutes."
-			case "renew":
-				expires, err := controllers.RenewLease(ctx, evt.User)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to renew lease: %w", err)
-				}
-				result = fmt.Sprintf("Your lease has been renewed. It expires at %s", expires)
-			case "release":
-				err = controllers.RemoveLease(ctx, evt.User)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
-				}
-				result = "Your lease(s) and associated resources are being deleted. You will receive a notification when this is complete."
-			case "list":
-				fallthrough
-			default:
-				result, err = controllers.GetLeaseStatus(evt.User)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to fetch pool status: %w", err)
-				}
+			_, err := controllers.AcquireLease(ctx, evt.User, options.cpus, options.memory, options.pool, options.networks)
+			if err != nil {
+				return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to acquire lease: %w", err)
+			}
+			result = "Lease(s) have been created. Once fulfilled by the vSphere capacity manager you will receive a direct message " +
+				"with further details. This could take a few minutes."
+		case "renew":
+			expires, err := controllers.RenewLease(ctx, evt.User)
+			if err != nil {
+				return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to renew lease: %w", err)
+			}
+			result = fmt.Sprintf("Your lease has been renewed. It expires at %s", expires)
+		case "release":
+			err = controllers.RemoveLease(ctx, evt.User)
+			if err != nil {
+				return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
+			}
+			result = "Your lease(s) and associated resources are being deleted. You will receive a notification when this is complete."
+		case "list":
+			fallthrough
+		default:
+			result, err = controllers.GetLeaseStatus(evt.User)
+			if err != nil {
+				return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to fetch pool status: %w", err)
 			}
 		}
 
 		return util.StringToBlock(result, false), nil
 	},
-	RequiredArgs: 0,
-	HelpMarkdown: "interact with your vSphere CI leases: `ci lease list|acquire|release`",
+	Description: "interact with your vSphere CI leases",
+	Arguments: []data.Argument{
+		{Name: "action", Enum: []string{"list", "acquire", "renew", "release"}, Default: "list"},
+	},
+	Flags: []data.Argument{
+		{Name: "cpus", Type: data.ArgTypeInt, Default: "24", Help: "vCPUs to lease"},
+		{Name: "memory", Type: data.ArgTypeInt, Default: "96", Help: "memory to lease in GB"},
+		{Name: "networks", Type: data.ArgTypeInt, Default: "1", Help: "networks to lease"},
+		{Name: "pools", Help: "space separated names of the pools to lease from"},
+	},
 	ShouldMatch: []string{
 		"ci lease list",
-		"ci lease acquire (optional args) cpus=24 memory=96 networks=1 pools=\"space-separated-pool-names\"",
+		"ci lease acquire cpus=24 memory=96 networks=1 pools=\"space-separated-pool-names\"",
 		"ci lease release",
 	},
 	ShouldntMatch: []string{
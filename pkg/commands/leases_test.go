@@ -34,11 +34,18 @@ func TestValidateLeaseOptions(t *testing.T) {
 			},
 			expectedPoolValue: "pool1",
 		},
+		{
+			name:              "Pool names with spaces in quotes",
+			options:           tokenize("cpus=4 memory=16 networks=1 pools=\"pool1 pool2\"", true),
+			expectedPoolValue: "pool1 pool2",
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			options := getLeaseOptions(tc.options)
+			parsed, err := parseArgs(LeasesAttributes, append([]string{"ci", "lease", "acquire"}, tc.options...))
+			gs.Expect(err).NotTo(HaveOccurred())
+			options := getLeaseOptions(parsed)
 
 			gs.Expect(options.pool).To(Equal(tc.expectedPoolValue))
 		})
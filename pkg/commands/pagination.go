@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// ShowMoreActionID the action_id of the button which shows the next page of paginated results.
+	ShowMoreActionID = "show-more"
+	// DEFAULT_PAGINATION_TTL how long the pages of a paginated response can be shown.
+	DEFAULT_PAGINATION_TTL = time.Hour
+
+	paginationExpired = "these results have expired. run the command again to see them."
+)
+
+var paginations = newPaginationStore(DEFAULT_PAGINATION_TTL)
+
+// pagination is a list of results which is shown a page at a time.
+type pagination struct {
+	title   string
+	items   []string
+	perPage int
+	created time.Time
+}
+
+func (p pagination) pages() int {
+	return (len(p.items) + p.perPage - 1) / p.perPage
+}
+
+// paginationStore holds paginated results, keyed by a cursor, until they expire. Results are held in memory so
+// "Show more" only works on the replica which responded to the command.
+type paginationStore struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	paginations map[string]pagination
+}
+
+func newPaginationStore(ttl time.Duration) *paginationStore {
+	return &paginationStore{
+		ttl:         ttl,
+		paginations: map[string]pagination{},
+	}
+}
+
+// put holds results and returns their cursor. Expired results are pruned.
+func (s *paginationStore) put(p pagination) (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("unable to create cursor: %v", err)
+	}
+	cursor := hex.EncodeToString(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, existing := range s.paginations {
+		if now.Sub(existing.created) > s.ttl {
+			delete(s.paginations, key)
+		}
+	}
+	p.created = now
+	s.paginations[cursor] = p
+	return cursor, nil
+}
+
+func (s *paginationStore) get(cursor string) (pagination, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.paginations[cursor]
+	if !ok || time.Since(p.created) > s.ttl {
+		return pagination{}, false
+	}
+	return p, true
+}
+
+// paginate returns the first page of a list of results. When there is more than one page, the response has a
+// "Show more" button which replaces the response with the next page.
+func paginate(title string, items []string, perPage int) []slack.MsgOption {
+	p := pagination{title: title, items: items, perPage: perPage}
+	if len(items) <= perPage {
+		return renderPage("", p, 0)
+	}
+	cursor, err := paginations.put(p)
+	if err != nil {
+		// the results are still shown, split across messages if needed
+		p.perPage = len(items)
+	}
+	return renderPage(cursor, p, 0)
+}
+
+// renderPage renders a page of results with a summary of which results are shown.
+func renderPage(cursor string, p pagination, page int) []slack.MsgOption {
+	start := page * p.perPage
+	end := min(start+p.perPage, len(p.items))
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, p.title, false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, strings.Join(p.items[start:end], "\n"), false, false), nil, nil),
+	}
+	if len(p.items) > end {
+		blocks = append(blocks,
+			slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("showing %d-%d of %d results", start+1, end, len(p.items)), false, false)),
+			slack.NewActionBlock("", slack.NewButtonBlockElement(ShowMoreActionID, fmt.Sprintf("%s:%d", cursor, page+1),
+				slack.NewTextBlockObject(slack.PlainTextType, "Show more", false, false))))
+	} else if page > 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("showing %d-%d of %d results", start+1, end, len(p.items)), false, false)))
+	}
+	return []slack.MsgOption{
+		slack.MsgOptionText(p.title, false),
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionDisableLinkUnfurl(),
+	}
+}
+
+// ShowMoreActionAttributes replaces paginated results with their next page.
+var ShowMoreActionAttributes = data.ActionAttributes{
+	ActionID:           ShowMoreActionID,
+	AllowNonSplatUsers: true,
+	ReplaceOriginal:    true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		cursor, pageValue, found := strings.Cut(action.Value, ":")
+		page, err := strconv.Atoi(pageValue)
+		if !found || err != nil {
+			return nil, fmt.Errorf("invalid page %q", action.Value)
+		}
+		p, ok := paginations.get(cursor)
+		if !ok || page < 0 || page >= p.pages() {
+			return util.StringToBlock(paginationExpired, false), nil
+		}
+		return renderPage(cursor, p, page), nil
+	},
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/fakeslack"
+)
+
+func TestPaginate(t *testing.T) {
+	defer func() {
+		paginations = newPaginationStore(DEFAULT_PAGINATION_TTL)
+	}()
+
+	var items []string
+	for i := 1; i <= 25; i++ {
+		items = append(items, fmt.Sprintf("job-%d", i))
+	}
+
+	workspace := fakeslack.NewWorkspace(SPLAT_BOT_USER_ID)
+	render := func(response []slack.MsgOption) (string, *slack.ButtonBlockElement) {
+		t.Helper()
+		_, _, err := workspace.PostMessage("C1", response...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		posted := workspace.Posted()
+		msg := posted[len(posted)-1]
+		for _, block := range msg.Blocks.BlockSet {
+			if actions, ok := block.(*slack.ActionBlock); ok {
+				return fakeslack.Render(msg), actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+			}
+		}
+		return fakeslack.Render(msg), nil
+	}
+
+	rendered, button := render(paginate("*jobs*", items, 10))
+	if !strings.Contains(rendered, "job-10") || strings.Contains(rendered, "job-11") || !strings.Contains(rendered, "showing 1-10 of 25 results") {
+		t.Errorf("unexpected first page:\n%s", rendered)
+	}
+	if button == nil || button.ActionID != ShowMoreActionID {
+		t.Fatalf("expected a show more button:\n%s", rendered)
+	}
+
+	showMore := func(value string) []slack.MsgOption {
+		t.Helper()
+		response, err := ShowMoreActionAttributes.Callback(context.TODO(), workspace, &slack.InteractionCallback{}, &slack.BlockAction{ActionID: ShowMoreActionID, Value: value})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return response
+	}
+	rendered, button = render(showMore(button.Value))
+	if !strings.Contains(rendered, "job-20") || !strings.Contains(rendered, "showing 11-20 of 25 results") || button == nil {
+		t.Errorf("unexpected second page:\n%s", rendered)
+	}
+	rendered, button = render(showMore(button.Value))
+	if !strings.Contains(rendered, "job-25") || !strings.Contains(rendered, "showing 21-25 of 25 results") || button != nil {
+		t.Errorf("expected the last page without a show more button:\n%s", rendered)
+	}
+
+	rendered, _ = render(showMore("unknown:1"))
+	if rendered != paginationExpired {
+		t.Errorf("expected expired results to be reported, got %q", rendered)
+	}
+
+	if rendered, button = render(paginate("*jobs*", items[:3], 10)); button != nil || strings.Contains(rendered, "showing") {
+		t.Errorf("expected a single page without pagination:\n%s", rendered)
+	}
+}
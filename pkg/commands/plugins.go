@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+)
+
+// CorePlugin provides help, the Jira and Prow commands and the audit log.
+type CorePlugin struct{}
+
+// NewCorePlugin returns the plugin which provides the core commands.
+func NewCorePlugin() *CorePlugin {
+	return &CorePlugin{}
+}
+
+func (p *CorePlugin) Name() string {
+	return "core"
+}
+
+func (p *CorePlugin) Configure(cfg *config.Config) error {
+	docQueryURL = cfg.Docs.QueryURL
+	return nil
+}
+
+func (p *CorePlugin) Start(ctx context.Context) error {
+	return nil
+}
+
+func (p *CorePlugin) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (p *CorePlugin) Commands() []data.Attributes {
+	return []data.Attributes{
+		CreateAttributes,
+		HelpAttributes,
+		ProwAttributes,
+		ProwGraphAttributes,
+		CreateJiraWithThreadAttributes,
+		AuditAttributes,
+	}
+}
+
+func (p *CorePlugin) Actions() []data.ActionAttributes {
+	return []data.ActionAttributes{
+		CloseActionAttributes,
+		ShowMoreActionAttributes,
+	}
+}
+
+// GitHubPlugin provides the pull request commands. The commands are only provided when the private key of the
+// GitHub app is present.
+type GitHubPlugin struct {
+	keyPresent bool
+}
+
+// NewGitHubPlugin returns the plugin which provides the pull request commands.
+func NewGitHubPlugin() *GitHubPlugin {
+	return &GitHubPlugin{}
+}
+
+func (p *GitHubPlugin) Name() string {
+	return "github"
+}
+
+func (p *GitHubPlugin) Configure(cfg *config.Config) error {
+	githubConfig = cfg.GitHub
+	_, err := os.ReadFile(cfg.GitHub.KeyPath)
+	if err != nil {
+		log.Printf("error reading file %s: %v", cfg.GitHub.KeyPath, err)
+		log.Infof("Skipping adding of pull request commands.")
+	}
+	p.keyPresent = err == nil
+	return nil
+}
+
+func (p *GitHubPlugin) Start(ctx context.Context) error {
+	return nil
+}
+
+func (p *GitHubPlugin) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (p *GitHubPlugin) Commands() []data.Attributes {
+	if !p.keyPresent {
+		return nil
+	}
+	return []data.Attributes{
+		PullRequestAttributes,
+		PullRequestAssignedAttributes,
+	}
+}
+
+// CIPlugin provides the lease and pool commands and runs the controllers which manage leases and pools. It
+// requires a cluster.
+type CIPlugin struct {
+	accounts       config.AccountsConfig
+	leaderElection config.LeaderElectionConfig
+	stopped        <-chan struct{}
+}
+
+// NewCIPlugin returns the plugin which provides the lease and pool commands.
+func NewCIPlugin() *CIPlugin {
+	return &CIPlugin{}
+}
+
+func (p *CIPlugin) Name() string {
+	return "ci"
+}
+
+func (p *CIPlugin) Configure(cfg *config.Config) error {
+	p.accounts = cfg.Accounts
+	p.leaderElection = cfg.LeaderElection
+	return nil
+}
+
+func (p *CIPlugin) Start(ctx context.Context) error {
+	stopped, err := controllers.Start(ctx, p.accounts, p.leaderElection)
+	if err != nil {
+		return err
+	}
+	p.stopped = stopped
+	return nil
+}
+
+// Stop waits for the controllers to stop. The controllers stop once the context passed to Start is done.
+func (p *CIPlugin) Stop(ctx context.Context) error {
+	if p.stopped == nil {
+		return nil
+	}
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *CIPlugin) Commands() []data.Attributes {
+	return []data.Attributes{
+		LeasesAttributes,
+		PoolsAttributes,
+	}
+}
+
+func (p *CIPlugin) Actions() []data.ActionAttributes {
+	return []data.ActionAttributes{
+		LeaseRenewActionAttributes,
+		LeaseReleaseActionAttributes,
+		PoolCordonActionAttributes,
+		PoolUncordonActionAttributes,
+	}
+}
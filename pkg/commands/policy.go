@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// policyReloadDelay the time the watcher waits for changes to the policy file to settle before it's reloaded.
+const policyReloadDelay = 500 * time.Millisecond
+
+var (
+	policyMu      sync.RWMutex
+	commandPolicy *data.CommandPolicy
+	groupResolver GroupMembershipResolver
+)
+
+// GroupMembershipResolver reports whether a user is a member of a Slack user group.
+type GroupMembershipResolver interface {
+	IsMember(groupID, userID string) (bool, error)
+}
+
+// SetGroupMembershipResolver sets the resolver used to evaluate the groups in a policy rule.
+func SetGroupMembershipResolver(resolver GroupMembershipResolver) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	groupResolver = resolver
+}
+
+// permissionError is returned when a user is not allowed to invoke a command. The message is
+// intended to be shown to the user.
+type permissionError struct {
+	command     string
+	requirement string
+}
+
+func (p *permissionError) Error() string {
+	return fmt.Sprintf("you are not allowed to run `%s`. %s", p.command, p.requirement)
+}
+
+func readPolicy(path string) (*data.CommandPolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy file %s: %v", path, err)
+	}
+
+	policy := &data.CommandPolicy{}
+	err = yaml.Unmarshal(content, policy)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling policy file %s: %v", path, err)
+	}
+
+	for idx, rule := range policy.Rules {
+		if len(strings.Fields(rule.Command)) == 0 {
+			return nil, fmt.Errorf("rule %d in policy file %s has no command", idx, path)
+		}
+		if len(rule.Users)+len(rule.Groups)+len(rule.Channels) == 0 {
+			return nil, fmt.Errorf("rule for %s in policy file %s allows nobody", rule.Command, path)
+		}
+	}
+	return policy, nil
+}
+
+// loadPolicy reads the policy file and replaces the current policy. If the policy can not be read, the
+// current policy is retained.
+func loadPolicy(path string) error {
+	policy, err := readPolicy(path)
+	if err != nil {
+		return err
+	}
+	policyMu.Lock()
+	commandPolicy = policy
+	policyMu.Unlock()
+	log.Infof("loaded %d command policy rules from %s", len(policy.Rules), path)
+	return nil
+}
+
+// watchPolicy reloads the policy when the policy file changes until ctx is done. The directory containing the file
+// is watched so that ConfigMap updates, which swap a symlink, are detected.
+func watchPolicy(ctx context.Context, path string) error {
+	err := util.WatchFiles(ctx, "policy", policyReloadDelay, func(watcher *fsnotify.Watcher) error {
+		return watcher.Add(filepath.Dir(path))
+	}, func() {
+		if err := loadPolicy(path); err != nil {
+			log.Warnf("keeping previous command policy: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("unable to watch policy file %s: %v", path, err)
+	}
+	return nil
+}
+
+// getPolicyRule returns the most specific rule which applies to the command being invoked. nil is returned
+// if no rule applies.
+func getPolicyRule(attribute data.Attributes, args []string) *data.PolicyRule {
+	// rules only apply to discrete commands, not catch-alls such as knowledge
+	if len(attribute.Commands) == 0 {
+		return nil
+	}
+
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	if commandPolicy == nil {
+		return nil
+	}
+
+	var best *data.PolicyRule
+	bestLength := 0
+	for idx, rule := range commandPolicy.Rules {
+		path := strings.Fields(rule.Command)
+		if len(path) > len(args) || len(path) <= bestLength {
+			continue
+		}
+		matches := true
+		for index, token := range path {
+			if token != args[index] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			best = &commandPolicy.Rules[idx]
+			bestLength = len(path)
+		}
+	}
+	return best
+}
+
+func isGroupMember(groups []string, user string) bool {
+	policyMu.RLock()
+	resolver := groupResolver
+	policyMu.RUnlock()
+
+	if resolver == nil {
+		if len(groups) > 0 {
+			log.Warnf("no group membership resolver is configured, ignoring groups %v", groups)
+		}
+		return false
+	}
+	for _, group := range groups {
+		member, err := resolver.IsMember(group, user)
+		if err != nil {
+			log.Warnf("unable to resolve membership of group %s: %v", group, err)
+			continue
+		}
+		if member {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func authorizeRule(rule *data.PolicyRule, evt *slackevents.MessageEvent) error {
+	if contains(rule.Users, evt.User) || contains(rule.Channels, evt.Channel) || isGroupMember(rule.Groups, evt.User) {
+		return nil
+	}
+
+	return &permissionError{
+		command:     rule.Command,
+		requirement: fmt.Sprintf("it requires %s.", ruleRequirement(rule)),
+	}
+}
+
+// ruleRequirement describes what a rule requires of a user. e.g. `membership in @splat-team or running it in #splat`
+func ruleRequirement(rule *data.PolicyRule) string {
+	var requirements []string
+	if len(rule.Groups) > 0 {
+		var groups []string
+		for _, group := range rule.Groups {
+			groups = append(groups, util.GroupMention(group))
+		}
+		requirements = append(requirements, fmt.Sprintf("membership in %s", strings.Join(groups, ", ")))
+	}
+	if len(rule.Channels) > 0 {
+		var channels []string
+		for _, channel := range rule.Channels {
+			channels = append(channels, fmt.Sprintf("<#%s>", channel))
+		}
+		requirements = append(requirements, fmt.Sprintf("running it in %s", strings.Join(channels, ", ")))
+	}
+	if len(rule.Users) > 0 {
+		requirements = append(requirements, "being explicitly listed in the policy")
+	}
+	return strings.Join(requirements, " or ")
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+const testPolicy = `rules:
+- command: "ci pools"
+  users: ["U1", "U2"]
+- command: "ci pools cordon"
+  users: ["U1"]
+  channels: ["C1"]
+`
+
+type staticResolver map[string][]string
+
+func (s staticResolver) IsMember(groupID, userID string) (bool, error) {
+	return contains(s[groupID], userID), nil
+}
+
+func TestPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	if err := loadPolicy(path); err != nil {
+		t.Fatalf("unable to load policy: %v", err)
+	}
+	defer func() {
+		commandPolicy = nil
+	}()
+
+	testCases := []struct {
+		name    string
+		args    []string
+		user    string
+		channel string
+		allowed bool
+	}{
+		{name: "listed user", args: []string{"ci", "pools", "list"}, user: "U2", allowed: true},
+		{name: "unlisted user", args: []string{"ci", "pools", "list"}, user: "U3", allowed: false},
+		{name: "most specific rule applies", args: []string{"ci", "pools", "cordon", "pool-1"}, user: "U2", allowed: false},
+		{name: "allowed channel", args: []string{"ci", "pools", "cordon", "pool-1"}, user: "U3", channel: "C1", allowed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := isAllowedUser(PoolsAttributes, tc.args, &slackevents.MessageEvent{User: tc.user, Channel: tc.channel})
+			if tc.allowed && err != nil {
+				t.Errorf("expected user to be allowed: %v", err)
+			} else if !tc.allowed && err == nil {
+				t.Errorf("expected user to be denied")
+			}
+		})
+	}
+}
+
+func TestPolicyGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n- command: \"jira create\"\n  groups: [\"S1\"]\n"), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	if err := loadPolicy(path); err != nil {
+		t.Fatalf("unable to load policy: %v", err)
+	}
+	SetGroupMembershipResolver(staticResolver{"S1": {"U1"}})
+	defer func() {
+		commandPolicy = nil
+		SetGroupMembershipResolver(nil)
+	}()
+
+	args := []string{"jira", "create", "summary"}
+	if err := isAllowedUser(CreateAttributes, args, &slackevents.MessageEvent{User: "U1"}); err != nil {
+		t.Errorf("expected group member to be allowed: %v", err)
+	}
+	if err := isAllowedUser(CreateAttributes, args, &slackevents.MessageEvent{User: "U2"}); err == nil {
+		t.Errorf("expected non-member to be denied")
+	}
+}
+
+func TestInvalidPolicyKeepsPrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	if err := loadPolicy(path); err != nil {
+		t.Fatalf("unable to load policy: %v", err)
+	}
+	defer func() {
+		commandPolicy = nil
+	}()
+
+	if err := os.WriteFile(path, []byte("rules:\n- command: \"\"\n"), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	if err := loadPolicy(path); err == nil {
+		t.Fatalf("expected invalid policy to fail to load")
+	}
+	if getPolicyRule(PoolsAttributes, []string{"ci", "pools", "list"}) == nil {
+		t.Errorf("expected previous policy to be retained")
+	}
+}
+
+func TestWatchPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	if err := loadPolicy(path); err != nil {
+		t.Fatalf("unable to load policy: %v", err)
+	}
+	defer func() {
+		commandPolicy = nil
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := watchPolicy(ctx, path); err != nil {
+		t.Fatalf("unable to watch policy: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("rules:\n- command: \"ci\"\n  users: [\"U3\"]\n"), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for getPolicyRule(PoolsAttributes, []string{"ci", "pools", "list"}).Command != "ci" {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the policy to be reloaded")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// the policy isn't reloaded once the watcher is stopped
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(path, []byte(testPolicy), 0600); err != nil {
+		t.Fatalf("unable to write policy: %v", err)
+	}
+	time.Sleep(2 * policyReloadDelay)
+	if rule := getPolicyRule(PoolsAttributes, []string{"ci", "pools", "list"}); rule.Command != "ci" {
+		t.Errorf("expected the policy not to be reloaded after the watcher stopped, got rule %s", rule.Command)
+	}
+}
@@ -11,57 +11,65 @@ import (
 	"github.com/slack-go/slack/slackevents"
 )
 
-func init() {
-	AddCommand(PoolsAttributes)
+var PoolCordonActionAttributes = data.ActionAttributes{
+	ActionID: controllers.PoolCordonActionID,
+	Commands: []string{"ci", "pools", "cordon"},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		err := controllers.SetPoolSchedulable(ctx, action.Value, false)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
+		}
+		return util.StringToBlock(fmt.Sprintf("pool %s is cordoned", action.Value), false), nil
+	},
+}
+
+var PoolUncordonActionAttributes = data.ActionAttributes{
+	ActionID: controllers.PoolUncordonActionID,
+	Commands: []string{"ci", "pools", "uncordon"},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		err := controllers.SetPoolSchedulable(ctx, action.Value, true)
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to uncordon pool: %w", err)
+		}
+		return util.StringToBlock(fmt.Sprintf("pool %s is uncordoned", action.Value), false), nil
+	},
 }
 
 var PoolsAttributes = data.Attributes{
 	Commands:       []string{"ci", "pools"},
+	Mutating:       true,
 	RequireMention: true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		result := ""
-		var err error
-		if len(args) > 2 {
-			switch args[2] {
-			case "uncordon":
-				if len(args) < 4 {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("requires the name or index of the pool")
-				}
-				err = controllers.SetPoolSchedulable(ctx, args[3], true)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to uncordon pool: %w", err)
-				}
-				result = "pool is uncordoned"
-			case "cordon":
-				if len(args) < 4 {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("requires the name or index of the pool")
-				}
-				err = controllers.SetPoolSchedulable(ctx, args[3], false)
-				if err != nil {
-					return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to set pool unschedulable: %w", err)
-				}
-				result = "pool is cordoned"
-			case "status":
-				fallthrough
-			case "list":
-				fallthrough
-			default:
-				result, err := controllers.GetPoolStatus()
-				if err != nil {
-					return nil, fmt.Errorf("failed to fetch pool status: %w", err)
-				}
-				return []slack.MsgOption{result}, nil
+		parsed := data.GetParsedArgs(ctx)
+		pool := parsed.String("pool")
+		switch action := parsed.String("action"); action {
+		case "uncordon", "cordon":
+			if len(pool) == 0 {
+				return util.StringToBlock(fmt.Sprintf("`ci pools %s` requires the name or index of the pool", action), false),
+					fmt.Errorf("requires the name or index of the pool")
+			}
+			err := controllers.SetPoolSchedulable(ctx, pool, action == "uncordon")
+			if err != nil {
+				return util.StringToBlock(err.Error(), false), fmt.Errorf("failed to %s pool: %w", action, err)
+			}
+			return util.StringToBlock(fmt.Sprintf("pool is %sed", action), false), nil
+		default:
+			result, err := controllers.GetPoolStatus()
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch pool status: %w", err)
 			}
+			return []slack.MsgOption{result}, nil
 		}
-
-		return util.StringToBlock(result, false), nil
 	},
-	RequiredArgs: 0,
-	HelpMarkdown: "interact with vSphere CI pools: `ci pools list|uncordoned|cordoned <pool name>`",
+	Description: "interact with vSphere CI pools",
+	Arguments: []data.Argument{
+		{Name: "action", Enum: []string{"list", "status", "cordon", "uncordon"}, Default: "list"},
+		{Name: "pool", Help: "name or index of the pool to cordon or uncordon"},
+	},
 	ShouldMatch: []string{
 		"ci pools list",
-		"ci pools schedulable pool-1",
-		"ci pools unschedulable pool-1",
+		"ci pools cordon pool-1",
+		"ci pools uncordon pool-1",
 	},
 	ShouldntMatch: []string{
 		"jira create-with-summary PROJECT bug",
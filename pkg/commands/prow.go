@@ -26,6 +26,8 @@ const (
 	prowJobsUrl      = "https://prow.ci.openshift.org/prowjobs.js?omit=annotations,decoration_config,pod_spec"
 	retrievalTimeout = time.Hour + time.Minute*30
 	retrieveEvery    = time.Minute * 29
+	// prowResultsPerPage the number of jobs shown at a time
+	prowResultsPerPage = 10
 )
 
 var (
@@ -41,15 +43,18 @@ var ProwGraphAttributes = data.Attributes{
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
 		startProwRetrievalTimers()
 
-		results, err := createProwGraph(args[2])
+		results, err := createProwGraph(data.GetParsedArgs(ctx).String("platform"))
 		if err != nil {
 			return nil, err
 		}
 
 		return util.StringToBlock(results, false), nil
 	},
-	RequiredArgs: 3,
-	HelpMarkdown: "retrieve prow results: `prow graph [platform]`",
+	Description: "graph prow results",
+	Async:       true,
+	Arguments: []data.Argument{
+		{Name: "platform", Required: true, Help: "platform of the jobs. e.g. vsphere"},
+	},
 	ShouldMatch: []string{
 		"prow graph vsphere",
 	},
@@ -65,15 +70,32 @@ var ProwAttributes = data.Attributes{
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
 		startProwRetrievalTimers()
 
-		results, err := queryProwResults(args[2], args[3], prowv1.ProwJobState(args[4]))
+		parsed := data.GetParsedArgs(ctx)
+		platform, version, state := parsed.String("platform"), parsed.String("version"), parsed.String("state")
+		results, err := queryProwResults(platform, version, prowv1.ProwJobState(state))
 		if err != nil {
 			return nil, err
 		}
+		if len(results) == 0 {
+			return util.StringToBlock(fmt.Sprintf("no %s jobs were found for %s %s.", state, platform, version), false), nil
+		}
 
-		return util.StringToBlock(results, false), nil
+		return paginate(fmt.Sprintf("*%s jobs for %s %s*", state, platform, version), results, prowResultsPerPage), nil
+	},
+	Description: "retrieve prow results",
+	Async:       true,
+	Arguments: []data.Argument{
+		{Name: "platform", Required: true, Help: "platform of the jobs. e.g. vsphere"},
+		{Name: "version", Required: true, Help: "OpenShift version of the jobs. e.g. 4.16"},
+		{Name: "state", Required: true, Enum: []string{
+			string(prowv1.TriggeredState),
+			string(prowv1.PendingState),
+			string(prowv1.SuccessState),
+			string(prowv1.FailureState),
+			string(prowv1.AbortedState),
+			string(prowv1.ErrorState),
+		}},
 	},
-	RequiredArgs: 5,
-	HelpMarkdown: "retrieve prow results: `prow results [platform] [version] [state]`",
 	ShouldMatch: []string{
 		"prow results vsphere 4.16 success",
 	},
@@ -228,32 +250,19 @@ func createProwGraph(platform string) (string, error) {
 	mu.Unlock()
 	return resultsBuilder.String(), nil
 }
-func queryProwResults(platform, version string, prowJobState prowv1.ProwJobState) (string, error) {
-	var resultsBuilder strings.Builder
-	numToRetrieve := 10
+
+// queryProwResults returns links to the jobs which match the platform, version and state.
+func queryProwResults(platform, version string, prowJobState prowv1.ProwJobState) ([]string, error) {
+	var results []string
 
 	mu.Lock()
 	if prowJobList != nil {
-		periodicJobs = make([]prowv1.ProwJob, 0)
-
 		for _, pj := range prowJobList.Items {
 			if !removeJob(platform, version, &prowJobState, pj) {
-				periodicJobs = append(periodicJobs, pj)
+				results = append(results, fmt.Sprintf("<%s|%s>", pj.Status.URL, pj.Spec.Job))
 			}
 		}
-
-		periodicJobLength := len(periodicJobs)
-		if periodicJobLength > numToRetrieve {
-			periodicJobLength = numToRetrieve
-		}
-
-		periodicJobs = periodicJobs[0:periodicJobLength:periodicJobLength]
-
-		for _, j := range periodicJobs {
-			fmt.Fprintf(&resultsBuilder, "<%s|%s>\n", j.Status.URL, j.Spec.Job)
-		}
 	}
-	periodicJobs = make([]prowv1.ProwJob, 0)
 	mu.Unlock()
-	return resultsBuilder.String(), nil
+	return results, nil
 }
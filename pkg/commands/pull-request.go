@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -16,6 +15,7 @@ import (
 	"github.com/beatlabs/github-auth/key"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 	splathub "github.com/openshift-splat-team/splat-bot/pkg/github"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	githubql "github.com/shurcooL/githubv4"
@@ -26,12 +26,11 @@ import (
 )
 
 const (
-	githubAppId        = "858938"
 	JSON_DEBUG_ENABLED = false // TODO: make configurable in future
 )
 
 var (
-	githubID string
+	githubConfig = config.Default().GitHub
 
 	boldStyle = slack.RichTextSectionTextStyle{Bold: true}
 )
@@ -40,17 +39,22 @@ var PullRequestAttributes = data.Attributes{
 	Commands:       []string{"pull-requests"},
 	RequireMention: true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		prList, err := fetchPullRequests(args)
+		login := data.GetParsedArgs(ctx).String("user")
+		prList, err := fetchPullRequests(ctx, ConstructSearchQuery(false, login))
 
 		if err != nil {
 			return nil, fmt.Errorf("user not allowed: %v", err)
 		}
 
-		return generateOutput(args, prList)
+		return generateOutput(login, prList)
+	},
+	AllowNonSplatUsers: true,
+	HelpArea:           "github",
+	Async:              true,
+	Description:        "retrieve list of pull requests open for the specified user",
+	Arguments: []data.Argument{
+		{Name: "user", Required: true, Help: "GitHub user"},
 	},
-	AllowNonSplatUsers:  true,
-	RequiredArgs:        2,
-	HelpMarkdown:        "retrieve list of pull requests open for the specified user: `pull-requests [user]`",
 	ResponseIsEphemeral: true,
 	RespondInChannel:    true,
 	ShouldMatch: []string{
@@ -66,17 +70,22 @@ var PullRequestAssignedAttributes = data.Attributes{
 	Commands:       []string{"pull-requests-assigned"},
 	RequireMention: true,
 	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
-		prList, err := fetchPullRequests(args)
+		login := data.GetParsedArgs(ctx).String("user")
+		prList, err := fetchPullRequests(ctx, ConstructSearchQuery(true, login))
 
 		if err != nil {
 			return nil, fmt.Errorf("user not allowed: %v", err)
 		}
 
-		return generateOutput(args, prList)
+		return generateOutput(login, prList)
+	},
+	AllowNonSplatUsers: true,
+	HelpArea:           "github",
+	Async:              true,
+	Description:        "retrieve list of pull requests opened that are assigned to the specified user",
+	Arguments: []data.Argument{
+		{Name: "user", Required: true, Help: "GitHub user"},
 	},
-	AllowNonSplatUsers:  true,
-	RequiredArgs:        2,
-	HelpMarkdown:        "retrieve list of pull requests opened that are assigned to the specified user: `pull-requests-assigned [user]`",
 	ResponseIsEphemeral: true,
 	RespondInChannel:    true,
 	ShouldMatch: []string{
@@ -88,49 +97,19 @@ var PullRequestAssignedAttributes = data.Attributes{
 	},
 }
 
-func getGithubKeyPath() string {
-	keyPath := os.Getenv("GITHUB_KEY_PATH")
-	if keyPath == "" {
-		keyPath = "data/private.key"
-	}
-	return keyPath
-}
-
-func init() {
-	keyPath := getGithubKeyPath()
-	_, err := os.ReadFile(keyPath)
-	if err != nil {
-		log.Printf("error reading file %s: %v", keyPath, err)
-	}
-	// If key not found, disable command and log missing file.
-	if err != nil {
-		log.Debugf("error loading knowledge entries: %v", err)
-		log.Infof("Skipping adding of knowledge-based actions.")
-		return
-	}
-	AddCommand(PullRequestAttributes)
-	AddCommand(PullRequestAssignedAttributes)
-}
-
-func generateOutput(args []string, prList []prstatus.PullRequest) ([]slack.MsgOption, error) {
+func generateOutput(login string, prList []prstatus.PullRequest) ([]slack.MsgOption, error) {
 
 	var messageBlocks []slack.Block
-	truncated := false
 	log.Printf("Attempting to creating %v PR entries.", len(prList))
 	//var prResultsBuffer strings.Builder
 	if len(prList) == 0 {
 		// This means no Pull Requests were found.  Create generic message to put above close section.
-		notFoundLabel := slack.NewRichTextSectionTextElement(fmt.Sprintf("No pull requests were found for user %v", args[1]), nil)
+		notFoundLabel := slack.NewRichTextSectionTextElement(fmt.Sprintf("No pull requests were found for user %v", login), nil)
 		notFoundSection := slack.NewRichTextSection(notFoundLabel)
 		notFoundBlock := slack.NewRichTextBlock("", notFoundSection)
 		messageBlocks = append(messageBlocks, notFoundBlock)
 	} else {
 		for index, pr := range prList {
-			if len(messageBlocks)+3+3 > 50 {
-				log.Printf("Due to number of blocks, stopping at index: %d", index)
-				truncated = true
-				break
-			}
 			// Generate divider after first PR
 			if index > 0 {
 				divider := slack.NewDividerBlock()
@@ -185,17 +164,12 @@ func generateOutput(args []string, prList []prstatus.PullRequest) ([]slack.MsgOp
 	divider := slack.NewDividerBlock()
 	messageBlocks = append(messageBlocks, divider)
 
-	if truncated {
-		// TODO: Need to add output to notify user of truncation
-		log.Print("Detected truncated results.")
-	}
-
 	lineReturn := slack.NewRichTextSectionTextElement("\n", nil)
 	lineReturnSection := slack.NewRichTextSection(lineReturn)
 	messageBlocks = append(messageBlocks, slack.NewRichTextBlock("", lineReturnSection))
 
 	closeText := slack.NewTextBlockObject("plain_text", "Close", true, false)
-	closeButton := slack.NewButtonBlockElement("", "", closeText)
+	closeButton := slack.NewButtonBlockElement(CloseActionID, "", closeText)
 	closeActionBlock := slack.NewActionBlock("", closeButton)
 	messageBlocks = append(messageBlocks, closeActionBlock)
 
@@ -214,6 +188,8 @@ func generateOutput(args []string, prList []prstatus.PullRequest) ([]slack.MsgOp
 		}
 	}
 
+	// responses with more blocks than fit in a message are split when they're posted, each message ending with the
+	// Close button
 	return []slack.MsgOption{
 		slack.MsgOptionBlocks(messageBlocks...),
 	}, nil
@@ -243,10 +219,10 @@ func generatePrURL(pr prstatus.PullRequest) string {
 }
 
 func getGithubToken() (string, error) {
-	githubID = "858938"
+	githubID := githubConfig.AppID
 
 	// load from a file
-	keyFile, err := key.FromFile(getGithubKeyPath())
+	keyFile, err := key.FromFile(githubConfig.KeyPath)
 	if err != nil {
 		log.Debugf("Error: %v\n", err)
 	}
@@ -288,7 +264,7 @@ func getGithubToken() (string, error) {
 
 	// Get Access Token
 	var request *http.Request
-	request, err = http.NewRequest("POST", "https://api.github.com/app/installations/48639702/access_tokens", bytes.NewBuffer(nil))
+	request, err = http.NewRequest("POST", fmt.Sprintf("https://api.github.com/app/installations/%s/access_tokens", githubConfig.InstallationID), bytes.NewBuffer(nil))
 	if err != nil {
 		return "", err
 	}
@@ -313,10 +289,10 @@ func getGithubToken() (string, error) {
 	return gitToken, nil
 }
 
-func ConstructSearchQuery(args []string) string {
+// ConstructSearchQuery returns the query for the open pull requests assigned to login, or authored by login.
+func ConstructSearchQuery(assigned bool, login string) string {
 	var tokens []string
-	login := args[1]
-	if args[0] == "pull-requests-assigned" {
+	if assigned {
 		tokens = []string{"is:pr", "state:open", "assignee:" + login}
 	} else {
 		tokens = []string{"is:pr", "state:open", "author:" + login}
@@ -361,7 +337,7 @@ func QueryPullRequests(ctx context.Context, ghc GithubQuerier, query string) ([]
 	return prs, nil
 }
 
-func fetchPullRequests(args []string) ([]prstatus.PullRequest, error) {
+func fetchPullRequests(ctx context.Context, query string) ([]prstatus.PullRequest, error) {
 	var prList []prstatus.PullRequest
 
 	gitToken, err := getGithubToken()
@@ -374,8 +350,8 @@ func fetchPullRequests(args []string) ([]prstatus.PullRequest, error) {
 		Host:              "github.com",
 		Endpoint:          splathub.NewStrings(github.DefaultAPIEndpoint),
 		GraphqlEndpoint:   github.DefaultGraphQLEndpoint,
-		AppID:             githubAppId,
-		AppPrivateKeyPath: "data/private.key",
+		AppID:             githubConfig.AppID,
+		AppPrivateKeyPath: githubConfig.KeyPath,
 	}
 
 	// Create github client
@@ -387,8 +363,7 @@ func fetchPullRequests(args []string) ([]prstatus.PullRequest, error) {
 		log.Debugf("Error creating github client: %v\n", err)
 		return nil, err
 	}
-	query := ConstructSearchQuery(args)
-	prList, err = QueryPullRequests(context.TODO(), githubClient, query)
+	prList, err = QueryPullRequests(ctx, githubClient, query)
 	if err != nil {
 		log.Debugf("Failed to get PRs: %v\n", err)
 		return nil, err
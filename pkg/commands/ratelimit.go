@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"golang.org/x/time/rate"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+// maxIdleLimiters the number of limiters kept before idle limiters are pruned.
+const maxIdleLimiters = 10000
+
+var (
+	limiters                = newRateLimiter()
+	defaultUserRateLimit    config.RateLimit
+	defaultChannelRateLimit config.RateLimit
+)
+
+type keyedLimiter struct {
+	limiter  *rate.Limiter
+	limit    config.RateLimit
+	lastUsed time.Time
+}
+
+// rateLimiter is a set of token buckets keyed by command and user or channel.
+type rateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*keyedLimiter
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limiters: map[string]*keyedLimiter{},
+	}
+}
+
+// reserve takes a token from the bucket for key. nil is returned if the bucket is empty. The token is returned to
+// the bucket if the reservation is cancelled.
+func (r *rateLimiter) reserve(key string, limit config.RateLimit) *reservedToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if len(r.limiters) > maxIdleLimiters {
+		// a limiter idle for its period has refilled so it can be dropped
+		for existingKey, existing := range r.limiters {
+			if now.Sub(existing.lastUsed) > existing.limit.Per {
+				delete(r.limiters, existingKey)
+			}
+		}
+	}
+
+	entry, ok := r.limiters[key]
+	if !ok || entry.limit != limit {
+		entry = &keyedLimiter{
+			limiter: rate.NewLimiter(rate.Every(limit.Per/time.Duration(limit.Requests)), limit.Requests),
+			limit:   limit,
+		}
+		r.limiters[key] = entry
+	}
+	entry.lastUsed = now
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+		reservation.CancelAt(now)
+		return nil
+	}
+	return &reservedToken{reservation: reservation, at: now}
+}
+
+// reservedToken is a token taken from a bucket at a time.
+type reservedToken struct {
+	reservation *rate.Reservation
+	at          time.Time
+}
+
+// rateReservation holds the tokens taken for an invocation.
+type rateReservation struct {
+	tokens []*reservedToken
+}
+
+// cancel returns the tokens, so an invocation which doesn't respond isn't counted. A reservation only returns its
+// token when it's cancelled at the time it was taken.
+func (r *rateReservation) cancel() {
+	for _, token := range r.tokens {
+		token.reservation.CancelAt(token.at)
+	}
+}
+
+// rateLimitError is returned when a command is invoked too often.
+type rateLimitError struct {
+	command string
+	scope   string
+	limit   config.RateLimit
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("slow down! `%s` may only be run %d times every %s per %s.", e.command, e.limit.Requests, e.limit.Per, e.scope)
+}
+
+// checkRateLimit takes a token for the user and for the channel. An error is returned if either is exhausted, in
+// which case neither token is taken.
+func checkRateLimit(attribute data.Attributes, msg *slackevents.MessageEvent) error {
+	_, err := reserveRateLimit(attribute, msg)
+	return err
+}
+
+// reserveRateLimit takes a token for the user and for the channel. The tokens may be returned by cancelling the
+// reservation. An error is returned if either is exhausted, in which case neither token is taken.
+func reserveRateLimit(attribute data.Attributes, msg *slackevents.MessageEvent) (*rateReservation, error) {
+	command := describeAttribute(attribute)
+	reserved := &rateReservation{}
+
+	userLimit := attribute.UserRateLimit
+	if !userLimit.IsSet() {
+		userLimit = defaultUserRateLimit
+	}
+	if userLimit.IsSet() {
+		token := limiters.reserve(fmt.Sprintf("%s/user/%s", command, msg.User), userLimit)
+		if token == nil {
+			return nil, &rateLimitError{command: command, scope: "user", limit: userLimit}
+		}
+		reserved.tokens = append(reserved.tokens, token)
+	}
+
+	channelLimit := attribute.ChannelRateLimit
+	if !channelLimit.IsSet() {
+		channelLimit = defaultChannelRateLimit
+	}
+	if channelLimit.IsSet() {
+		token := limiters.reserve(fmt.Sprintf("%s/channel/%s", command, msg.Channel), channelLimit)
+		if token == nil {
+			reserved.cancel()
+			return nil, &rateLimitError{command: command, scope: "channel", limit: channelLimit}
+		}
+		reserved.tokens = append(reserved.tokens, token)
+	}
+	return reserved, nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+func TestCheckRateLimit(t *testing.T) {
+	defer func() {
+		limiters = newRateLimiter()
+	}()
+
+	attribute := data.Attributes{
+		Commands:         []string{"ci", "lease"},
+		UserRateLimit:    config.RateLimit{Requests: 2, Per: time.Hour},
+		ChannelRateLimit: config.RateLimit{Requests: 3, Per: time.Hour},
+	}
+	for i := 0; i < 2; i++ {
+		if err := checkRateLimit(attribute, &slackevents.MessageEvent{User: "U1", Channel: "C1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	err := checkRateLimit(attribute, &slackevents.MessageEvent{User: "U1", Channel: "C1"})
+	if err == nil || !strings.Contains(err.Error(), "2 times every 1h0m0s per user") {
+		t.Errorf("expected the user to be limited: %v", err)
+	}
+
+	// the channel bucket has one token left after the user's requests
+	if err := checkRateLimit(attribute, &slackevents.MessageEvent{User: "U2", Channel: "C1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = checkRateLimit(attribute, &slackevents.MessageEvent{User: "U3", Channel: "C1"})
+	if err == nil || !strings.Contains(err.Error(), "per channel") {
+		t.Errorf("expected the channel to be limited: %v", err)
+	}
+
+	// other channels have their own bucket, and the user's token isn't taken when the channel is limited
+	for i := 0; i < 2; i++ {
+		if err := checkRateLimit(attribute, &slackevents.MessageEvent{User: "U3", Channel: "C2"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// cancelling a reservation returns its tokens
+	reservation, err := reserveRateLimit(attribute, &slackevents.MessageEvent{User: "U4", Channel: "C3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reservation.cancel()
+	for i := 0; i < 2; i++ {
+		if err := checkRateLimit(attribute, &slackevents.MessageEvent{User: "U4", Channel: "C3"}); err != nil {
+			t.Errorf("expected the cancelled tokens to be returned: %v", err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// DEFAULT_RESPONSE_INDEX_TTL how long the bot tracks a response to a message. Edits and deletes of the
+	// message after the TTL are ignored.
+	DEFAULT_RESPONSE_INDEX_TTL = 24 * time.Hour
+
+	messageChanged = "message_changed"
+	messageDeleted = "message_deleted"
+)
+
+var responses = newResponseIndex(DEFAULT_RESPONSE_INDEX_TTL)
+
+// indexedResponse is a reply posted by the bot.
+type indexedResponse struct {
+	channel   string
+	timestamp string
+	// thread the thread the reply is continued in when it doesn't fit in one message
+	thread string
+	// continuations the timestamps of the messages the reply is continued in
+	continuations []string
+	created       time.Time
+}
+
+// responseIndex maps a message, keyed by channel and timestamp, to the bot's reply to it.
+type responseIndex struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	responses map[string]indexedResponse
+}
+
+func newResponseIndex(ttl time.Duration) *responseIndex {
+	return &responseIndex{
+		ttl:       ttl,
+		responses: map[string]indexedResponse{},
+	}
+}
+
+func responseKey(channel, timestamp string) string {
+	return fmt.Sprintf("%s/%s", channel, timestamp)
+}
+
+// put records the reply to a message. Expired replies are pruned.
+func (r *responseIndex) put(channel, timestamp string, response indexedResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for key, existing := range r.responses {
+		if now.Sub(existing.created) > r.ttl {
+			delete(r.responses, key)
+		}
+	}
+	response.created = now
+	r.responses[responseKey(channel, timestamp)] = response
+}
+
+// get returns the reply to a message.
+func (r *responseIndex) get(channel, timestamp string) (indexedResponse, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	response, ok := r.responses[responseKey(channel, timestamp)]
+	if !ok || time.Since(response.created) > r.ttl {
+		return indexedResponse{}, false
+	}
+	return response, true
+}
+
+func (r *responseIndex) remove(channel, timestamp string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.responses, responseKey(channel, timestamp))
+}
+
+// trackResponse records the reply to a message if the command tracks edits.
+func trackResponse(attribute data.Attributes, msg *slackevents.MessageEvent, response indexedResponse) {
+	if !attribute.TrackEdits || attribute.ResponseIsEphemeral || len(response.timestamp) == 0 {
+		return
+	}
+	responses.put(msg.Channel, msg.TimeStamp, response)
+}
+
+// postContinuations posts the messages of a split response after the first one in the thread of the reply. The
+// timestamps of the posted messages are returned.
+func postContinuations(client util.SlackClientInterface, channel, threadTS string, pages [][]slack.MsgOption) ([]string, error) {
+	var continuations []string
+	for _, page := range pages {
+		_, timestamp, err := client.PostMessage(channel, append(page, slack.MsgOptionTS(threadTS))...)
+		if err != nil {
+			return continuations, fmt.Errorf("failed continuing response: %v", err)
+		}
+		continuations = append(continuations, timestamp)
+	}
+	return continuations, nil
+}
+
+// replaceResponse updates a reply with a new response. The messages the previous response was continued in are
+// deleted and the new response is continued in the thread of the reply.
+func replaceResponse(client util.SlackClientInterface, previous indexedResponse, response []slack.MsgOption) (indexedResponse, error) {
+	pages := util.SplitResponse(response)
+	_, _, _, err := client.UpdateMessage(previous.channel, previous.timestamp, pages[0]...)
+	if err != nil {
+		return previous, fmt.Errorf("failed updating response: %v", err)
+	}
+	for _, timestamp := range previous.continuations {
+		if _, _, err := client.DeleteMessage(previous.channel, timestamp); err != nil {
+			log.Warnf("failed deleting continued response: %v", err)
+		}
+	}
+	previous.continuations, err = postContinuations(client, previous.channel, previous.thread, pages[1:])
+	return previous, err
+}
+
+// deleteResponse deletes the bot's reply to a message, if there is one.
+func deleteResponse(client util.SlackClientInterface, channel, timestamp string) error {
+	previous, ok := responses.get(channel, timestamp)
+	if !ok {
+		return nil
+	}
+	responses.remove(channel, timestamp)
+	for _, continuation := range previous.continuations {
+		if _, _, err := client.DeleteMessage(previous.channel, continuation); err != nil {
+			log.Warnf("failed deleting continued response: %v", err)
+		}
+	}
+	_, _, err := client.DeleteMessage(previous.channel, previous.timestamp)
+	if err != nil {
+		return fmt.Errorf("failed deleting response: %v", err)
+	}
+	return nil
+}
+
+// handleMessageDeleted deletes the bot's reply when the message which triggered it is deleted.
+func handleMessageDeleted(client util.SlackClientInterface, msg *slackevents.MessageEvent) error {
+	log.Debugf("message %s deleted in %s", msg.DeletedTimeStamp, msg.Channel)
+	return deleteResponse(client, msg.Channel, msg.DeletedTimeStamp)
+}
+
+// handleMessageChanged re-evaluates an edited message against the commands which track edits. The bot's
+// reply is updated with the new response or deleted if the edited message no longer warrants one. Messages
+// the bot didn't reply to, and changes which don't edit the text, such as unfurls, are ignored.
+func handleMessageChanged(ctx context.Context, client util.SlackClientInterface, msg *slackevents.MessageEvent) error {
+	edited := msg.Message
+	if edited == nil || len(edited.BotID) > 0 {
+		// the bot's own replies are edited when they're updated
+		return nil
+	}
+	if msg.PreviousMessage != nil && msg.PreviousMessage.Text == edited.Text {
+		return nil
+	}
+	previous, ok := responses.get(msg.Channel, edited.TimeStamp)
+	if !ok {
+		return nil
+	}
+	edited.Channel = msg.Channel
+	edited.ChannelType = msg.ChannelType
+	if len(edited.Type) == 0 {
+		edited.Type = msg.Type
+	}
+	log.Debugf("message %s edited in %s:\n%s", edited.TimeStamp, edited.Channel, edited.Text)
+
+	// skipped is set when a command which may answer the edited text can't run now, in which case the reply is kept
+	skipped := false
+	candidates, rejections := collectCandidates(edited, sourceMessage)
+	reportCandidates(edited, candidates, rejections)
+	for _, candidate := range candidates {
+		attribute := candidate.attribute
+		if !attribute.TrackEdits || attribute.ResponseIsEphemeral {
+			continue
+		}
+		if err := isAllowedUser(attribute, candidate.args, edited); err != nil {
+			skipped = true
+			continue
+		}
+
+		reservation, err := reserveRateLimit(attribute, edited)
+		if err != nil {
+			log.Debugf("not responding with %s: %v", describeAttribute(attribute), err)
+			skipped = true
+			continue
+		}
+		if isAsync(attribute, edited, candidate.args) && workers != nil {
+			return replaceAsync(client, attribute, edited, candidate.args, previous, reservation)
+		}
+		response := invokeCommand(ctx, client, attribute, edited, candidate.args)
+		if len(response) == 0 {
+			reservation.cancel()
+			continue
+		}
+		previous, err = replaceResponse(client, previous, response)
+		responses.put(edited.Channel, edited.TimeStamp, previous)
+		return err
+	}
+
+	if skipped {
+		log.Debugf("keeping the reply to %s in %s", edited.TimeStamp, edited.Channel)
+		return nil
+	}
+	return deleteResponse(client, edited.Channel, edited.TimeStamp)
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/fakeslack"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// responseClient records the calls made to maintain the bot's replies.
+type responseClient struct {
+	util.StubInterface
+	posted  int
+	updated int
+	deleted []string
+}
+
+func (c *responseClient) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	c.posted++
+	return channelID, "2000.0001", nil
+}
+
+func (c *responseClient) UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	c.updated++
+	return channelID, timestamp, "", nil
+}
+
+func (c *responseClient) DeleteMessage(channel, messageTimestamp string) (string, string, error) {
+	c.deleted = append(c.deleted, messageTimestamp)
+	return channel, messageTimestamp, nil
+}
+
+func buildSubtypeEvent(msg *slackevents.MessageEvent) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type: "message",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: "message",
+			Data: msg,
+		},
+	}
+}
+
+func TestMessageEdits(t *testing.T) {
+	attributeMu.Lock()
+	saved := attributes
+	attributes = []data.Attributes{}
+	attributeMu.Unlock()
+	defer func() {
+		attributeMu.Lock()
+		attributes = saved
+		attributeMu.Unlock()
+		responses = newResponseIndex(DEFAULT_RESPONSE_INDEX_TTL)
+		deliveries = newDedupCache(DEFAULT_DEDUP_TTL)
+		limiters = newRateLimiter()
+	}()
+
+	AddCommand(data.Attributes{
+		Commands:           []string{"vsphere"},
+		TrackEdits:         true,
+		AllowNonSplatUsers: true,
+		RespondInChannel:   true,
+		UserRateLimit:      config.RateLimit{Requests: 2, Per: time.Hour},
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			return util.StringToBlock("vsphere answer", false), nil
+		},
+	})
+
+	client := &responseClient{}
+	ctx := context.TODO()
+	original := &slackevents.MessageEvent{Type: "message", Channel: "C1", User: "U1", Text: "vsphere question", TimeStamp: "1000.0001"}
+	if err := Handler(ctx, client, buildSubtypeEvent(original)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := responses.get("C1", "1000.0001"); !ok || client.posted != 1 {
+		t.Fatalf("expected the reply to be tracked")
+	}
+
+	previousText := original.Text
+	edit := func(text string) {
+		err := Handler(ctx, client, buildSubtypeEvent(&slackevents.MessageEvent{
+			Type:            "message",
+			SubType:         messageChanged,
+			Channel:         "C1",
+			Message:         &slackevents.MessageEvent{Type: "message", User: "U1", Text: text, TimeStamp: "1000.0001"},
+			PreviousMessage: &slackevents.MessageEvent{Type: "message", User: "U1", Text: previousText, TimeStamp: "1000.0001"},
+		}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		previousText = text
+	}
+
+	// changes which don't edit the text, such as unfurls, are ignored
+	edit("vsphere question")
+	if client.updated != 0 || client.posted != 1 {
+		t.Errorf("expected the reply to be left alone, posted: %d updated: %d", client.posted, client.updated)
+	}
+
+	edit("vsphere question, edited")
+	if client.updated != 1 || client.posted != 1 {
+		t.Errorf("expected the reply to be updated, posted: %d updated: %d", client.posted, client.updated)
+	}
+
+	// the reply is kept when the edited text can't be answered because the user is rate limited
+	edit("vsphere question, edited again")
+	if client.updated != 1 || len(client.deleted) != 0 {
+		t.Errorf("expected the reply to be kept, updated: %d deleted: %v", client.updated, client.deleted)
+	}
+
+	edit("unrelated")
+	if len(client.deleted) != 1 || client.deleted[0] != "2000.0001" {
+		t.Errorf("expected the reply to be deleted: %v", client.deleted)
+	}
+
+	// the bot doesn't reply to old messages which are edited once their reply is gone
+	edit("vsphere again")
+	if client.posted != 1 || client.updated != 1 {
+		t.Errorf("expected no reply to be posted, posted: %d updated: %d", client.posted, client.updated)
+	}
+
+	limiters = newRateLimiter()
+	another := &slackevents.MessageEvent{Type: "message", Channel: "C1", User: "U1", Text: "vsphere question", TimeStamp: "1000.0002"}
+	if err := Handler(ctx, client, buildSubtypeEvent(another)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := Handler(ctx, client, buildSubtypeEvent(&slackevents.MessageEvent{
+		Type:             "message",
+		SubType:          messageDeleted,
+		Channel:          "C1",
+		DeletedTimeStamp: "1000.0002",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.deleted) != 2 {
+		t.Errorf("expected the reply to be deleted with the message: %v", client.deleted)
+	}
+	if _, ok := responses.get("C1", "1000.0002"); ok {
+		t.Errorf("expected the reply to no longer be tracked")
+	}
+}
+
+func TestSplitResponses(t *testing.T) {
+	attributeMu.Lock()
+	saved := attributes
+	attributes = []data.Attributes{}
+	attributeMu.Unlock()
+	defer func() {
+		attributeMu.Lock()
+		attributes = saved
+		attributeMu.Unlock()
+		responses = newResponseIndex(DEFAULT_RESPONSE_INDEX_TTL)
+	}()
+
+	AddCommand(data.Attributes{
+		Commands:           []string{"results"},
+		TrackEdits:         true,
+		AllowNonSplatUsers: true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			count := 1
+			if len(args) > 1 && args[1] == "all" {
+				count = 120
+			}
+			var blocks []slack.Block
+			for i := 0; i < count; i++ {
+				blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("result %d", i), false, false), nil, nil))
+			}
+			return []slack.MsgOption{slack.MsgOptionBlocks(blocks...)}, nil
+		},
+	})
+
+	workspace := fakeslack.NewWorkspace(SPLAT_BOT_USER_ID)
+	ctx := context.TODO()
+	evt := workspace.UserMessage("C1", "U1", "results all", "")
+	original := evt.InnerEvent.Data.(*slackevents.MessageEvent)
+	if err := Handler(ctx, workspace, evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	posted := workspace.Posted()
+	if len(posted) != 3 {
+		t.Fatalf("expected 120 blocks to be posted in 3 messages, got %d", len(posted))
+	}
+	for _, msg := range posted {
+		if msg.ThreadTimestamp != original.TimeStamp {
+			t.Errorf("expected the response to be continued in the thread of the message: %+v", msg.Msg)
+		}
+	}
+
+	err := Handler(ctx, workspace, buildSubtypeEvent(&slackevents.MessageEvent{
+		Type:    "message",
+		SubType: messageChanged,
+		Channel: "C1",
+		Message: &slackevents.MessageEvent{Type: "message", User: "U1", Text: "results", TimeStamp: original.TimeStamp},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	posted = workspace.Posted()
+	if posted[0].Deleted || fakeslack.Render(posted[0]) != "result 0" || !posted[1].Deleted || !posted[2].Deleted {
+		t.Errorf("expected the reply to be updated and its continuations deleted: %+v", posted)
+	}
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/controllers"
+	"github.com/openshift-splat-team/splat-bot/pkg/scheduler"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	scheduleTimeFormat = "2006-01-02 15:04 UTC"
+)
+
+var jobScheduler *scheduler.Scheduler
+
+// SchedulerPlugin runs commands on a schedule and posts their responses to a channel. Jobs only run on the
+// leader when leader election is enabled.
+type SchedulerPlugin struct {
+	client    util.SlackClientInterface
+	botUserID string
+	scheduler *scheduler.Scheduler
+	stopped   <-chan struct{}
+}
+
+// NewSchedulerPlugin returns the plugin which runs scheduled jobs. Responses are posted with client.
+func NewSchedulerPlugin(client util.SlackClientInterface) *SchedulerPlugin {
+	return &SchedulerPlugin{client: client}
+}
+
+func (p *SchedulerPlugin) Name() string {
+	return "scheduler"
+}
+
+func (p *SchedulerPlugin) Configure(cfg *config.Config) error {
+	var state scheduler.State = scheduler.NewMemoryState()
+	if len(cfg.Scheduler.StateConfigMap) > 0 {
+		namespace, name, _ := strings.Cut(cfg.Scheduler.StateConfigMap, "/")
+		configMapState, err := scheduler.NewConfigMapState(namespace, name)
+		if err != nil {
+			return fmt.Errorf("unable to create ConfigMap scheduler state: %v", err)
+		}
+		state = configMapState
+	}
+	s, err := scheduler.New(cfg.Scheduler.Jobs, p.runJob, state, controllers.IsLeader)
+	if err != nil {
+		return err
+	}
+	p.botUserID = cfg.Slack.BotUserID
+	p.scheduler = s
+	jobScheduler = s
+	return nil
+}
+
+func (p *SchedulerPlugin) Start(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		p.scheduler.Run(ctx)
+	}()
+	p.stopped = stopped
+	return nil
+}
+
+// Stop waits for running jobs to finish. The scheduler stops once the context passed to Start is done.
+func (p *SchedulerPlugin) Stop(ctx context.Context) error {
+	if p.stopped == nil {
+		return nil
+	}
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *SchedulerPlugin) Commands() []data.Attributes {
+	return []data.Attributes{
+		ScheduleAttributes,
+	}
+}
+
+// runJob runs the command of a job as if it was sent to the job's channel and posts the response there. Jobs
+// are configured by the operators of the bot so the command policy and rate limits don't apply.
+func (p *SchedulerPlugin) runJob(ctx context.Context, job config.ScheduledJob) error {
+	msg := &slackevents.MessageEvent{
+		Type:    "scheduled_job",
+		Channel: job.Channel,
+		User:    p.botUserID,
+		Text:    job.Command,
+	}
+	candidates, rejections := collectCandidates(msg, sourceScheduledJob)
+	reportCandidates(msg, candidates, rejections)
+	for _, candidate := range candidates {
+		if len(candidate.attribute.Commands) == 0 {
+			// catch-alls such as knowledge answer questions rather than run commands
+			continue
+		}
+		response, finished := invokeWithTimeout(ctx, p.client, candidate.attribute, msg, candidate.args)
+		// the job is running until the command returns, so a command which overruns its timeout doesn't overlap
+		// the next run
+		defer func() { <-finished }()
+		pages := util.SplitResponse(response)
+		channel, timestamp, err := p.client.PostMessage(job.Channel, pages[0]...)
+		if err != nil {
+			return fmt.Errorf("failed posting response of scheduled job %s: %v", job.Name, err)
+		}
+		_, err = postContinuations(p.client, channel, timestamp, pages[1:])
+		return err
+	}
+	return fmt.Errorf("no command matches `%s`", job.Command)
+}
+
+// describeJob describes the status of a job in one line.
+func describeJob(status scheduler.Status) string {
+	job := status.Job
+	var details []string
+	switch {
+	case status.Running:
+		details = append(details, "running")
+	case job.Paused:
+		details = append(details, "paused")
+	case !status.Next.IsZero():
+		details = append(details, "next run "+status.Next.UTC().Format(scheduleTimeFormat))
+	}
+	if !status.LastRun.IsZero() {
+		lastRun := "last run " + status.LastRun.UTC().Format(scheduleTimeFormat)
+		if status.LastError != nil {
+			lastRun = fmt.Sprintf("%s failed: %v", lastRun, status.LastError)
+		}
+		details = append(details, lastRun)
+	}
+	return fmt.Sprintf("`%s` `%s` in <#%s>: `%s` (%s)", job.Name, job.Schedule, job.Channel, job.Command, strings.Join(details, ", "))
+}
+
+var ScheduleAttributes = data.Attributes{
+	Commands:       []string{"schedule"},
+	Mutating:       true,
+	RequireMention: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		if jobScheduler == nil {
+			return util.StringToBlock("the scheduler is not enabled", false), nil
+		}
+		parsed := data.GetParsedArgs(ctx)
+		name := parsed.String("job")
+		action := parsed.String("action")
+		if action != "list" && len(name) == 0 {
+			return util.StringToBlock(fmt.Sprintf("`schedule %s` requires the name of the job", action), false),
+				fmt.Errorf("requires the name of the job")
+		}
+
+		var err error
+		switch action {
+		case "pause":
+			err = jobScheduler.Pause(ctx, name)
+		case "resume":
+			err = jobScheduler.Resume(ctx, name)
+		case "run-now":
+			err = jobScheduler.RunNow(name)
+		default:
+			statuses, listErr := jobScheduler.Jobs(ctx)
+			if listErr != nil {
+				return nil, listErr
+			}
+			if len(statuses) == 0 {
+				return util.StringToBlock("there are no scheduled jobs", false), nil
+			}
+			lines := make([]string, 0, len(statuses))
+			for _, status := range statuses {
+				lines = append(lines, describeJob(status))
+			}
+			return util.StringToBlockUnfurl(strings.Join(lines, "\n"), false, false), nil
+		}
+		if err != nil {
+			return util.StringToBlock(err.Error(), false), err
+		}
+		log.Infof("user %s ran schedule %s %s", evt.User, action, name)
+
+		switch action {
+		case "pause":
+			return util.StringToBlock(fmt.Sprintf("job %s is paused", name), false), nil
+		case "resume":
+			return util.StringToBlock(fmt.Sprintf("job %s is resumed", name), false), nil
+		default:
+			return util.StringToBlock(fmt.Sprintf("job %s is running. its response will be posted to its channel", name), false), nil
+		}
+	},
+	Description: "list, pause, resume or run the scheduled jobs",
+	Arguments: []data.Argument{
+		{Name: "action", Enum: []string{"list", "pause", "resume", "run-now"}, Default: "list"},
+		{Name: "job", Help: "name of the job to pause, resume or run"},
+	},
+	ShouldMatch: []string{
+		"schedule list",
+		"schedule pause prow-failures",
+		"schedule run-now prow-failures",
+	},
+	ShouldntMatch: []string{
+		"ci pools list",
+		"prow results vsphere 4.16 failure",
+	},
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/fakeslack"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestSchedulerPluginRunJob(t *testing.T) {
+	attributeMu.Lock()
+	saved := attributes
+	attributes = []data.Attributes{}
+	attributeMu.Unlock()
+	defer func() {
+		attributeMu.Lock()
+		attributes = saved
+		attributeMu.Unlock()
+		jobScheduler = nil
+	}()
+
+	AddCommand(data.Attributes{
+		Commands:       []string{"report"},
+		RequireMention: true,
+		Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+			return util.StringToBlock("report for "+args[1], false), nil
+		},
+	})
+
+	workspace := fakeslack.NewWorkspace(SPLAT_BOT_USER_ID)
+	plugin := NewSchedulerPlugin(workspace)
+	cfg := config.Default()
+	cfg.Slack.BotUserID = SPLAT_BOT_USER_ID
+	cfg.Scheduler.Jobs = []config.ScheduledJob{{Name: "report", Schedule: "@daily", Channel: "C1", Command: "report vsphere"}}
+	if err := plugin.Configure(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := plugin.runJob(context.TODO(), cfg.Scheduler.Jobs[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	posted := workspace.Posted()
+	if len(posted) != 1 || posted[0].Channel != "C1" || posted[0].Text != "report for vsphere" || len(posted[0].ThreadTimestamp) > 0 {
+		t.Errorf("expected the response to be posted to the channel without a mention: %+v", posted)
+	}
+
+	err := plugin.runJob(context.TODO(), config.ScheduledJob{Name: "unknown", Channel: "C1", Command: "unknown command"})
+	if err == nil {
+		t.Errorf("expected jobs without a matching command to fail")
+	}
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+// slashCommandResponseType returns the response type for a slash command response. Responses are ephemeral
+// unless the command responds in the channel and its response is not ephemeral.
+func slashCommandResponseType(attribute data.Attributes) string {
+	if attribute.RespondInChannel && !attribute.ResponseIsEphemeral {
+		return slack.ResponseTypeInChannel
+	}
+	return slack.ResponseTypeEphemeral
+}
+
+// respondToSlashCommand delivers a response with the response URL of the slash command. Responses which don't fit
+// in one message are delivered as several messages.
+func respondToSlashCommand(client util.SlackClientInterface, cmd slack.SlashCommand, attribute data.Attributes, response []slack.MsgOption) error {
+	if attribute.RespondInDM {
+		channelID, err := getDMChannelID(client, &slackevents.MessageEvent{User: cmd.UserID})
+		if err != nil {
+			return fmt.Errorf("failed getting channel ID: %v", err)
+		}
+		for _, page := range util.SplitResponse(response) {
+			if _, _, err = client.PostMessage(channelID, page...); err != nil {
+				return fmt.Errorf("failed responding to slash command: %v", err)
+			}
+		}
+		return nil
+	}
+
+	// a response URL accepts up to 5 responses, one for each message of a split response
+	for _, page := range util.SplitResponse(response) {
+		page = append(page, slack.MsgOptionResponseURL(cmd.ResponseURL, slashCommandResponseType(attribute)))
+		if _, _, err := client.PostMessage(cmd.ChannelID, page...); err != nil {
+			return fmt.Errorf("failed responding to slash command: %v", err)
+		}
+	}
+	return nil
+}
+
+// SlashCommandHandler handles a slash command such as `/splat ci lease acquire`. The text of the command is
+// dispatched to the same commands as a message mentioning the bot.
+func SlashCommandHandler(ctx context.Context, client util.SlackClientInterface, cmd slack.SlashCommand) error {
+	log.Debugf("SlashCommand: %s; %s; %s %s", cmd.UserID, cmd.ChannelID, cmd.Command, cmd.Text)
+
+	text := strings.TrimSpace(cmd.Text)
+	if len(text) == 0 {
+		text = "help"
+	}
+	msg := &slackevents.MessageEvent{
+		Channel:  cmd.ChannelID,
+		User:     cmd.UserID,
+		Username: cmd.UserName,
+		Text:     text,
+		Type:     "slash_command",
+	}
+
+	candidates, rejections := collectCandidates(msg, sourceSlashCommand)
+	reportCandidates(msg, candidates, rejections)
+	for _, candidate := range candidates {
+		attribute := candidate.attribute
+		if err := isAllowedUser(attribute, candidate.args, msg); err != nil {
+			recordInvocation(ctx, attribute, msg, candidate.args, time.Now(), data.AuditOutcomeDenied, err)
+			postErr := respondToSlashCommand(client, cmd, data.Attributes{}, util.StringToBlock(err.Error(), false))
+			if postErr != nil {
+				log.Warnf("failed notifying user of denied command: %v", postErr)
+			}
+			return fmt.Errorf("user %s with id %s is not allowed: %v", msg.Username, msg.User, err)
+		}
+
+		// as with messages, catch-alls are only limited when they respond
+		reservation, err := reserveRateLimit(attribute, msg)
+		if err != nil {
+			if len(attribute.Commands) == 0 {
+				log.Debugf("not responding with %s: %v", describeAttribute(attribute), err)
+				continue
+			}
+			recordInvocation(ctx, attribute, msg, candidate.args, time.Now(), data.AuditOutcomeRateLimited, err)
+			postErr := respondToSlashCommand(client, cmd, data.Attributes{}, util.StringToBlock(err.Error(), false))
+			if postErr != nil {
+				log.Warnf("failed notifying user of rate limited command: %v", postErr)
+			}
+			return fmt.Errorf("user %s with id %s is rate limited: %v", msg.Username, msg.User, err)
+		}
+
+		if isAsync(attribute, msg, candidate.args) && workers != nil {
+			args := candidate.args
+			err := workers.submit(msg.User, func(workerCtx context.Context) {
+				response, finished := invokeWithTimeout(workerCtx, client, attribute, msg, args)
+				if err := respondToSlashCommand(client, cmd, attribute, response); err != nil {
+					log.Warnf("failed delivering response to %v: %v", attribute.Commands, err)
+				}
+				<-finished
+			})
+			if err != nil {
+				postErr := respondToSlashCommand(client, cmd, data.Attributes{}, util.StringToBlock(err.Error(), false))
+				if postErr != nil {
+					log.Warnf("failed notifying user of rejected command: %v", postErr)
+				}
+				return fmt.Errorf("unable to run %v for user %s: %v", attribute.Commands, msg.User, err)
+			}
+			return nil
+		}
+
+		response := invokeCommand(ctx, client, attribute, msg, candidate.args)
+		if len(response) > 0 {
+			return respondToSlashCommand(client, cmd, attribute, response)
+		}
+		reservation.cancel()
+	}
+
+	return respondToSlashCommand(client, cmd, data.Attributes{},
+		util.StringToBlock(fmt.Sprintf("I don't know how to help with `%s`. Try `%s help`.", cmd.Text, cmd.Command), false))
+}
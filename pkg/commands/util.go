@@ -1,5 +0,0 @@
-package commands
-
-const (
-	JIRA_BASE_URL = "https://issues.redhat.com"
-)
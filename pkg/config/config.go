@@ -0,0 +1,465 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// VERSION the version of the configuration file format.
+	VERSION = "v1"
+
+	DEFAULT_SLACK_WORKSPACE            = "redhat-internal"
+	DEFAULT_SLACK_GROUP_CACHE_TTL      = 15 * time.Minute
+	DEFAULT_COMMAND_WORKERS            = 4
+	DEFAULT_COMMAND_WORKERS_PER_USER   = 2
+	DEFAULT_AUDIT_CONFIGMAP_RECORDS    = 1000
+	DEFAULT_METRICS_BIND_ADDRESS       = ":8080"
+	DEFAULT_OLLAMA_MODEL               = "tinyllama"
+	DEFAULT_MODEL_TEMPERATURE          = 0.7
+	DEFAULT_PROMPT_PATH                = "/usr/src/app/knowledge_prompts"
+	DEFAULT_KNOWLEDGE_MAX_ANSWERS      = 1
+	DEFAULT_KNOWLEDGE_CACHE_DIR        = "/tmp/splat-bot/knowledge"
+	DEFAULT_KNOWLEDGE_CACHE_TTL        = 24 * time.Hour
+	DEFAULT_KNOWLEDGE_LLM_TIMEOUT      = 30 * time.Second
+	DEFAULT_KNOWLEDGE_GIT_BRANCH       = "main"
+	DEFAULT_KNOWLEDGE_PULL_INTERVAL    = 5 * time.Minute
+	DEFAULT_KNOWLEDGE_FEEDBACK_RECORDS = 1000
+	DEFAULT_GITHUB_APP_ID              = "858938"
+	DEFAULT_GITHUB_INSTALLATION_ID     = "48639702"
+	DEFAULT_GITHUB_KEY_PATH            = "data/private.key"
+	DEFAULT_JIRA_BASE_URL              = "https://issues.redhat.com"
+	DEFAULT_DOC_QUERY_URL              = "http://localhost:8000/"
+	DEFAULT_LEADER_ELECTION_ID         = "splat-bot-leader"
+	redacted                           = "<redacted>"
+)
+
+// Config is the configuration of the bot. Each field may be overridden by the environment variable named in
+// its env tag. Fields tagged secret are redacted when the configuration is printed.
+type Config struct {
+	Version string `yaml:"version"`
+	// Plugins the names of the plugins to enable, in the order they're started.
+	Plugins   []string        `yaml:"plugins" env:"PLUGINS"`
+	Slack     SlackConfig     `yaml:"slack"`
+	Commands  CommandsConfig  `yaml:"commands"`
+	Audit     AuditConfig     `yaml:"audit"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	LLM       LLMConfig       `yaml:"llm"`
+	Knowledge KnowledgeConfig `yaml:"knowledge"`
+	GitHub    GitHubConfig    `yaml:"github"`
+	Jira      JiraConfig      `yaml:"jira"`
+	Docs      DocsConfig      `yaml:"docs"`
+	Accounts  AccountsConfig  `yaml:"accounts"`
+	// LeaderElection elects the replica which runs the controllers and background jobs.
+	LeaderElection LeaderElectionConfig `yaml:"leaderElection"`
+	Scheduler      SchedulerConfig      `yaml:"scheduler"`
+}
+
+// SlackConfig configures the connection to Slack and who may use the bot.
+type SlackConfig struct {
+	AppToken string `yaml:"appToken" env:"SLACK_APP_TOKEN" secret:"true"`
+	BotToken string `yaml:"botToken" env:"SLACK_BOT_TOKEN" secret:"true"`
+	// BotUserID the user ID of the bot, used to recognize mentions.
+	BotUserID string `yaml:"botUserID" env:"SPLAT_BOT_USER_ID"`
+	// Workspace the workspace name used in links to threads.
+	Workspace string `yaml:"workspace" env:"SLACK_WORKSPACE"`
+	// AllowedUsers user IDs which may run commands which don't allow non-SPLAT users.
+	AllowedUsers []string `yaml:"allowedUsers" env:"SLACK_ALLOWED_USERS"`
+	// AllowedGroups user group handles or IDs whose members may run commands which don't allow non-SPLAT users.
+	AllowedGroups []string `yaml:"allowedGroups" env:"SLACK_ALLOWED_GROUPS"`
+	// AdminUsers user IDs which may run administrative commands, such as splat audit.
+	AdminUsers []string `yaml:"adminUsers" env:"SLACK_ADMIN_USERS"`
+	// AdminGroups user group handles or IDs whose members may run administrative commands.
+	AdminGroups []string `yaml:"adminGroups" env:"SLACK_ADMIN_GROUPS"`
+	// GroupCacheTTL how long user group membership is cached.
+	GroupCacheTTL time.Duration `yaml:"groupCacheTTL" env:"SLACK_GROUP_CACHE_TTL"`
+	// CommandPolicyPath the path to the command policy file.
+	CommandPolicyPath string `yaml:"commandPolicyPath" env:"SLACK_COMMAND_POLICY_PATH"`
+}
+
+// CommandsConfig configures how commands are run.
+type CommandsConfig struct {
+	// EnableChatResponse responds with the LLM when no command responds to a mention.
+	EnableChatResponse bool `yaml:"enableChatResponse" env:"ENABLE_CHAT_RESPONSE"`
+	// DebugDispatch logs why each command did or didn't match a message.
+	DebugDispatch bool `yaml:"debugDispatch" env:"DEBUG_COMMAND_DISPATCH"`
+	// Workers the number of async commands which may run at once.
+	Workers int `yaml:"workers" env:"COMMAND_WORKERS"`
+	// WorkersPerUser the number of async commands a user may have running or queued at once.
+	WorkersPerUser int `yaml:"workersPerUser" env:"COMMAND_WORKERS_PER_USER"`
+	// RateLimitPerUser the default rate limit of a command per user, in the form <requests>/<duration>.
+	RateLimitPerUser RateLimit `yaml:"rateLimitPerUser" env:"RATE_LIMIT_PER_USER"`
+	// RateLimitPerChannel the default rate limit of a command per channel, in the form <requests>/<duration>.
+	RateLimitPerChannel RateLimit `yaml:"rateLimitPerChannel" env:"RATE_LIMIT_PER_CHANNEL"`
+}
+
+// AuditConfig configures where command invocations are recorded.
+type AuditConfig struct {
+	// LogPath the JSONL file records are appended to.
+	LogPath string `yaml:"logPath" env:"AUDIT_LOG_PATH"`
+	// ConfigMap the ConfigMap, in the form <namespace>/<name>, records are stored in.
+	ConfigMap string `yaml:"configMap" env:"AUDIT_CONFIGMAP"`
+	// ConfigMapMaxRecords the number of records kept in the ConfigMap.
+	ConfigMapMaxRecords int `yaml:"configMapMaxRecords" env:"AUDIT_CONFIGMAP_MAX_RECORDS"`
+}
+
+// MetricsConfig configures the metrics and health check server.
+type MetricsConfig struct {
+	BindAddress string `yaml:"bindAddress" env:"METRICS_BIND_ADDRESS"`
+}
+
+// LLMConfig configures the LLM used to generate responses.
+type LLMConfig struct {
+	Endpoint    string  `yaml:"endpoint" env:"OLLAMA_ENDPOINT"`
+	Model       string  `yaml:"model" env:"OLLAMA_MODEL"`
+	Temperature float64 `yaml:"temperature" env:"MODEL_TEMPERATURE"`
+}
+
+// KnowledgeConfig configures the knowledge assets.
+type KnowledgeConfig struct {
+	// PromptPath the directory knowledge assets are loaded from.
+	PromptPath string `yaml:"promptPath" env:"PROMPT_PATH"`
+	// MaxAnswers the number of matching assets combined into an answer. The best match is used by default.
+	MaxAnswers int `yaml:"maxAnswers" env:"KNOWLEDGE_MAX_ANSWERS"`
+	// CacheDir the directory the content of the URLs of assets which invoke the LLM is cached in.
+	CacheDir string `yaml:"cacheDir" env:"KNOWLEDGE_CACHE_DIR"`
+	// CacheTTL the time cached content is used before the URL is fetched again.
+	CacheTTL time.Duration `yaml:"cacheTTL" env:"KNOWLEDGE_CACHE_TTL"`
+	// LLMTimeout the time an answer may take to generate before the asset's markdown is used instead.
+	LLMTimeout time.Duration `yaml:"llmTimeout" env:"KNOWLEDGE_LLM_TIMEOUT"`
+	// Watch reloads the assets when the files in the prompt path change.
+	Watch bool `yaml:"watch" env:"KNOWLEDGE_WATCH"`
+	// DebugMatching logs how the conditions of each asset were evaluated against each message.
+	DebugMatching bool `yaml:"debugMatching" env:"KNOWLEDGE_DEBUG_MATCHING"`
+	// GitRepository a repository which is cloned into the prompt path and pulled periodically. Optional.
+	GitRepository string `yaml:"gitRepository" env:"KNOWLEDGE_GIT_REPOSITORY"`
+	// GitBranch the branch of the repository which is pulled.
+	GitBranch string `yaml:"gitBranch" env:"KNOWLEDGE_GIT_BRANCH"`
+	// GitPath the directory of the repository assets are loaded from. Defaults to the whole repository.
+	GitPath string `yaml:"gitPath" env:"KNOWLEDGE_GIT_PATH"`
+	// GitPullInterval the time between pulls of the repository.
+	GitPullInterval time.Duration `yaml:"gitPullInterval" env:"KNOWLEDGE_GIT_PULL_INTERVAL"`
+	// FeedbackPath the JSONL file ratings of answers are appended to.
+	FeedbackPath string `yaml:"feedbackPath" env:"KNOWLEDGE_FEEDBACK_PATH"`
+	// FeedbackConfigMap the ConfigMap, in the form <namespace>/<name>, ratings of answers are stored in. It's
+	// preferred over FeedbackPath. Without either, ratings are only kept in memory.
+	FeedbackConfigMap string `yaml:"feedbackConfigMap" env:"KNOWLEDGE_FEEDBACK_CONFIGMAP"`
+	// FeedbackConfigMapMaxRecords the number of ratings kept in the ConfigMap.
+	FeedbackConfigMapMaxRecords int `yaml:"feedbackConfigMapMaxRecords" env:"KNOWLEDGE_FEEDBACK_CONFIGMAP_MAX_RECORDS"`
+}
+
+// GitHubConfig configures the GitHub app used by the pull request commands.
+type GitHubConfig struct {
+	AppID          string `yaml:"appID" env:"GITHUB_APP_ID"`
+	InstallationID string `yaml:"installationID" env:"GITHUB_INSTALLATION_ID"`
+	// KeyPath the path to the private key of the app. The pull request commands are disabled without it.
+	KeyPath string `yaml:"keyPath" env:"GITHUB_KEY_PATH"`
+}
+
+// JiraConfig configures the Jira commands.
+type JiraConfig struct {
+	// BaseURL the Jira issues are created in and linked to.
+	BaseURL             string `yaml:"baseURL" env:"JIRA_BASE_URL"`
+	PersonalAccessToken string `yaml:"personalAccessToken" env:"JIRA_PERSONAL_ACCESS_TOKEN" secret:"true"`
+}
+
+// DocsConfig configures the ask-docs command.
+type DocsConfig struct {
+	QueryURL string `yaml:"queryURL" env:"DOC_QUERY_URL"`
+}
+
+// AccountsConfig configures the vCenter accounts and DNS records created for user leases.
+type AccountsConfig struct {
+	// VCenters the vCenters accounts are minted in. Leases aren't given accounts without any.
+	VCenters      []string `yaml:"vcenters" env:"ACCOUNT_MINTING_VCENTERS"`
+	AdminUsername string   `yaml:"adminUsername" env:"ADMIN_CREDENTIAL_MINTER_USERNAME"`
+	AdminPassword string   `yaml:"adminPassword" env:"ADMIN_CREDENTIAL_MINTER_PASSWORD" secret:"true"`
+	// DomainName the domain DNS records for leases are created in.
+	DomainName string `yaml:"domainName" env:"USER_DOMAIN_NAME"`
+	// HostedZoneID the Route 53 hosted zone of DomainName.
+	HostedZoneID string `yaml:"hostedZoneID" env:"HOSTED_ZONE_ID"`
+}
+
+// LeaderElectionConfig configures the election of the replica which reconciles leases and runs background jobs
+// when several replicas are deployed. Every replica handles Slack events.
+type LeaderElectionConfig struct {
+	Enabled bool `yaml:"enabled" env:"LEADER_ELECTION"`
+	// Namespace the namespace of the election lease. Defaults to the namespace the bot runs in.
+	Namespace string `yaml:"namespace" env:"LEADER_ELECTION_NAMESPACE"`
+	// ID the name of the election lease.
+	ID string `yaml:"id" env:"LEADER_ELECTION_ID"`
+}
+
+// SchedulerConfig configures the jobs which run commands on a schedule and post the response to a channel.
+type SchedulerConfig struct {
+	Jobs []ScheduledJob `yaml:"jobs"`
+	// StateConfigMap the ConfigMap, in the form <namespace>/<name>, which jobs paused from Slack are stored in.
+	// Without it, paused jobs are only known to the replica which paused them and are resumed on restart.
+	StateConfigMap string `yaml:"stateConfigMap" env:"SCHEDULER_STATE_CONFIGMAP"`
+}
+
+// ScheduledJob runs a command on a schedule.
+type ScheduledJob struct {
+	// Name identifies the job in `schedule` commands.
+	Name string `yaml:"name"`
+	// Schedule a cron expression with minute, hour, day of month, month and day of week fields, or one of
+	// @hourly, @daily, @weekly, @monthly or @every <duration>. Times are UTC.
+	Schedule string `yaml:"schedule"`
+	// Channel the channel ID the response is posted to.
+	Channel string `yaml:"channel"`
+	// Command the command path and arguments, as a user would type them. e.g. `prow results vsphere 4.16 failure`
+	Command string `yaml:"command"`
+	// Paused jobs don't run until they're resumed from Slack.
+	Paused bool `yaml:"paused"`
+}
+
+// Default returns the configuration used when nothing is configured.
+func Default() *Config {
+	return &Config{
+		Version: VERSION,
+		Plugins: []string{"core", "github", "ci", "knowledge", "scheduler"},
+		Slack: SlackConfig{
+			Workspace:     DEFAULT_SLACK_WORKSPACE,
+			GroupCacheTTL: DEFAULT_SLACK_GROUP_CACHE_TTL,
+		},
+		Commands: CommandsConfig{
+			Workers:        DEFAULT_COMMAND_WORKERS,
+			WorkersPerUser: DEFAULT_COMMAND_WORKERS_PER_USER,
+		},
+		Audit: AuditConfig{
+			ConfigMapMaxRecords: DEFAULT_AUDIT_CONFIGMAP_RECORDS,
+		},
+		Metrics: MetricsConfig{
+			BindAddress: DEFAULT_METRICS_BIND_ADDRESS,
+		},
+		LLM: LLMConfig{
+			Model:       DEFAULT_OLLAMA_MODEL,
+			Temperature: DEFAULT_MODEL_TEMPERATURE,
+		},
+		Knowledge: KnowledgeConfig{
+			PromptPath:                  DEFAULT_PROMPT_PATH,
+			MaxAnswers:                  DEFAULT_KNOWLEDGE_MAX_ANSWERS,
+			CacheDir:                    DEFAULT_KNOWLEDGE_CACHE_DIR,
+			CacheTTL:                    DEFAULT_KNOWLEDGE_CACHE_TTL,
+			LLMTimeout:                  DEFAULT_KNOWLEDGE_LLM_TIMEOUT,
+			Watch:                       true,
+			GitBranch:                   DEFAULT_KNOWLEDGE_GIT_BRANCH,
+			GitPullInterval:             DEFAULT_KNOWLEDGE_PULL_INTERVAL,
+			FeedbackConfigMapMaxRecords: DEFAULT_KNOWLEDGE_FEEDBACK_RECORDS,
+		},
+		GitHub: GitHubConfig{
+			AppID:          DEFAULT_GITHUB_APP_ID,
+			InstallationID: DEFAULT_GITHUB_INSTALLATION_ID,
+			KeyPath:        DEFAULT_GITHUB_KEY_PATH,
+		},
+		Jira: JiraConfig{
+			BaseURL: DEFAULT_JIRA_BASE_URL,
+		},
+		Docs: DocsConfig{
+			QueryURL: DEFAULT_DOC_QUERY_URL,
+		},
+		LeaderElection: LeaderElectionConfig{
+			ID: DEFAULT_LEADER_ELECTION_ID,
+		},
+	}
+}
+
+// Load reads the configuration file at path, if path is set, over the defaults and then applies the
+// environment variable overrides. The configuration isn't validated.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if len(path) > 0 {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read configuration: %v", err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unable to parse configuration %s: %v", path, err)
+		}
+	}
+	if err := applyEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration. Every missing or invalid value is reported.
+func (c *Config) Validate() error {
+	var problems []string
+	report := func(field, env, format string, args ...any) {
+		problem := fmt.Sprintf("%s: %s", field, fmt.Sprintf(format, args...))
+		if len(env) > 0 {
+			problem = fmt.Sprintf("%s (%s): %s", field, env, fmt.Sprintf(format, args...))
+		}
+		problems = append(problems, problem)
+	}
+
+	if c.Version != VERSION {
+		report("version", "", "unsupported version %q, expected %q", c.Version, VERSION)
+	}
+	if len(c.Plugins) == 0 {
+		report("plugins", "PLUGINS", "at least one plugin must be enabled")
+	}
+
+	switch {
+	case len(c.Slack.AppToken) == 0:
+		report("slack.appToken", "SLACK_APP_TOKEN", "must be set")
+	case !strings.HasPrefix(c.Slack.AppToken, "xapp-"):
+		report("slack.appToken", "SLACK_APP_TOKEN", "must have the prefix \"xapp-\"")
+	}
+	switch {
+	case len(c.Slack.BotToken) == 0:
+		report("slack.botToken", "SLACK_BOT_TOKEN", "must be set")
+	case !strings.HasPrefix(c.Slack.BotToken, "xoxb-"):
+		report("slack.botToken", "SLACK_BOT_TOKEN", "must have the prefix \"xoxb-\"")
+	}
+	if len(c.Slack.BotUserID) == 0 {
+		report("slack.botUserID", "SPLAT_BOT_USER_ID", "must be set so mentions of the bot are recognized")
+	}
+	if c.Slack.GroupCacheTTL <= 0 {
+		report("slack.groupCacheTTL", "SLACK_GROUP_CACHE_TTL", "must be positive")
+	}
+
+	if c.Commands.Workers < 1 {
+		report("commands.workers", "COMMAND_WORKERS", "must be at least 1")
+	}
+	if c.Commands.WorkersPerUser < 1 {
+		report("commands.workersPerUser", "COMMAND_WORKERS_PER_USER", "must be at least 1")
+	}
+
+	if len(c.Audit.ConfigMap) > 0 {
+		namespace, name, found := strings.Cut(c.Audit.ConfigMap, "/")
+		if !found || len(namespace) == 0 || len(name) == 0 {
+			report("audit.configMap", "AUDIT_CONFIGMAP", "must be in the form <namespace>/<name>")
+		}
+	}
+	if c.Audit.ConfigMapMaxRecords < 1 {
+		report("audit.configMapMaxRecords", "AUDIT_CONFIGMAP_MAX_RECORDS", "must be at least 1")
+	}
+
+	if len(c.Metrics.BindAddress) == 0 {
+		report("metrics.bindAddress", "METRICS_BIND_ADDRESS", "must be set")
+	}
+
+	if c.LLM.Temperature < 0 {
+		report("llm.temperature", "MODEL_TEMPERATURE", "must not be negative")
+	}
+
+	if c.Knowledge.MaxAnswers < 1 {
+		report("knowledge.maxAnswers", "KNOWLEDGE_MAX_ANSWERS", "must be at least 1")
+	}
+	if len(c.Knowledge.CacheDir) == 0 {
+		report("knowledge.cacheDir", "KNOWLEDGE_CACHE_DIR", "must be set")
+	}
+	if c.Knowledge.CacheTTL <= 0 {
+		report("knowledge.cacheTTL", "KNOWLEDGE_CACHE_TTL", "must be positive")
+	}
+	if c.Knowledge.LLMTimeout <= 0 {
+		report("knowledge.llmTimeout", "KNOWLEDGE_LLM_TIMEOUT", "must be positive")
+	}
+	if len(c.Knowledge.GitRepository) > 0 {
+		if len(c.Knowledge.GitBranch) == 0 {
+			report("knowledge.gitBranch", "KNOWLEDGE_GIT_BRANCH", "must be set to pull %s", c.Knowledge.GitRepository)
+		}
+		if c.Knowledge.GitPullInterval < time.Minute {
+			report("knowledge.gitPullInterval", "KNOWLEDGE_GIT_PULL_INTERVAL", "must be at least 1m")
+		}
+		if filepath.IsAbs(c.Knowledge.GitPath) || strings.HasPrefix(filepath.Clean(c.Knowledge.GitPath), "..") {
+			report("knowledge.gitPath", "KNOWLEDGE_GIT_PATH", "must be a directory within the repository")
+		}
+	}
+	if len(c.Knowledge.FeedbackConfigMap) > 0 {
+		namespace, name, found := strings.Cut(c.Knowledge.FeedbackConfigMap, "/")
+		if !found || len(namespace) == 0 || len(name) == 0 {
+			report("knowledge.feedbackConfigMap", "KNOWLEDGE_FEEDBACK_CONFIGMAP", "must be in the form <namespace>/<name>")
+		}
+	}
+	if c.Knowledge.FeedbackConfigMapMaxRecords < 1 {
+		report("knowledge.feedbackConfigMapMaxRecords", "KNOWLEDGE_FEEDBACK_CONFIGMAP_MAX_RECORDS", "must be at least 1")
+	}
+
+	if len(c.Accounts.VCenters) > 0 && (len(c.Accounts.AdminUsername) == 0 || len(c.Accounts.AdminPassword) == 0) {
+		report("accounts", "", "adminUsername and adminPassword must be set to mint accounts in vCenters")
+	}
+
+	if c.LeaderElection.Enabled && len(c.LeaderElection.ID) == 0 {
+		report("leaderElection.id", "LEADER_ELECTION_ID", "must be set when leader election is enabled")
+	}
+
+	jobNames := map[string]bool{}
+	for index, job := range c.Scheduler.Jobs {
+		field := fmt.Sprintf("scheduler.jobs[%d]", index)
+		switch {
+		case len(job.Name) == 0:
+			report(field+".name", "", "must be set")
+		case strings.ContainsAny(job.Name, " \t"):
+			report(field+".name", "", "must not contain spaces")
+		case jobNames[job.Name]:
+			report(field+".name", "", "%q is used by another job", job.Name)
+		}
+		jobNames[job.Name] = true
+		if len(job.Schedule) == 0 {
+			report(field+".schedule", "", "must be set")
+		}
+		if len(job.Channel) == 0 {
+			report(field+".channel", "", "must be set")
+		}
+		if len(job.Command) == 0 {
+			report(field+".command", "", "must be set")
+		}
+	}
+	if len(c.Scheduler.StateConfigMap) > 0 {
+		namespace, name, found := strings.Cut(c.Scheduler.StateConfigMap, "/")
+		if !found || len(namespace) == 0 || len(name) == 0 {
+			report("scheduler.stateConfigMap", "SCHEDULER_STATE_CONFIGMAP", "must be in the form <namespace>/<name>")
+		}
+	}
+
+	// each store fills up to the 1 MiB limit of a ConfigMap, so they can't share one
+	configMaps := map[string]string{}
+	for _, configMap := range []struct {
+		field string
+		env   string
+		name  string
+	}{
+		{field: "audit.configMap", env: "AUDIT_CONFIGMAP", name: c.Audit.ConfigMap},
+		{field: "knowledge.feedbackConfigMap", env: "KNOWLEDGE_FEEDBACK_CONFIGMAP", name: c.Knowledge.FeedbackConfigMap},
+		{field: "scheduler.stateConfigMap", env: "SCHEDULER_STATE_CONFIGMAP", name: c.Scheduler.StateConfigMap},
+	} {
+		if len(configMap.name) == 0 {
+			continue
+		}
+		if other, ok := configMaps[configMap.name]; ok {
+			report(configMap.field, configMap.env, "%q is used by %s", configMap.name, other)
+			continue
+		}
+		configMaps[configMap.name] = configMap.field
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// Print writes the configuration as YAML with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	copied := *c
+	redact(&copied)
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&copied); err != nil {
+		return fmt.Errorf("unable to print configuration: %v", err)
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `version: v1
+slack:
+  appToken: xapp-file
+  botToken: xoxb-file
+  botUserID: U0BOT
+  allowedUsers: [U1, U2]
+  groupCacheTTL: 5m
+commands:
+  workers: 8
+  rateLimitPerUser: 10/1m
+accounts:
+  vcenters: [vcenter-1.example.com]
+  adminUsername: admin
+  adminPassword: hunter2
+`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unable to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-env")
+	t.Setenv("SLACK_ALLOWED_GROUPS", "splat-team, @splat-admins")
+	t.Setenv("ACCOUNT_MINTING_VCENTERS", "vcenter-1.example.com vcenter-2.example.com")
+	t.Setenv("ENABLE_CHAT_RESPONSE", "true")
+	t.Setenv("PLUGINS", "knowledge")
+
+	cfg, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Slack.AppToken != "xapp-file" || cfg.Slack.BotToken != "xoxb-env" {
+		t.Errorf("expected the environment to override the file: %+v", cfg.Slack)
+	}
+	if cfg.Slack.GroupCacheTTL != 5*time.Minute {
+		t.Errorf("unexpected group cache TTL: %s", cfg.Slack.GroupCacheTTL)
+	}
+	if strings.Join(cfg.Slack.AllowedGroups, ",") != "splat-team,@splat-admins" {
+		t.Errorf("unexpected allowed groups: %v", cfg.Slack.AllowedGroups)
+	}
+	if strings.Join(cfg.Plugins, ",") != "knowledge" {
+		t.Errorf("unexpected plugins: %v", cfg.Plugins)
+	}
+	if len(cfg.Accounts.VCenters) != 2 {
+		t.Errorf("unexpected vCenters: %v", cfg.Accounts.VCenters)
+	}
+	if !cfg.Commands.EnableChatResponse || cfg.Commands.Workers != 8 {
+		t.Errorf("unexpected commands config: %+v", cfg.Commands)
+	}
+	if cfg.Commands.RateLimitPerUser != (RateLimit{Requests: 10, Per: time.Minute}) || cfg.Commands.RateLimitPerChannel.IsSet() {
+		t.Errorf("unexpected rate limits: %+v", cfg.Commands)
+	}
+	// defaults are kept for values which aren't configured
+	if cfg.Commands.WorkersPerUser != DEFAULT_COMMAND_WORKERS_PER_USER || cfg.GitHub.AppID != DEFAULT_GITHUB_APP_ID {
+		t.Errorf("expected defaults to be kept: %+v %+v", cfg.Commands, cfg.GitHub)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(writeConfig(t, "version: v1\nslack:\n  appTokn: xapp-typo\n")); err == nil {
+		t.Errorf("expected unknown fields to be rejected")
+	}
+
+	t.Setenv("COMMAND_WORKERS", "many")
+	t.Setenv("RATE_LIMIT_PER_CHANNEL", "10")
+	_, err := Load("")
+	if err == nil {
+		t.Fatalf("expected invalid environment variables to be rejected")
+	}
+	for _, expected := range []string{"COMMAND_WORKERS", "RATE_LIMIT_PER_CHANNEL"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %s to be reported: %v", expected, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Plugins = nil
+	cfg.Slack.AppToken = "xoxb-wrong"
+	cfg.Commands.Workers = 0
+	cfg.Audit.ConfigMap = "audit"
+	cfg.Knowledge.MaxAnswers = 0
+	cfg.Knowledge.LLMTimeout = 0
+	cfg.Knowledge.FeedbackConfigMap = "splat-bot/"
+	cfg.Scheduler.StateConfigMap = "audit"
+	cfg.Accounts.VCenters = []string{"vcenter-1.example.com"}
+	cfg.Scheduler.Jobs = []ScheduledJob{
+		{Name: "prow-failures", Schedule: "@daily", Channel: "C1", Command: "prow results vsphere 4.16 failure"},
+		{Name: "prow-failures", Schedule: "@daily", Channel: "C1"},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected the configuration to be invalid")
+	}
+	for _, expected := range []string{
+		"plugins (PLUGINS): at least one plugin must be enabled",
+		"slack.appToken (SLACK_APP_TOKEN): must have the prefix",
+		"slack.botToken (SLACK_BOT_TOKEN): must be set",
+		"slack.botUserID (SPLAT_BOT_USER_ID): must be set",
+		"commands.workers (COMMAND_WORKERS): must be at least 1",
+		"audit.configMap (AUDIT_CONFIGMAP)",
+		"knowledge.maxAnswers (KNOWLEDGE_MAX_ANSWERS): must be at least 1",
+		"knowledge.llmTimeout (KNOWLEDGE_LLM_TIMEOUT): must be positive",
+		"knowledge.feedbackConfigMap (KNOWLEDGE_FEEDBACK_CONFIGMAP): must be in the form <namespace>/<name>",
+		"accounts: adminUsername and adminPassword",
+		"scheduler.jobs[1].name: \"prow-failures\" is used by another job",
+		"scheduler.jobs[1].command: must be set",
+		"scheduler.stateConfigMap (SCHEDULER_STATE_CONFIGMAP): \"audit\" is used by audit.configMap",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q to be reported: %v", expected, err)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	t.Setenv("JIRA_PERSONAL_ACCESS_TOKEN", "jira-secret")
+	cfg, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buffer := &bytes.Buffer{}
+	if err := cfg.Print(buffer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, secret := range []string{"xapp-file", "xoxb-file", "hunter2", "jira-secret"} {
+		if strings.Contains(buffer.String(), secret) {
+			t.Errorf("expected %s to be redacted:\n%s", secret, buffer.String())
+		}
+	}
+	if !strings.Contains(buffer.String(), "rateLimitPerUser: 10/1m0s") || !strings.Contains(buffer.String(), "botUserID: U0BOT") {
+		t.Errorf("expected the configuration to be printed:\n%s", buffer.String())
+	}
+	if cfg.Slack.AppToken != "xapp-file" {
+		t.Errorf("expected printing not to modify the configuration")
+	}
+
+	// the printed configuration can be loaded again
+	if _, err := Load(writeConfig(t, buffer.String())); err != nil {
+		t.Errorf("unable to load printed configuration: %v", err)
+	}
+}
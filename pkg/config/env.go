@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides the fields of cfg which have an env tag with the value of the environment variable, if
+// it is set. Every invalid value is reported.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	var problems []string
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("env")
+		if len(name) == 0 {
+			return
+		}
+		envValue, ok := lookup(name)
+		if !ok {
+			return
+		}
+		if err := setField(value, envValue); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	})
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid environment variables:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// redact replaces the value of the fields tagged secret which are set.
+func redact(cfg *Config) {
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("secret") == "true" && value.Kind() == reflect.String && value.Len() > 0 {
+			value.SetString(redacted)
+		}
+	})
+}
+
+// walkFields calls fn for each field of the sections of the configuration.
+func walkFields(value reflect.Value, fn func(reflect.StructField, reflect.Value)) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+		if field.Type.Kind() == reflect.Struct && len(field.Tag.Get("env")) == 0 {
+			walkFields(fieldValue, fn)
+			continue
+		}
+		fn(field, fieldValue)
+	}
+}
+
+// setField parses value into the field.
+func setField(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		// lists are separated with commas or spaces
+		values := strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
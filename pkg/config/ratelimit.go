@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows a number of Requests every Per. The zero value doesn't limit requests.
+type RateLimit struct {
+	// Requests the number of requests allowed every Per. Requests may burst up to this number.
+	Requests int
+	// Per the period in which Requests are allowed.
+	Per time.Duration
+}
+
+// IsSet returns true if the rate limit limits requests.
+func (r RateLimit) IsSet() bool {
+	return r.Requests > 0 && r.Per > 0
+}
+
+// ParseRateLimit parses a rate limit in the form <requests>/<duration>. e.g. 10/1m
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, per, found := strings.Cut(value, "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("rate limit %s must be in the form <requests>/<duration>", value)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || count < 1 {
+		return RateLimit{}, fmt.Errorf("rate limit %s must allow at least one request", value)
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(per))
+	if err != nil || duration <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %s has an invalid duration", value)
+	}
+	return RateLimit{Requests: count, Per: duration}, nil
+}
+
+// MarshalText formats the rate limit as <requests>/<duration>. The zero value is empty.
+func (r RateLimit) MarshalText() ([]byte, error) {
+	if !r.IsSet() {
+		return []byte{}, nil
+	}
+	return []byte(fmt.Sprintf("%d/%s", r.Requests, r.Per)), nil
+}
+
+// UnmarshalText parses a rate limit in the form <requests>/<duration>. Empty text doesn't limit requests.
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	if len(strings.TrimSpace(string(text))) == 0 {
+		*r = RateLimit{}
+		return nil
+	}
+	parsed, err := ParseRateLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
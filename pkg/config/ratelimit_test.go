@@ -0,0 +1,21 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseRateLimit("10/1m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limit.Requests != 10 || limit.Per != time.Minute {
+		t.Errorf("unexpected rate limit: %+v", limit)
+	}
+	for _, invalid := range []string{"10", "0/1m", "ten/1m", "10/soon"} {
+		if _, err := ParseRateLimit(invalid); err == nil {
+			t.Errorf("expected %s to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package configmap stores state of the bot, such as audit records, in ConfigMaps so that it's shared between
+// replicas and kept across restarts.
+package configmap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// MAX_DATA_BYTES the size the records of a JSONL key are kept under. The data of a ConfigMap is limited to 1 MiB,
+// so each store needs a ConfigMap of its own.
+const MAX_DATA_BYTES = 900 * 1024
+
+// Store reads and updates a key of a ConfigMap. The ConfigMap is created when the key is first updated.
+type Store struct {
+	client client.Client
+	name   types.NamespacedName
+	key    string
+}
+
+// NewStore returns a store of the key of the ConfigMap namespace/name. The client is created from the kubeconfig
+// the bot runs with.
+func NewStore(namespace, name, key string) (*Store, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get kubeconfig: %v", err)
+	}
+	k8sclient, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client: %v", err)
+	}
+	return NewStoreWithClient(k8sclient, namespace, name, key), nil
+}
+
+// NewStoreWithClient returns a store of the key of the ConfigMap namespace/name which uses k8sclient.
+func NewStoreWithClient(k8sclient client.Client, namespace, name, key string) *Store {
+	return &Store{
+		client: k8sclient,
+		name:   types.NamespacedName{Namespace: namespace, Name: name},
+		key:    key,
+	}
+}
+
+// Get returns the contents of the key. The contents are empty if the ConfigMap doesn't exist.
+func (s *Store) Get(ctx context.Context) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.client.Get(ctx, s.name, configMap)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("unable to get ConfigMap %s: %v", s.name, err)
+	}
+	return configMap.Data[s.key], nil
+}
+
+// Update replaces the contents of the key with the contents update returns. update is called again with the
+// latest contents when another replica changed or created the ConfigMap first.
+func (s *Store) Update(ctx context.Context, update func(contents string) (string, error)) error {
+	changed := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, changed, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := s.client.Get(ctx, s.name, configMap)
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return err
+		}
+
+		contents, err := update(configMap.Data[s.key])
+		if err != nil {
+			return err
+		}
+
+		if create {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: s.name.Namespace,
+					Name:      s.name.Name,
+				},
+				Data: map[string]string{s.key: contents},
+			}
+			return s.client.Create(ctx, configMap)
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[s.key] = contents
+		return s.client.Update(ctx, configMap)
+	})
+}
+
+// JSONL stores records in a key of a ConfigMap, one JSON record per line. The oldest records are dropped to keep
+// at most maxRecords and to keep the key under MAX_DATA_BYTES.
+type JSONL[T any] struct {
+	store      *Store
+	maxRecords int
+}
+
+// NewJSONL returns records stored in the key of store.
+func NewJSONL[T any](store *Store, maxRecords int) *JSONL[T] {
+	return &JSONL[T]{store: store, maxRecords: maxRecords}
+}
+
+// Append adds a record after the records which are stored.
+func (j *JSONL[T]) Append(ctx context.Context, record T) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("unable to marshal record: %v", err)
+	}
+	return j.store.Update(ctx, func(contents string) (string, error) {
+		lines := append(splitLines(contents), string(line))
+		if len(lines) > j.maxRecords {
+			lines = lines[len(lines)-j.maxRecords:]
+		}
+		size := 0
+		for _, line := range lines {
+			size += len(line) + 1
+		}
+		for len(lines) > 1 && size > MAX_DATA_BYTES {
+			size -= len(lines[0]) + 1
+			lines = lines[1:]
+		}
+		buffer := bytes.Buffer{}
+		for _, line := range lines {
+			buffer.WriteString(line)
+			buffer.WriteByte('\n')
+		}
+		return buffer.String(), nil
+	})
+}
+
+// List returns the stored records, oldest first.
+func (j *JSONL[T]) List(ctx context.Context) ([]T, error) {
+	contents, err := j.store.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var records []T
+	for _, line := range splitLines(contents) {
+		var record T
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// splitLines returns the lines of contents which aren't blank.
+func splitLines(contents string) []string {
+	var lines []string
+	for _, line := range strings.Split(contents, "\n") {
+		if len(strings.TrimSpace(line)) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
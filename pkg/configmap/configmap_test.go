@@ -0,0 +1,71 @@
+package configmap
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type testRecord struct {
+	Name string `json:"name"`
+	Text string `json:"text,omitempty"`
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStoreWithClient(fake.NewClientBuilder().Build(), "splat", "state", "state.json")
+	if contents, err := store.Get(ctx); err != nil || contents != "" {
+		t.Fatalf("expected no contents before the ConfigMap is created: %q %v", contents, err)
+	}
+	for _, value := range []string{"created", "updated"} {
+		err := store.Update(ctx, func(contents string) (string, error) {
+			return contents + value, nil
+		})
+		if err != nil {
+			t.Fatalf("unable to update: %v", err)
+		}
+	}
+	if contents, err := store.Get(ctx); err != nil || contents != "createdupdated" {
+		t.Errorf("expected the ConfigMap to be created and then updated: %q %v", contents, err)
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	ctx := context.TODO()
+	k8sclient := fake.NewClientBuilder().Build()
+	records := NewJSONL[testRecord](NewStoreWithClient(k8sclient, "splat", "records", "records.jsonl"), 2)
+	for _, name := range []string{"ufo", "car", "spacecraft"} {
+		if err := records.Append(ctx, testRecord{Name: name}); err != nil {
+			t.Fatalf("unable to append: %v", err)
+		}
+	}
+	listed, err := records.List(ctx)
+	if err != nil {
+		t.Fatalf("unable to list: %v", err)
+	}
+	if len(listed) != 2 || listed[0].Name != "car" || listed[1].Name != "spacecraft" {
+		t.Errorf("expected the oldest record to be dropped: %+v", listed)
+	}
+
+	// records are dropped to keep the ConfigMap under its size limit
+	store := NewStoreWithClient(k8sclient, "splat", "large", "records.jsonl")
+	records = NewJSONL[testRecord](store, 1000)
+	text := strings.Repeat("x", MAX_DATA_BYTES/4)
+	for _, name := range []string{"ufo", "car", "spacecraft", "rocket", "satellite"} {
+		if err := records.Append(ctx, testRecord{Name: name, Text: text}); err != nil {
+			t.Fatalf("unable to append: %v", err)
+		}
+	}
+	listed, err = records.List(ctx)
+	if err != nil {
+		t.Fatalf("unable to list: %v", err)
+	}
+	if len(listed) != 3 || listed[0].Name != "spacecraft" {
+		t.Errorf("expected the oldest records to be dropped: %d records", len(listed))
+	}
+	if contents, _ := store.Get(ctx); len(contents) > MAX_DATA_BYTES {
+		t.Errorf("expected at most %d bytes, got %d", MAX_DATA_BYTES, len(contents))
+	}
+}
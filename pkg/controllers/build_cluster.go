@@ -1,54 +1,117 @@
 package controllers
 
 import (
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	"k8s.io/klog/v2/textlogger"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+var (
+	leaderMu sync.Mutex
+	elected  <-chan struct{}
 )
 
-func init() {
-	if os.Getenv("UNIT") != "" {
-		log.Printf("!!! controllers are disabled for unit tests")
-		return
+// IsLeader returns true if this replica is the elected leader which reconciles leases and runs background jobs.
+// Every replica is the leader when the controllers aren't started or leader election isn't enabled.
+func IsLeader() bool {
+	leaderMu.Lock()
+	ch := elected
+	leaderMu.Unlock()
+	if ch == nil {
+		return true
 	}
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start starts the controllers which manage pools and leases. The pool and lease views used by commands are
+// kept up to date on every replica while lease side effects, such as creating accounts and pruning expired
+// leases, only run on the elected leader. The controllers stop when ctx is done, after which the returned
+// channel is closed.
+func Start(ctx context.Context, accounts config.AccountsConfig, election config.LeaderElectionConfig) (<-chan struct{}, error) {
 	logger := textlogger.NewLogger(textlogger.NewConfig())
 	ctrl.SetLogger(logger)
 
-	mgr, err := manager.New(config.GetConfigOrDie(), manager.Options{})
+	restConfig, err := ctrlconfig.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not get kubeconfig: %v", err)
+	}
+
+	// metrics are served with the bot's health checks
+	mgr, err := manager.New(restConfig, manager.Options{
+		Metrics:                       metricsserver.Options{BindAddress: "0"},
+		LeaderElection:                election.Enabled,
+		LeaderElectionID:              election.ID,
+		LeaderElectionNamespace:       election.Namespace,
+		LeaderElectionReleaseOnCancel: true,
+	})
 	if err != nil {
-		log.Printf("could not create manager: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("could not create manager: %v", err)
 	}
 
 	err = v1.AddToScheme(mgr.GetScheme())
 	if err != nil {
-		log.Printf("could not add types to scheme: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("could not add types to scheme: %v", err)
 	}
 
 	if err := (&PoolReconciler{}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create PoolReconciler: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("unable to create PoolReconciler: %v", err)
 	}
 
-	if err := (&LeaseReconciler{}).
+	if err := (&LeaseReconciler{Accounts: accounts}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create LeaseReconciler: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("unable to create LeaseReconciler: %v", err)
 	}
 
+	var cacheSynced atomic.Bool
+	metrics.AddReadinessCheck("controller-cache", func() error {
+		if !cacheSynced.Load() {
+			return errors.New("cache has not synced")
+		}
+		return nil
+	})
+
+	leaderMu.Lock()
+	elected = mgr.Elected()
+	leaderMu.Unlock()
+	go func() {
+		select {
+		case <-mgr.Elected():
+			log.Infof("elected leader, running lease controllers and background jobs")
+		case <-ctx.Done():
+		}
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := mgr.Start(ctx); err != nil {
+			log.Errorf("could not start manager: %v", err)
+		}
+	}()
 	go func() {
-		if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
-			log.Printf("could not start manager: %v", err)
-			os.Exit(1)
+		if mgr.GetCache().WaitForCacheSync(ctx) {
+			cacheSynced.Store(true)
 		}
 	}()
+	return stopped, nil
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,6 +11,8 @@ import (
 	"time"
 
 	awstypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	log "github.com/sirupsen/logrus"
@@ -21,8 +22,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 const (
@@ -31,6 +35,7 @@ const (
 	userLeaseRenewLabel      = "vsphere-capacity-manager.splat-team.io/renew-counts"
 	LeaseDisablePruningLabel = "vsphere-capacity-manager.splat-team.io/disable-pruning"
 	leaseTimeIncrement       = 8
+	userLeasePruneInterval   = 30 * time.Minute
 	maxRenews                = 3
 
 	network_only_lease         = "network-only-lease"
@@ -38,6 +43,11 @@ const (
 	lease_details_sent         = "lease-details-sent"
 
 	VcmNamespace = "vsphere-infra-helpers"
+
+	// LeaseRenewActionID the action_id of the button which renews the leases of the user.
+	LeaseRenewActionID = "lease-renew"
+	// LeaseReleaseActionID the action_id of the button which releases the leases of the user.
+	LeaseReleaseActionID = "lease-release"
 )
 
 var (
@@ -134,6 +144,7 @@ func AcquireLease(ctx context.Context, user string, cpus, memory int, pool strin
 	leaseMu.Lock()
 	userLeases[user] = lease
 	leaseMu.Unlock()
+	metrics.LeaseAcquired()
 	return lease, nil
 }
 
@@ -276,7 +287,8 @@ type LeaseReconciler struct {
 	// ReleaseVersion is the version of current cluster operator release.
 	ReleaseVersion string
 
-	domainName string
+	// Accounts configures the accounts and DNS records created for leases.
+	Accounts config.AccountsConfig
 
 	// userReconciler reconciles users associated with leases
 	userReconciler *UserReconciler
@@ -290,42 +302,85 @@ func (l *LeaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	// Set up API helpers from the manager.
-	l.domainName = os.Getenv("USER_DOMAIN_NAME")
 	l.Client = mgr.GetClient()
 	k8sclient = mgr.GetClient()
 	l.Scheme = mgr.GetScheme()
 	l.Recorder = mgr.GetEventRecorderFor("pools-controller")
 	l.RESTMapper = mgr.GetRESTMapper()
 
+	if err := (&leaseViewReconciler{}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("error setting up lease view: %w", err)
+	}
+
 	// SetupWithManager
-	l.userReconciler = &UserReconciler{}
+	l.userReconciler = &UserReconciler{Accounts: l.Accounts}
 
 	if err := (l.userReconciler).
 		SetupWithManager(mgr); err != nil {
 		log.Printf("[LeaseReconciler] unable to create controller: %v", err)
 	}
 
-	l.userLeasePruner(context.TODO())
+	// runnables which don't opt out of leader election only run on the leader
+	if err := mgr.Add(manager.RunnableFunc(l.userLeasePruner)); err != nil {
+		return fmt.Errorf("error adding lease pruner: %w", err)
+	}
 	return nil
 }
 
-func cleanUpAccounts(ctx context.Context, lease *v1.Lease) error {
-	vcenters := os.Getenv("ACCOUNT_MINTING_VCENTERS")
-	var vcentersSlice []string
-	if vcenters == "" {
-		log.Printf("No vCenters set, user leases will not be deleted.")
-	} else {
-		vcentersSlice = strings.Split(vcenters, " ")
+// leaseViewReconciler keeps the leases shown and checked by the lease commands up to date. It doesn't modify
+// leases, so it runs on every replica and any replica can handle the lease commands.
+type leaseViewReconciler struct {
+	client.Client
+}
+
+func (l *leaseViewReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	l.Client = mgr.GetClient()
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("lease-view").
+		For(&v1.Lease{}).
+		WithOptions(controller.Options{NeedLeaderElection: ptr.To(false)}).
+		Complete(l); err != nil {
+		return fmt.Errorf("error setting up controller: %w", err)
 	}
+	return nil
+}
 
-	adminMinterUsername := os.Getenv("ADMIN_CREDENTIAL_MINTER_USERNAME")
-	adminMinterPassword := os.Getenv("ADMIN_CREDENTIAL_MINTER_PASSWORD")
+func (l *leaseViewReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	lease := &v1.Lease{}
+	err := l.Client.Get(ctx, req.NamespacedName, lease)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
 
-	for _, vcenter := range vcentersSlice {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	if err != nil || lease.DeletionTimestamp != nil {
+		delete(leases, req.Name)
+		for user, userLease := range userLeases {
+			if userLease.Name == req.Name {
+				delete(userLeases, user)
+				break
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if user, found := lease.Annotations[SplatBotLeaseOwner]; found && !hasLabel(lease, network_only_lease) {
+		userLeases[user] = lease
+	}
+	leases[lease.Name] = lease
+	return ctrl.Result{}, nil
+}
+
+func cleanUpAccounts(ctx context.Context, accounts config.AccountsConfig, lease *v1.Lease) error {
+	if len(accounts.VCenters) == 0 {
+		log.Printf("No vCenters set, user leases will not be deleted.")
+	}
+
+	for _, vcenter := range accounts.VCenters {
 		if lease == nil {
 			break
 		}
-		err := util.DeleteUserAccount(ctx, vcenter, lease.Name, adminMinterUsername, adminMinterPassword)
+		err := util.DeleteUserAccount(ctx, vcenter, lease.Name, accounts.AdminUsername, accounts.AdminPassword)
 		if err != nil {
 			return fmt.Errorf("failed to delete lease account %q: %v", lease.Name, err)
 		}
@@ -398,52 +453,62 @@ func getLeaseExpiration(lease *v1.Lease) time.Time {
 	return lease.CreationTimestamp.Add(time.Hour * time.Duration(leaseExtension))
 }
 
-func (l *LeaseReconciler) userLeasePruner(ctx context.Context) {
-	go func() {
-		for {
-			var pruneLeaseList []*v1.Lease
-			var err error
-			currentTime := time.Now()
-
-			log.Println("checking for expired user or nearly expired user leases")
-			leaseMu.Lock()
-			for _, lease := range leases {
-				if lease.Annotations == nil || lease.DeletionTimestamp != nil {
-					continue
-				}
-				if _, exists := lease.Annotations[SplatBotLeaseOwner]; !exists {
-					continue
-				}
-				if val, exists := lease.Annotations[LeaseDisablePruningLabel]; exists {
-					if val == "true" {
-						log.Printf("pruning of lease %s is disabled.", lease.Name)
-						continue
-					}
-				}
-				expiresAt := getLeaseExpiration(lease)
-				if currentTime.After(expiresAt) {
-					log.Printf("lease %q expired", lease.Name)
-					pruneLeaseList = append(pruneLeaseList, lease)
-				}
-				if currentTime.After(expiresAt.Add(-1 * time.Hour)) {
-					err = l.userReconciler.sendUserMessage(l.userReconciler.client, lease, fmt.Sprintf("your lease will expire at %s. you can renew your lease up to 3 times with `ci lease renew`.", getLeaseExpiration(lease)))
-					if err != nil {
-						log.Printf("failed to send user lease expiration warning: %v", err)
-					}
-				}
+// userLeasePruner deletes expired user leases and warns the owners of leases which are about to expire until
+// ctx is done.
+func (l *LeaseReconciler) userLeasePruner(ctx context.Context) error {
+	for {
+		l.pruneUserLeases(ctx)
+		log.Printf("user lease pruner sleeping for %s", userLeasePruneInterval)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(userLeasePruneInterval):
+		}
+	}
+}
+
+func (l *LeaseReconciler) pruneUserLeases(ctx context.Context) {
+	var pruneLeaseList []*v1.Lease
+	var err error
+	currentTime := time.Now()
+
+	log.Println("checking for expired user or nearly expired user leases")
+	leaseMu.Lock()
+	for _, lease := range leases {
+		if lease.Annotations == nil || lease.DeletionTimestamp != nil {
+			continue
+		}
+		if _, exists := lease.Annotations[SplatBotLeaseOwner]; !exists {
+			continue
+		}
+		if val, exists := lease.Annotations[LeaseDisablePruningLabel]; exists {
+			if val == "true" {
+				log.Printf("pruning of lease %s is disabled.", lease.Name)
+				continue
 			}
-			leaseMu.Unlock()
-			for _, lease := range pruneLeaseList {
-				log.Printf("pruning lease %q", lease.Name)
-				err = l.Delete(ctx, lease)
-				if err != nil {
-					log.Printf("failed to delete lease %q: %v", lease.Name, err)
-				}
+		}
+		expiresAt := getLeaseExpiration(lease)
+		if currentTime.After(expiresAt) {
+			log.Printf("lease %q expired", lease.Name)
+			pruneLeaseList = append(pruneLeaseList, lease)
+		}
+		if currentTime.After(expiresAt.Add(-1 * time.Hour)) {
+			err = l.userReconciler.sendLeaseExpirationWarning(l.userReconciler.client, lease, fmt.Sprintf("your lease will expire at %s. you can renew your lease up to 3 times with `ci lease renew`.", getLeaseExpiration(lease)))
+			if err != nil {
+				log.Printf("failed to send user lease expiration warning: %v", err)
 			}
-			log.Printf("user lease pruner sleeping for 30 minutes")
-			time.Sleep(30 * time.Minute)
 		}
-	}()
+	}
+	leaseMu.Unlock()
+	for _, lease := range pruneLeaseList {
+		log.Printf("pruning lease %q", lease.Name)
+		err = l.Delete(ctx, lease)
+		if err != nil {
+			log.Printf("failed to delete lease %q: %v", lease.Name, err)
+			continue
+		}
+		metrics.LeasePruned()
+	}
 }
 
 func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -458,39 +523,27 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// the leases used by commands are updated by the lease view
 	if lease.DeletionTimestamp == nil {
-		if lease.Annotations != nil {
-			if user, found := lease.Annotations[SplatBotLeaseOwner]; found {
-				log.Printf("found splat-bot lease: %s", lease.Name)
-				err := l.setDropFinalizer(ctx, lease, false)
-				if err != nil {
-					return ctrl.Result{}, fmt.Errorf("failed to set finalizer: %w", err)
-				}
-				leaseMu.Lock()
-				if !hasAnnotation(lease, "temporary-password") ||
-					!hasAnnotation(lease, "temporary-username") {
-					if lease.Status.Phase == v1.PHASE_FULFILLED {
-						l.userReconciler.LeaseChan <- lease
+		if _, found := lease.Annotations[SplatBotLeaseOwner]; found {
+			log.Printf("found splat-bot lease: %s", lease.Name)
+			err := l.setDropFinalizer(ctx, lease, false)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to set finalizer: %w", err)
+			}
+			if !hasAnnotation(lease, "temporary-password") ||
+				!hasAnnotation(lease, "temporary-username") {
+				if lease.Status.Phase == v1.PHASE_FULFILLED {
+					select {
+					case l.userReconciler.LeaseChan <- lease:
+					case <-ctx.Done():
+						return ctrl.Result{}, ctx.Err()
 					}
 				}
-				leaseMu.Unlock()
-				if !hasLabel(lease, network_only_lease) {
-					userLeases[user] = lease
-				}
 			}
 		}
-		leases[lease.Name] = lease
 	} else {
-		leaseMu.Lock()
 		log.Infof("Handling delete of lease %v", lease.Name)
-		delete(leases, lease.Name)
-		for user, userLease := range userLeases {
-			if userLease.Name == lease.Name {
-				delete(userLeases, user)
-				break
-			}
-		}
-		leaseMu.Unlock()
 		if hasFinalizer(lease) {
 			// Check to see if lease is pending.  If so, then just continue since there is nothing to clean up.
 			if !hasLabel(lease, network_only_lease) && (lease.Status.Phase != "Pending" && lease.Status.Phase != "") {
@@ -498,12 +551,12 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				if err != nil {
 					return ctrl.Result{}, fmt.Errorf("failed to get network: %w", err)
 				}
-				err = util.InvokeRecordActionsFromVIPS(ctx, awstypes.ChangeActionDelete, network.Spec.IpAddresses[2:4], fmt.Sprintf("%s.%s", lease.Name, l.domainName))
+				err = util.InvokeRecordActionsFromVIPS(ctx, awstypes.ChangeActionDelete, network.Spec.IpAddresses[2:4], fmt.Sprintf("%s.%s", lease.Name, l.Accounts.DomainName), l.Accounts.HostedZoneID)
 				if err != nil {
 					log.Printf("failed to delete record actions: %v", err)
 				}
 
-				err = cleanUpAccounts(ctx, lease)
+				err = cleanUpAccounts(ctx, l.Accounts, lease)
 				if err != nil {
 					return ctrl.Result{}, fmt.Errorf("failed to cleanup accounts: %w", err)
 				}
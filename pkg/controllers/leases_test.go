@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLeaseViewReconciler(t *testing.T) {
+	defer func() {
+		leases = make(map[string]*v1.Lease)
+		userLeases = make(map[string]*v1.Lease)
+	}()
+
+	scheme := runtime.NewScheme()
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unable to add types to scheme: %v", err)
+	}
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "lease-1",
+			Namespace:   VcmNamespace,
+			Annotations: map[string]string{SplatBotLeaseOwner: "U1"},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lease).Build()
+	reconciler := &leaseViewReconciler{Client: client}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: VcmNamespace, Name: "lease-1"}}
+
+	if _, err := reconciler.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if leases["lease-1"] == nil || userLeases["U1"] == nil {
+		t.Fatalf("expected the lease to be added to the view")
+	}
+
+	if err := client.Delete(context.TODO(), lease); err != nil {
+		t.Fatalf("unable to delete lease: %v", err)
+	}
+	if _, err := reconciler.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if leases["lease-1"] != nil || userLeases["U1"] != nil {
+		t.Errorf("expected the lease to be removed from the view")
+	}
+}
+
+func TestIsLeader(t *testing.T) {
+	defer func() {
+		elected = nil
+	}()
+
+	if !IsLeader() {
+		t.Errorf("expected to be the leader when the controllers aren't started")
+	}
+	ch := make(chan struct{})
+	elected = ch
+	if IsLeader() {
+		t.Errorf("expected not to be the leader before being elected")
+	}
+	close(ch)
+	if !IsLeader() {
+		t.Errorf("expected to be the leader once elected")
+	}
+}
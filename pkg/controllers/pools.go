@@ -13,8 +13,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+const (
+	// PoolCordonActionID the action_id of the button which cordons a pool. The value of the button is the pool name.
+	PoolCordonActionID = "pool-cordon"
+	// PoolUncordonActionID the action_id of the button which uncordons a pool. The value of the button is the pool name.
+	PoolUncordonActionID = "pool-uncordon"
 )
 
 var (
@@ -75,8 +84,13 @@ func GetPoolStatus() (slack.MsgOption, error) {
 		}...))
 
 		rtBlocks = append(rtBlocks, slack.NewRichTextBlock(fmt.Sprintf("pool-status-%s", idx), rtElems...))
-		/*rtBlocks = append(rtBlocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.PlainTextType, ".", false, false), nil, slack.NewAccessory(
-		slack.NewButtonBlockElement(fmt.Sprintf("cordon-%s", idx), "cordon", slack.NewTextBlockObject(slack.PlainTextType, "cordon", false, false)))))*/
+
+		actionID, actionText := PoolCordonActionID, "Cordon"
+		if pool.Spec.NoSchedule {
+			actionID, actionText = PoolUncordonActionID, "Uncordon"
+		}
+		rtBlocks = append(rtBlocks, slack.NewActionBlock(fmt.Sprintf("pool-actions-%s", idx),
+			slack.NewButtonBlockElement(actionID, pool.Name, slack.NewTextBlockObject(slack.PlainTextType, actionText, false, false))))
 		rtBlocks = append(rtBlocks, slack.NewDividerBlock())
 	}
 
@@ -110,9 +124,12 @@ type PoolReconciler struct {
 	ReleaseVersion string
 }
 
+// SetupWithManager adds the reconciler to the manager. Pools are only read, so the reconciler runs on every
+// replica.
 func (l *PoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&v1.Pool{}).
+		WithOptions(controller.Options{NeedLeaderElection: ptr.To(false)}).
 		Complete(l); err != nil {
 		return fmt.Errorf("error setting up controller: %w", err)
 	}
@@ -4,15 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path"
 	"time"
 
 	awstypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	log "github.com/sirupsen/logrus"
 
-	"strings"
-
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	"github.com/slack-go/slack"
@@ -22,6 +20,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 type UserReconciler struct {
@@ -45,13 +44,8 @@ type UserReconciler struct {
 	// ReleaseVersion is the version of current cluster operator release.
 	ReleaseVersion string
 
-	vcentersSlice []string
-
-	adminMinterUsername string
-
-	adminMinterPassword string
-
-	domainName string
+	// Accounts configures the accounts and DNS records created for leases.
+	Accounts config.AccountsConfig
 	// client slack client
 	client util.SlackClientInterface
 }
@@ -69,18 +63,13 @@ func (l *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 	l.client = slackClient
 	l.LeaseChan = make(chan *v1.Lease)
-	vcenters := os.Getenv("ACCOUNT_MINTING_VCENTERS")
-	if vcenters == "" {
+	if len(l.Accounts.VCenters) == 0 {
 		log.Printf("No vCenters set, user leases will not be processed.")
 	}
-	l.vcentersSlice = strings.Split(vcenters, " ")
-	l.adminMinterUsername = os.Getenv("ADMIN_CREDENTIAL_MINTER_USERNAME")
-	l.adminMinterPassword = os.Getenv("ADMIN_CREDENTIAL_MINTER_PASSWORD")
-	l.domainName = os.Getenv("USER_DOMAIN_NAME")
-	go func() {
-		l.Reconcile()
-	}()
-
+	// the user reconciler modifies leases so it only runs on the leader
+	if err := mgr.Add(manager.RunnableFunc(l.Reconcile)); err != nil {
+		return fmt.Errorf("unable to add user reconciler: %v", err)
+	}
 	return nil
 }
 
@@ -102,6 +91,32 @@ func (l *UserReconciler) sendUserMessage(client util.SlackClientInterface, lease
 	return nil
 }
 
+// sendLeaseExpirationWarning sends a message to the owner of a lease with buttons to renew or release their leases.
+func (l *UserReconciler) sendLeaseExpirationWarning(client util.SlackClientInterface, lease *v1.Lease, msg string) error {
+	slackUser := lease.Annotations["splat-bot-owner"]
+	channel, _, _, err := client.OpenConversation(&slack.OpenConversationParameters{
+		Users:    []string{slackUser},
+		ReturnIM: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open conversation: %v", err)
+	}
+
+	renewButton := slack.NewButtonBlockElement(LeaseRenewActionID, lease.Name, slack.NewTextBlockObject(slack.PlainTextType, "Renew", false, false))
+	renewButton.Style = slack.StylePrimary
+	releaseButton := slack.NewButtonBlockElement(LeaseReleaseActionID, lease.Name, slack.NewTextBlockObject(slack.PlainTextType, "Release", false, false))
+	releaseButton.Style = slack.StyleDanger
+
+	_, _, err = client.PostMessage(channel.ID, slack.MsgOptionText(msg, false), slack.MsgOptionBlocks(
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, msg, false, false), nil, nil),
+		slack.NewActionBlock("lease-actions", renewButton, releaseButton),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to post message: %v", err)
+	}
+	return nil
+}
+
 func (l *UserReconciler) sendNetworkLeaseDetails(ctx context.Context, client util.SlackClientInterface, lease *v1.Lease, network *v1.Network) error {
 	var slackUser string
 	var err error
@@ -262,10 +277,18 @@ func (l *UserReconciler) setLeaseAnnotation(ctx context.Context, lease *v1.Lease
 	return l.Client.Update(ctx, lease)
 }
 
-func (l *UserReconciler) requeue(lease *v1.Lease) {
+// requeue reconciles the lease again after a delay, unless ctx is done first.
+func (l *UserReconciler) requeue(ctx context.Context, lease *v1.Lease) {
 	go func() {
-		time.Sleep(2 * time.Second)
-		l.LeaseChan <- lease
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+		select {
+		case l.LeaseChan <- lease:
+		case <-ctx.Done():
+		}
 	}()
 }
 
@@ -286,17 +309,19 @@ func (l *UserReconciler) getNetwork(ctx context.Context, lease *v1.Lease) (*v1.N
 	return nil, fmt.Errorf("no network object found for lease: %s", lease.Name)
 }
 
-func (l *UserReconciler) Reconcile() {
-	ctx := context.Background()
-	//nolint:gosimple
+// Reconcile creates the accounts and DNS records of the leases sent to LeaseChan and sends their details to
+// their owners until ctx is done.
+func (l *UserReconciler) Reconcile(ctx context.Context) error {
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case lease := <-l.LeaseChan:
 			log.Printf("reconciling lease: %s", lease.Name)
 			network, err := l.getNetwork(ctx, lease)
 			if err != nil {
 				log.Printf("unable to get network: %v", err)
-				l.requeue(lease)
+				l.requeue(ctx, lease)
 				continue
 			}
 
@@ -325,39 +350,39 @@ func (l *UserReconciler) Reconcile() {
 
 					if err != nil {
 						log.Printf("requeing .. unable to get lease: %v", err)
-						l.requeue(lease)
+						l.requeue(ctx, lease)
 						continue
 					}
 
 					password, err := util.GetRandomIdentifier(20)
 					if err != nil {
 						log.Printf("unable to generate password: %v", err)
-						l.requeue(lease)
+						l.requeue(ctx, lease)
 						continue
 					}
 
 					err = l.setLeaseAnnotation(ctx, lease, "temporary-password", password)
 					if err != nil {
 						log.Printf("requeing .. unable to set lease password annotation: %v", err)
-						l.requeue(lease)
+						l.requeue(ctx, lease)
 						continue
 					}
 					log.Printf("setting username annotation on %s", lease.Name)
 					err = l.setLeaseAnnotation(ctx, lease, "temporary-username", lease.Name)
 					if err != nil {
 						log.Printf("requeing .. unable to set lease username annotation: %v", err)
-						l.requeue(lease)
+						l.requeue(ctx, lease)
 						continue
 					}
 
 					allOk := true
-					for _, vcenter := range l.vcentersSlice {
+					for _, vcenter := range l.Accounts.VCenters {
 						log.Printf("creating user account %s in %s", lease.Name, vcenter)
 						err = util.CreateUserAccount(ctx,
 							vcenter,
 							"ci.ibmc.devcluster.openshift.com",
-							l.adminMinterUsername,
-							l.adminMinterPassword,
+							l.Accounts.AdminUsername,
+							l.Accounts.AdminPassword,
 							lease.Name,
 							password,
 							"CI")
@@ -371,7 +396,7 @@ func (l *UserReconciler) Reconcile() {
 					if !allOk {
 						continue
 					}
-					err = util.InvokeRecordActionsFromVIPS(ctx, awstypes.ChangeActionUpsert, network.Spec.IpAddresses[2:4], fmt.Sprintf("%s.%s", lease.Name, l.domainName))
+					err = util.InvokeRecordActionsFromVIPS(ctx, awstypes.ChangeActionUpsert, network.Spec.IpAddresses[2:4], fmt.Sprintf("%s.%s", lease.Name, l.Accounts.DomainName), l.Accounts.HostedZoneID)
 					if err != nil {
 						log.Printf("unable to create route53 records: %v", err)
 						_ = l.sendUserMessage(l.client, lease, fmt.Sprintf("unable to create route53 records. you'll need to create them yourself :(. %v", err))
@@ -0,0 +1,113 @@
+package fakeslack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Render returns the content of a message as plain text. Blocks are rendered in place of the text of the message
+// when the message has blocks, the same way Slack shows them.
+func Render(msg Message) string {
+	if len(msg.Blocks.BlockSet) == 0 {
+		return msg.Text
+	}
+	var lines []string
+	for _, block := range msg.Blocks.BlockSet {
+		if line := renderBlock(block); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderBlock(block slack.Block) string {
+	switch b := block.(type) {
+	case *slack.SectionBlock:
+		var parts []string
+		if b.Text != nil {
+			parts = append(parts, b.Text.Text)
+		}
+		for _, field := range b.Fields {
+			parts = append(parts, field.Text)
+		}
+		return strings.Join(parts, "\n")
+	case *slack.HeaderBlock:
+		if b.Text == nil {
+			return ""
+		}
+		return fmt.Sprintf("*%s*", b.Text.Text)
+	case *slack.ContextBlock:
+		var parts []string
+		for _, element := range b.ContextElements.Elements {
+			if text, ok := element.(*slack.TextBlockObject); ok {
+				parts = append(parts, text.Text)
+			}
+		}
+		return strings.Join(parts, " ")
+	case *slack.DividerBlock:
+		return "---"
+	case *slack.ActionBlock:
+		if b.Elements == nil {
+			return ""
+		}
+		var buttons []string
+		for _, element := range b.Elements.ElementSet {
+			if button, ok := element.(*slack.ButtonBlockElement); ok && button.Text != nil {
+				buttons = append(buttons, fmt.Sprintf("[%s]", button.Text.Text))
+			}
+		}
+		return strings.Join(buttons, " ")
+	case *slack.RichTextBlock:
+		var parts []string
+		for _, element := range b.Elements {
+			parts = append(parts, renderRichTextElement(element))
+		}
+		return strings.Join(parts, "")
+	default:
+		return fmt.Sprintf("<%s block>", block.BlockType())
+	}
+}
+
+func renderRichTextElement(element slack.RichTextElement) string {
+	switch e := element.(type) {
+	case *slack.RichTextSection:
+		return renderRichTextSection(e.Elements)
+	case *slack.RichTextQuote:
+		return "> " + renderRichTextSection(e.Elements)
+	case *slack.RichTextPreformatted:
+		return "```" + renderRichTextSection(e.Elements) + "```"
+	case *slack.RichTextList:
+		var items []string
+		for _, item := range e.Elements {
+			items = append(items, fmt.Sprintf("%s• %s", strings.Repeat("  ", e.Indent), renderRichTextElement(item)))
+		}
+		return strings.Join(items, "\n") + "\n"
+	default:
+		return ""
+	}
+}
+
+func renderRichTextSection(elements []slack.RichTextSectionElement) string {
+	var builder strings.Builder
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *slack.RichTextSectionTextElement:
+			builder.WriteString(e.Text)
+		case *slack.RichTextSectionLinkElement:
+			if len(e.Text) > 0 {
+				builder.WriteString(fmt.Sprintf("<%s|%s>", e.URL, e.Text))
+			} else {
+				builder.WriteString(e.URL)
+			}
+		case *slack.RichTextSectionEmojiElement:
+			builder.WriteString(fmt.Sprintf(":%s:", e.Name))
+		case *slack.RichTextSectionUserElement:
+			builder.WriteString(fmt.Sprintf("<@%s>", e.UserID))
+		case *slack.RichTextSectionChannelElement:
+			builder.WriteString(fmt.Sprintf("<#%s>", e.ChannelID))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,400 @@
+package fakeslack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// apiURL the API URL messages are built with. Endpoints which don't start with it are response URLs.
+	apiURL = "https://slack.invalid/api/"
+
+	// firstTimestamp the seconds of the timestamp of the first message posted to any workspace.
+	firstTimestamp = 1700000000
+)
+
+var (
+	_ util.SlackClientInterface = &Workspace{}
+
+	timestampSequence atomic.Int64
+)
+
+// Action is a change made to a message in the workspace.
+type Action string
+
+const (
+	ActionPosted  Action = "posted"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+	ActionReacted Action = "reacted"
+)
+
+// Message is a message in the workspace.
+type Message struct {
+	slack.Msg
+	// EphemeralTo the user an ephemeral message is shown to.
+	EphemeralTo string
+	// ResponseURL the response URL the message was sent to. The response type of these messages isn't known.
+	ResponseURL string
+	// Deleted the message was deleted.
+	Deleted bool
+}
+
+// Workspace is an in-memory Slack workspace. It implements SlackClientInterface as the bot user and records the
+// messages the bot posts, updates and deletes. Channels are created when a message is first posted to them.
+type Workspace struct {
+	// BotUserID the user the workspace posts as.
+	BotUserID string
+
+	mu       sync.Mutex
+	channels map[string]*slack.Channel
+	groups   []slack.UserGroup
+	messages []*Message
+	watchers []func(Action, Message)
+}
+
+// NewWorkspace returns an empty workspace in which botUserID posts.
+func NewWorkspace(botUserID string) *Workspace {
+	return &Workspace{
+		BotUserID: botUserID,
+		channels:  map[string]*slack.Channel{},
+	}
+}
+
+// AddChannel adds a channel to the workspace. Channels with IDs starting with D are direct messages.
+func (w *Workspace) AddChannel(id, name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.channel(id).Name = name
+}
+
+// AddUserGroup adds a user group to the workspace.
+func (w *Workspace) AddUserGroup(group slack.UserGroup) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.groups = append(w.groups, group)
+}
+
+// Watch calls fn whenever a message is posted or changed.
+func (w *Workspace) Watch(fn func(Action, Message)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watchers = append(w.watchers, fn)
+}
+
+// UserMessage posts a message from a user and returns the event Slack would deliver for it. threadTS is empty
+// for messages which aren't in a thread.
+func (w *Workspace) UserMessage(channelID, user, text, threadTS string) slackevents.EventsAPIEvent {
+	w.mu.Lock()
+	channel := w.channel(channelID)
+	msg := &Message{Msg: slack.Msg{
+		Channel:         channelID,
+		User:            user,
+		Text:            text,
+		Timestamp:       w.nextTimestamp(),
+		ThreadTimestamp: threadTS,
+	}}
+	w.messages = append(w.messages, msg)
+	channelType := slack.TYPE_CHANNEL
+	if channel.IsIM {
+		channelType = slack.TYPE_IM
+	}
+	w.mu.Unlock()
+	w.notify(ActionPosted, msg)
+
+	return slackevents.EventsAPIEvent{
+		Type: slackevents.CallbackEvent,
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.Message),
+			Data: &slackevents.MessageEvent{
+				Type:            string(slackevents.Message),
+				Channel:         channelID,
+				ChannelType:     channelType,
+				User:            user,
+				Text:            text,
+				TimeStamp:       msg.Timestamp,
+				ThreadTimeStamp: threadTS,
+			},
+		},
+	}
+}
+
+// Messages returns the messages in a channel, including thread replies and deleted messages, in the order
+// they were posted.
+func (w *Workspace) Messages(channelID string) []Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var messages []Message
+	for _, msg := range w.messages {
+		if msg.Channel == channelID {
+			messages = append(messages, *msg)
+		}
+	}
+	return messages
+}
+
+// Posted returns the messages posted by the bot in every channel in the order they were posted.
+func (w *Workspace) Posted() []Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var messages []Message
+	for _, msg := range w.messages {
+		if msg.User == w.BotUserID {
+			messages = append(messages, *msg)
+		}
+	}
+	return messages
+}
+
+func (w *Workspace) PostEphemeral(channelID string, userID string, options ...slack.MsgOption) (string, error) {
+	_, timestamp, err := w.send(channelID, append([]slack.MsgOption{slack.MsgOptionPostEphemeral(userID)}, options...))
+	return timestamp, err
+}
+
+func (w *Workspace) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	return w.send(channelID, append([]slack.MsgOption{slack.MsgOptionPost()}, options...))
+}
+
+func (w *Workspace) UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	channel, timestamp, err := w.send(channelID, append([]slack.MsgOption{slack.MsgOptionUpdate(timestamp)}, options...))
+	if err != nil {
+		return "", "", "", err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return channel, timestamp, w.find(channelID, timestamp).Text, nil
+}
+
+func (w *Workspace) DeleteMessage(channel, messageTimestamp string) (string, string, error) {
+	return w.send(channel, []slack.MsgOption{slack.MsgOptionDelete(messageTimestamp)})
+}
+
+func (w *Workspace) OpenConversation(params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	if len(params.Users) != 1 {
+		return nil, false, false, errors.New("only conversations with one user are supported")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := "D" + params.Users[0]
+	_, alreadyOpen := w.channels[id]
+	channel := *w.channel(id)
+	return &channel, false, alreadyOpen, nil
+}
+
+func (w *Workspace) GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.find(params.ChannelID, params.Timestamp) == nil {
+		return nil, false, "", errors.New("thread_not_found")
+	}
+	for _, msg := range w.messages {
+		if msg.Channel != params.ChannelID || msg.Deleted || len(msg.EphemeralTo) > 0 {
+			continue
+		}
+		if msg.Timestamp == params.Timestamp || msg.ThreadTimestamp == params.Timestamp {
+			msgs = append(msgs, slack.Message{Msg: msg.Msg})
+		}
+	}
+	return msgs, false, "", nil
+}
+
+func (w *Workspace) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	channel, ok := w.channels[input.ChannelID]
+	if !ok {
+		return nil, errors.New("channel_not_found")
+	}
+	info := *channel
+	return &info, nil
+}
+
+func (w *Workspace) GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]slack.UserGroup{}, w.groups...), nil
+}
+
+func (w *Workspace) GetUserGroupMembers(userGroup string) ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, group := range w.groups {
+		if group.ID == userGroup {
+			return append([]string{}, group.Users...), nil
+		}
+	}
+	return nil, errors.New("no_such_subteam")
+}
+
+func (w *Workspace) AddReaction(name string, item slack.ItemRef) error {
+	w.mu.Lock()
+	msg := w.find(item.Channel, item.Timestamp)
+	if msg == nil {
+		w.mu.Unlock()
+		return errors.New("message_not_found")
+	}
+	for _, reaction := range msg.Reactions {
+		if reaction.Name == name {
+			w.mu.Unlock()
+			return errors.New("already_reacted")
+		}
+	}
+	msg.Reactions = append(msg.Reactions, slack.ItemReaction{Name: name, Count: 1, Users: []string{w.BotUserID}})
+	w.mu.Unlock()
+	w.notify(ActionReacted, msg)
+	return nil
+}
+
+func (w *Workspace) RemoveReaction(name string, item slack.ItemRef) error {
+	w.mu.Lock()
+	msg := w.find(item.Channel, item.Timestamp)
+	if msg == nil {
+		w.mu.Unlock()
+		return errors.New("message_not_found")
+	}
+	var reactions []slack.ItemReaction
+	for _, reaction := range msg.Reactions {
+		if reaction.Name != name {
+			reactions = append(reactions, reaction)
+		}
+	}
+	if len(reactions) == len(msg.Reactions) {
+		w.mu.Unlock()
+		return errors.New("no_reaction")
+	}
+	msg.Reactions = reactions
+	w.mu.Unlock()
+	w.notify(ActionReacted, msg)
+	return nil
+}
+
+// send applies the options the same way the Slack client does and records the result.
+func (w *Workspace) send(channelID string, options []slack.MsgOption) (string, string, error) {
+	endpoint, values, err := slack.UnsafeApplyMsgOptions("", channelID, apiURL, options...)
+	if err != nil {
+		return "", "", err
+	}
+	blocks, err := decodeBlocks(values.Get("blocks"))
+	if err != nil {
+		return "", "", err
+	}
+
+	w.mu.Lock()
+	var action Action
+	var msg *Message
+	switch endpoint {
+	case apiURL + "chat.postMessage", apiURL + "chat.postEphemeral":
+		w.channel(channelID)
+		msg = &Message{Msg: slack.Msg{
+			Channel:         channelID,
+			User:            w.BotUserID,
+			Text:            values.Get("text"),
+			Blocks:          blocks,
+			Timestamp:       w.nextTimestamp(),
+			ThreadTimestamp: values.Get("thread_ts"),
+		}}
+		if endpoint == apiURL+"chat.postEphemeral" {
+			msg.EphemeralTo = values.Get("user")
+		}
+		w.messages = append(w.messages, msg)
+		action = ActionPosted
+	case apiURL + "chat.update", apiURL + "chat.delete":
+		msg = w.find(channelID, values.Get("ts"))
+		if msg == nil || msg.Deleted {
+			w.mu.Unlock()
+			return "", "", errors.New("message_not_found")
+		}
+		if endpoint == apiURL+"chat.delete" {
+			msg.Deleted = true
+			action = ActionDeleted
+		} else {
+			msg.Text = values.Get("text")
+			msg.Blocks = blocks
+			action = ActionUpdated
+		}
+	default:
+		if strings.HasPrefix(endpoint, apiURL) {
+			w.mu.Unlock()
+			return "", "", fmt.Errorf("unsupported endpoint %s", strings.TrimPrefix(endpoint, apiURL))
+		}
+		msg = &Message{
+			Msg: slack.Msg{
+				Channel:   channelID,
+				User:      w.BotUserID,
+				Text:      values.Get("text"),
+				Blocks:    blocks,
+				Timestamp: w.nextTimestamp(),
+			},
+			ResponseURL: endpoint,
+		}
+		w.messages = append(w.messages, msg)
+		action = ActionPosted
+	}
+	w.mu.Unlock()
+	w.notify(action, msg)
+	return msg.Channel, msg.Timestamp, nil
+}
+
+// notify calls the watchers with a copy of the message.
+func (w *Workspace) notify(action Action, msg *Message) {
+	w.mu.Lock()
+	watchers := append([]func(Action, Message){}, w.watchers...)
+	copied := *msg
+	w.mu.Unlock()
+	for _, watcher := range watchers {
+		watcher(action, copied)
+	}
+}
+
+// channel returns the channel with the ID, creating it if it doesn't exist. w.mu must be held.
+func (w *Workspace) channel(id string) *slack.Channel {
+	channel, ok := w.channels[id]
+	if !ok {
+		channel = &slack.Channel{}
+		channel.ID = id
+		channel.Name = id
+		if strings.HasPrefix(id, "D") {
+			channel.IsIM = true
+			channel.User = strings.TrimPrefix(id, "D")
+		}
+		w.channels[id] = channel
+	}
+	return channel
+}
+
+// find returns the message with the timestamp. w.mu must be held.
+func (w *Workspace) find(channelID, timestamp string) *Message {
+	for _, msg := range w.messages {
+		if msg.Channel == channelID && msg.Timestamp == timestamp {
+			return msg
+		}
+	}
+	return nil
+}
+
+// nextTimestamp returns a timestamp which is unique across workspaces, so that messages in different workspaces
+// aren't mistaken for redeliveries of each other.
+func (w *Workspace) nextTimestamp() string {
+	sequence := timestampSequence.Add(1)
+	return fmt.Sprintf("%d.%06d", firstTimestamp+sequence/1000000, sequence%1000000)
+}
+
+func decodeBlocks(encoded string) (slack.Blocks, error) {
+	var blocks slack.Blocks
+	if len(encoded) == 0 {
+		return blocks, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), &blocks); err != nil {
+		return blocks, fmt.Errorf("invalid blocks: %v", err)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,115 @@
+package fakeslack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestWorkspaceMessages(t *testing.T) {
+	workspace := NewWorkspace("UBOT")
+	var actions []Action
+	workspace.Watch(func(action Action, msg Message) {
+		actions = append(actions, action)
+	})
+
+	evt := workspace.UserMessage("C1", "U1", "hello", "")
+	userMsg := evt.InnerEvent.Data.(*slackevents.MessageEvent)
+
+	channel, timestamp, err := workspace.PostMessage("C1", slack.MsgOptionText("reply", false), slack.MsgOptionTS(userMsg.TimeStamp))
+	if err != nil || channel != "C1" {
+		t.Fatalf("unexpected post result %s: %v", channel, err)
+	}
+	if _, err := workspace.PostEphemeral("C1", "U1", slack.MsgOptionText("only for you", false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, text, err := workspace.UpdateMessage("C1", timestamp, slack.MsgOptionText("edited reply", false)); err != nil || text != "edited reply" {
+		t.Fatalf("unexpected update result %q: %v", text, err)
+	}
+	if err := workspace.AddReaction("eyes", slack.NewRefToMessage("C1", userMsg.TimeStamp)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := workspace.AddReaction("eyes", slack.NewRefToMessage("C1", userMsg.TimeStamp)); err == nil {
+		t.Errorf("expected reacting twice to fail")
+	}
+
+	replies, _, _, err := workspace.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: "C1", Timestamp: userMsg.TimeStamp})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(replies) != 2 || replies[1].Text != "edited reply" {
+		t.Errorf("expected the thread without the ephemeral message: %+v", replies)
+	}
+
+	if _, _, err := workspace.DeleteMessage("C1", timestamp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := workspace.DeleteMessage("C1", timestamp); err == nil {
+		t.Errorf("expected deleting a deleted message to fail")
+	}
+
+	posted := workspace.Posted()
+	if len(posted) != 2 || !posted[0].Deleted || posted[1].EphemeralTo != "U1" {
+		t.Errorf("unexpected posted messages: %+v", posted)
+	}
+	expected := "posted,posted,posted,updated,reacted,deleted"
+	var got []string
+	for _, action := range actions {
+		got = append(got, string(action))
+	}
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(got, ","))
+	}
+}
+
+func TestWorkspaceConversations(t *testing.T) {
+	workspace := NewWorkspace("UBOT")
+	workspace.AddChannel("C1", "vmware")
+	workspace.AddUserGroup(slack.UserGroup{ID: "S1", Handle: "splat-team", Users: []string{"U1"}})
+
+	info, err := workspace.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: "C1"})
+	if err != nil || info.Name != "vmware" {
+		t.Errorf("unexpected channel %+v: %v", info, err)
+	}
+	if _, err := workspace.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: "C2"}); err == nil {
+		t.Errorf("expected unknown channels to be reported")
+	}
+
+	dm, _, alreadyOpen, err := workspace.OpenConversation(&slack.OpenConversationParameters{Users: []string{"U1"}})
+	if err != nil || !dm.IsIM || alreadyOpen {
+		t.Fatalf("unexpected conversation %+v: %v", dm, err)
+	}
+	evt := workspace.UserMessage(dm.ID, "U1", "help", "")
+	if evt.InnerEvent.Data.(*slackevents.MessageEvent).ChannelType != slack.TYPE_IM {
+		t.Errorf("expected messages in a DM to have the im channel type")
+	}
+
+	members, err := workspace.GetUserGroupMembers("S1")
+	if err != nil || len(members) != 1 {
+		t.Errorf("unexpected members %v: %v", members, err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	workspace := NewWorkspace("UBOT")
+	button := slack.NewButtonBlockElement("renew", "lease-1", slack.NewTextBlockObject(slack.PlainTextType, "Renew", false, false))
+	_, _, err := workspace.PostMessage("C1", slack.MsgOptionText("fallback", false), slack.MsgOptionBlocks(
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "*lease* expires soon", false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewActionBlock("actions", button),
+		slack.NewRichTextBlock("rt", slack.NewRichTextSection(
+			slack.NewRichTextSectionEmojiElement("large_green_circle", 0, nil),
+			slack.NewRichTextSectionTextElement(" pool-1", nil),
+		)),
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "*lease* expires soon\n---\n[Renew]\n:large_green_circle: pool-1"
+	if rendered := Render(workspace.Posted()[0]); rendered != expected {
+		t.Errorf("expected %q, got %q", expected, rendered)
+	}
+}
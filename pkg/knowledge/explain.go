@@ -0,0 +1,322 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge/platforms"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	ExplainActionID = "knowledge-explain"
+	// maxExplainedAssets the number of assets explained in a response, which keeps it within Slack's block limit.
+	maxExplainedAssets = 10
+	// maxButtonValue the length Slack allows for the value of a button.
+	maxButtonValue = 2000
+)
+
+var explainCommands = []string{"knowledge", "explain"}
+
+// explainButton returns a button which explains why a question was answered.
+func explainButton(question string) *slack.ButtonBlockElement {
+	if runes := []rune(question); len(runes) > maxButtonValue {
+		question = string(runes[:maxButtonValue])
+	}
+	return slack.NewButtonBlockElement(ExplainActionID, question,
+		slack.NewTextBlockObject(slack.PlainTextType, "Why did I get this?", false, false))
+}
+
+// messageArgs splits a message into the words it's matched on, the same way the knowledge catch-all does.
+func messageArgs(message string) []string {
+	args := strings.Split(strings.ReplaceAll(message, "\n", " "), " ")
+	if util.ContainsBotMention(message) {
+		args = args[1:]
+	}
+	return args
+}
+
+// cloneTokenMatch copies a condition so it can be evaluated without changing the loaded asset.
+func cloneTokenMatch(match data.TokenMatch) data.TokenMatch {
+	terms := make([]data.TokenMatch, len(match.Terms))
+	for idx, term := range match.Terms {
+		terms[idx] = cloneTokenMatch(term)
+	}
+	match.Terms = terms
+	return match
+}
+
+// explainTokenMatch describes how each condition of a match tree was evaluated against the tokens of a message.
+// contextTerms is the number of terms at the end of the top of the tree which were added from the path of the asset.
+func explainTokenMatch(match data.TokenMatch, tokens map[string]string, pathExpr string, contextTerms int) []string {
+	match = cloneTokenMatch(match)
+	if match.CompiledExpr != nil {
+		return explainExpr(&match, tokens, pathExpr)
+	}
+	return explainTerm(&match, tokens, 0, "", explainTerms(contextTerms, len(match.Terms)))
+}
+
+// assetMatchTree describes how each condition of an asset, including those added from its path, was evaluated
+// against the tokens of a message.
+func assetMatchTree(asset data.KnowledgeAsset, tokens map[string]string) []string {
+	return explainTokenMatch(asset.On, tokens, platforms.GetPathContextExpr(asset.Path), len(platforms.GetPathContextTerms(asset.Path)))
+}
+
+// logMatchTree logs the match tree of an asset when debugMatching is set.
+func logMatchTree(asset data.KnowledgeAsset, tokens map[string]string) {
+	words := make([]string, 0, len(tokens))
+	for token := range tokens {
+		words = append(words, token)
+	}
+	sort.Strings(words)
+	log.Infof("matching %s against %s:\n%s", asset.Name, strings.Join(words, ", "), strings.Join(assetMatchTree(asset, tokens), "\n"))
+}
+
+// explainTerms labels the terms added from the path of an asset.
+func explainTerms(contextTerms, terms int) []string {
+	labels := make([]string, terms)
+	for idx := max(terms-contextTerms, 0); idx < terms; idx++ {
+		labels[idx] = "  <- added from the path"
+	}
+	return labels
+}
+
+func explainTerm(match *data.TokenMatch, tokens map[string]string, depth int, label string, termLabels []string) []string {
+	mark := "[ ]"
+	if isTokenMatch(match, tokens) {
+		mark = "[x]"
+	}
+	matchType := "and"
+	if match.Type == "or" {
+		matchType = "or"
+	}
+	line := fmt.Sprintf("%s%s %s", strings.Repeat("    ", depth), mark, matchType)
+	if len(match.Tokens) > 0 {
+		var found, missing []string
+		for _, token := range match.Tokens {
+			if _, exists := tokens[strings.ToLower(token)]; exists {
+				found = append(found, token)
+			} else {
+				missing = append(missing, token)
+			}
+		}
+		quantifier := "all of"
+		if matchType == "or" {
+			quantifier = "any of"
+		}
+		line = fmt.Sprintf("%s: %s %s", line, quantifier, strings.Join(match.Tokens, ", "))
+		if len(found) > 0 {
+			line = fmt.Sprintf("%s; found %s", line, strings.Join(found, ", "))
+		}
+		if len(missing) > 0 {
+			line = fmt.Sprintf("%s; missing %s", line, strings.Join(missing, ", "))
+		}
+	}
+	if len(match.Terms) > 0 {
+		if matchType == "or" {
+			line += fmt.Sprintf("; any of %d terms", len(match.Terms))
+		} else {
+			line += fmt.Sprintf("; all of %d terms", len(match.Terms))
+		}
+	}
+	lines := []string{line + label}
+	for idx := range match.Terms {
+		termLabel := ""
+		if idx < len(termLabels) {
+			termLabel = termLabels[idx]
+		}
+		lines = append(lines, explainTerm(&match.Terms[idx], tokens, depth+1, termLabel, nil)...)
+	}
+	return lines
+}
+
+// explainExpr describes the result of an expression and the tokens it names which are in the message.
+func explainExpr(match *data.TokenMatch, tokens map[string]string, pathExpr string) []string {
+	result, err := expr.Run(match.CompiledExpr, map[string]interface{}{"tokens": tokens})
+	mark := "[ ]"
+	if satisfied, ok := result.(bool); ok && satisfied && err == nil {
+		mark = "[x]"
+	}
+	lines := []string{fmt.Sprintf("%s expr: %s", mark, match.Expr)}
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("    error: %v", err))
+	}
+	if len(pathExpr) > 0 {
+		lines = append(lines, fmt.Sprintf("    added from the path: %s", pathExpr))
+	}
+	if node := match.CompiledExpr.Node(); node != nil {
+		scorer := &exprScorer{tokens: tokens, seen: map[string]bool{}}
+		ast.Walk(&node, scorer)
+		var found []string
+		for token := range scorer.seen {
+			found = append(found, token)
+		}
+		sort.Strings(found)
+		if len(found) > 0 {
+			lines = append(lines, fmt.Sprintf("    found %s", strings.Join(found, ", ")))
+		}
+	}
+	return lines
+}
+
+// explainAsset describes how an asset was evaluated against a message and whether it was answered.
+func explainAsset(evaluation assetEvaluation, answered map[string]bool, dir string) string {
+	asset := evaluation.asset
+	header := fmt.Sprintf("*%s*", asset.Name)
+	if len(asset.Path) > 0 {
+		path := asset.Path
+		if rel, err := filepath.Rel(dir, asset.Path); err == nil && len(dir) > 0 {
+			path = rel
+		}
+		header = fmt.Sprintf("%s `%s`", header, path)
+	}
+
+	var status []string
+	switch {
+	case len(evaluation.skipped) > 0:
+		status = append(status, fmt.Sprintf(":fast_forward: skipped, %s", evaluation.skipped))
+	case evaluation.matched && answered[asset.Name]:
+		status = append(status, fmt.Sprintf(":white_check_mark: matched with specificity %d and answered", evaluation.specificity))
+	case evaluation.matched:
+		status = append(status, fmt.Sprintf(":white_check_mark: matched with specificity %d, but other assets ranked higher", evaluation.specificity))
+	default:
+		status = append(status, ":x: didn't match")
+	}
+	if asset.Priority != 0 {
+		status = append(status, fmt.Sprintf("priority %d", asset.Priority))
+	}
+	if asset.Exclusive {
+		status = append(status, "exclusive")
+	}
+	lines := []string{header, strings.Join(status, ", ")}
+	if len(evaluation.contextTokens) > 0 {
+		lines = append(lines, fmt.Sprintf("channel context added %s", strings.Join(evaluation.contextTokens, ", ")))
+	}
+	if len(evaluation.skipped) == 0 {
+		lines = append(lines, "```\n"+strings.Join(assetMatchTree(asset, evaluation.tokens), "\n")+"\n```")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// explain runs a message through the assets and describes the match tree of the assets which matched, or of the
+// named asset. The message is evaluated as if it was sent to the channel, outside of a thread.
+func explain(message, channel, name string) ([]slack.MsgOption, error) {
+	args := messageArgs(message)
+	evaluations, err := evaluateAssets(args, &slackevents.MessageEvent{Channel: channel, Text: message})
+	if err != nil {
+		return nil, err
+	}
+	assetsMu.RLock()
+	dir := knowledgeDir
+	assetsMu.RUnlock()
+
+	var matches []rankedMatch
+	for _, evaluation := range evaluations {
+		if evaluation.matched {
+			matches = append(matches, rankedMatch{asset: evaluation.asset, specificity: evaluation.specificity})
+		}
+	}
+	answered := map[string]bool{}
+	var answeredNames []string
+	for _, match := range rankMatches(matches, maxAnswers) {
+		answered[match.asset.Name] = true
+		answeredNames = append(answeredNames, match.asset.Name)
+	}
+
+	var explained []assetEvaluation
+	found := false
+	for _, evaluation := range evaluations {
+		if len(name) > 0 {
+			if strings.EqualFold(evaluation.asset.Name, name) {
+				explained = append(explained, evaluation)
+				found = true
+			}
+		} else if evaluation.matched {
+			explained = append(explained, evaluation)
+		}
+	}
+	if len(name) > 0 && !found {
+		return util.StringToBlock(fmt.Sprintf("no knowledge asset is named %q", name), false), nil
+	}
+
+	summary := fmt.Sprintf("*Explaining* `%s`\ntokens: %s\n", message, strings.Join(util.NormalizeTokensToSlice(args), ", "))
+	if len(answeredNames) > 0 {
+		summary += fmt.Sprintf("%d of %d assets matched and %s answered", len(matches), len(evaluations), strings.Join(answeredNames, ", "))
+	} else {
+		summary += fmt.Sprintf("none of the %d assets matched, so the message isn't answered", len(evaluations))
+	}
+	blocks := sectionBlocks(summary)
+	for idx, evaluation := range explained {
+		assetBlocks := append([]slack.Block{slack.NewDividerBlock()}, sectionBlocks(explainAsset(evaluation, answered, dir))...)
+		// room is kept for the notice below and the hint which follows
+		if idx == maxExplainedAssets || len(blocks)+len(assetBlocks) > util.MAX_MESSAGE_BLOCKS-2 {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("%d more assets matched. name an asset to explain it", len(explained)-idx), false, false)))
+			break
+		}
+		blocks = append(blocks, assetBlocks...)
+	}
+	if len(name) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("to see why an asset didn't match, name it: `knowledge explain \"%s\" \"<asset name>\"`", message), false, false)))
+	}
+	return []slack.MsgOption{
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(summary, false),
+		slack.MsgOptionDisableLinkUnfurl(),
+	}, nil
+}
+
+// sectionBlocks returns the text in as many sections as it needs. Explanations are also sent to the response URL
+// of the explain button, which isn't split by util.SplitResponse.
+func sectionBlocks(text string) []slack.Block {
+	var blocks []slack.Block
+	for _, chunk := range util.SplitText(text, util.MAX_SECTION_TEXT_LENGTH) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+	}
+	return blocks
+}
+
+var KnowledgeExplainAttributes = data.Attributes{
+	Commands:            explainCommands,
+	RequireMention:      true,
+	AllowNonSplatUsers:  true,
+	ResponseIsEphemeral: true,
+	HelpArea:            "knowledge",
+	Description:         "explains which knowledge assets match a message and why. the message is matched as if it was sent to this channel",
+	Arguments: []data.Argument{
+		{Name: "message", Required: true, Help: "the message to explain, in quotes"},
+		{Name: "asset", Help: "the name of an asset to explain even if it doesn't match, in quotes"},
+	},
+	ShouldMatch: []string{
+		"knowledge explain \"how do i install on vsphere\"",
+		"knowledge explain \"how do i install on vsphere\" \"vSphere install\"",
+	},
+	ShouldntMatch: []string{
+		"knowledge lint",
+		"how do i explain my knowledge",
+	},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		parsed := data.GetParsedArgs(ctx)
+		return explain(parsed.String("message"), evt.Channel, parsed.String("asset"))
+	},
+}
+
+// ExplainActionAttributes explains why the question a knowledge response answered matched, to the user who asked.
+var ExplainActionAttributes = data.ActionAttributes{
+	ActionID:           ExplainActionID,
+	AllowNonSplatUsers: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		return explain(action.Value, interaction.Channel.ID, "")
+	},
+}
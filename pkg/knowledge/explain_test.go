@@ -0,0 +1,187 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestExplain(t *testing.T) {
+	restoreAssets(t)
+	previousClient := slackClient
+	slackClient = &util.StubInterface{}
+	t.Cleanup(func() { slackClient = previousClient })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"ufo.yaml": `name: ufo
+markdown: spacecraft are off topic
+on:
+  type: or
+  tokens: [ufo, spacecraft]
+`,
+		"expression.yaml": `name: expression
+markdown: repairs are handled elsewhere
+on:
+  expr: containsAll(tokens, ["repair", "ufo"])
+`,
+		filepath.Join("vmware", "install", "template.yaml"): `name: vSphere template
+markdown: templates
+channel_context:
+  context_path: vmware
+  channels: [vmware]
+on:
+  tokens: [template]
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := loadKnowledgeEntries(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		message  string
+		channel  string
+		asset    string
+		expected []string
+	}{
+		{
+			name:    "matched assets",
+			message: "my ufo needs repair",
+			channel: "random",
+			expected: []string{
+				"2 of 3 assets matched and expression answered",
+				"*expression* `expression.yaml`\n:white_check_mark: matched with specificity 2 and answered",
+				"[x] expr: containsAll(tokens, ",
+				"found repair, ufo",
+				"*ufo* `ufo.yaml`\n:white_check_mark: matched with specificity 1, but other assets ranked higher",
+				"[x] or: any of ufo, spacecraft; found ufo; missing spacecraft",
+			},
+		},
+		{
+			name:    "named asset with context terms",
+			message: "upload a template",
+			channel: "vmware",
+			asset:   "vsphere TEMPLATE",
+			expected: []string{
+				"none of the 3 assets matched",
+				":x: didn't match",
+				"channel context added vsphere, vmware, vcenter",
+				"[ ] and: all of template; found template; all of 2 terms",
+				"    [x] or: any of vsphere, vmware, vcenter; found vsphere, vmware, vcenter  <- added from the path",
+				"    [ ] or: any of install, installation, ipi, upi, install-config; missing install",
+			},
+		},
+		{
+			name:     "unknown asset",
+			message:  "my ufo",
+			channel:  "random",
+			asset:    "missing",
+			expected: []string{"no knowledge asset is named"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response, err := explain(tc.message, tc.channel, tc.asset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", response...)
+			blocks := unescapeJSON.Replace(values.Get("blocks") + values.Get("text"))
+			blocks = strings.ReplaceAll(blocks, "\\n", "\n")
+			for _, expected := range tc.expected {
+				if !strings.Contains(blocks, expected) {
+					t.Errorf("expected %q in the explanation:\n%s", expected, blocks)
+				}
+			}
+		})
+	}
+
+	for _, asset := range currentAssets() {
+		if asset.On.Satisfied {
+			t.Errorf("expected explaining not to change the conditions of %s", asset.Name)
+		}
+	}
+
+	parsed := data.NewParsedArgs()
+	parsed.Set("message", "my car", true)
+	parsed.Set("asset", "ufo", true)
+	explained, err := KnowledgeExplainAttributes.Callback(data.WithParsedArgs(context.Background(), parsed), nil, &slackevents.MessageEvent{Channel: "random"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", explained...)
+	if blocks := values.Get("blocks"); !strings.Contains(blocks, "my car") || !strings.Contains(blocks, "*ufo*") {
+		t.Errorf("expected the message and the asset of the parsed arguments to be explained, got %s", blocks)
+	}
+
+	response := knowledgeResponse(context.Background(), []rankedMatch{{asset: data.KnowledgeAsset{Name: "ufo", MarkdownPrompt: "off topic"}}}, "did you see a ufo")
+	_, values, _ = slack.UnsafeApplyMsgOptions("", "", "", response...)
+	if blocks := values.Get("blocks"); !strings.Contains(blocks, ExplainActionID) || !strings.Contains(blocks, "did you see a ufo") {
+		t.Errorf("expected the response to explain the question, got %s", blocks)
+	}
+}
+
+func TestExplainLongTree(t *testing.T) {
+	restoreAssets(t)
+	previousClient := slackClient
+	slackClient = &util.StubInterface{}
+	t.Cleanup(func() { slackClient = previousClient })
+
+	var tokens []string
+	for idx := 0; idx < 500; idx++ {
+		tokens = append(tokens, fmt.Sprintf("spacecraft%d", idx))
+	}
+	dir := t.TempDir()
+	asset := fmt.Sprintf("name: ufo\nmarkdown: spacecraft are off topic\non:\n  type: or\n  tokens: [ufo, %s]\n", strings.Join(tokens, ", "))
+	if err := os.WriteFile(filepath.Join(dir, "ufo.yaml"), []byte(asset), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := loadKnowledgeEntries(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the explain button responds through the response URL, which doesn't split sections which are too long
+	response, err := ExplainActionAttributes.Callback(context.Background(), nil,
+		&slack.InteractionCallback{Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "random"}}}},
+		&slack.BlockAction{Value: "did you see a ufo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", response...)
+	var blocks slack.Blocks
+	if err := json.Unmarshal([]byte(values.Get("blocks")), &blocks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sections := 0
+	for _, block := range blocks.BlockSet {
+		if section, ok := block.(*slack.SectionBlock); ok {
+			sections++
+			if len(section.Text.Text) > util.MAX_SECTION_TEXT_LENGTH {
+				t.Errorf("expected sections of at most %d characters, got %d", util.MAX_SECTION_TEXT_LENGTH, len(section.Text.Text))
+			}
+		}
+	}
+	if sections < 3 || len(blocks.BlockSet) > util.MAX_MESSAGE_BLOCKS {
+		t.Errorf("expected the match tree to be split into sections, got %d sections in %d blocks", sections, len(blocks.BlockSet))
+	}
+}
@@ -0,0 +1,261 @@
+package knowledge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/configmap"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	FeedbackActionID     = "knowledge-feedback"
+	feedbackConfigMapKey = "feedback.jsonl"
+)
+
+// feedbackRatings the ratings which can be given to an answer and the labels of their buttons.
+var feedbackRatings = []struct {
+	rating string
+	label  string
+}{
+	{rating: data.KnowledgeFeedbackHelpful, label: ":+1: Helpful"},
+	{rating: data.KnowledgeFeedbackNotHelpful, label: ":-1: Not helpful"},
+	{rating: data.KnowledgeFeedbackWrongTopic, label: "Wrong topic"},
+}
+
+// feedbackStore stores ratings of knowledge answers.
+type feedbackStore interface {
+	// Record stores a rating.
+	Record(ctx context.Context, feedback data.KnowledgeFeedback) error
+	// List returns the ratings given at or after since, oldest first.
+	List(ctx context.Context, since time.Time) ([]data.KnowledgeFeedback, error)
+}
+
+// answerFeedback the store ratings are recorded in. Ratings are kept in memory unless a file or ConfigMap is configured.
+var answerFeedback feedbackStore = newMemoryFeedback()
+
+// ratedSince returns the ratings given at or after since.
+func ratedSince(records []data.KnowledgeFeedback, since time.Time) []data.KnowledgeFeedback {
+	var selected []data.KnowledgeFeedback
+	for _, record := range records {
+		if !record.Time.Before(since) {
+			selected = append(selected, record)
+		}
+	}
+	return selected
+}
+
+// memoryFeedback holds ratings in memory. The ratings are lost on restart and aren't shared between replicas.
+type memoryFeedback struct {
+	mu      sync.Mutex
+	records []data.KnowledgeFeedback
+}
+
+func newMemoryFeedback() *memoryFeedback {
+	return &memoryFeedback{}
+}
+
+func (s *memoryFeedback) Record(ctx context.Context, feedback data.KnowledgeFeedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, feedback)
+	return nil
+}
+
+func (s *memoryFeedback) List(ctx context.Context, since time.Time) ([]data.KnowledgeFeedback, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ratedSince(s.records, since), nil
+}
+
+// fileFeedback appends ratings to a JSONL file, one rating per line.
+type fileFeedback struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newFileFeedback(path string) *fileFeedback {
+	return &fileFeedback{path: path}
+}
+
+func (s *fileFeedback) Record(ctx context.Context, feedback data.KnowledgeFeedback) error {
+	line, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("unable to marshal feedback: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %v", s.path, err)
+	}
+	defer file.Close()
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("unable to write to %s: %v", s.path, err)
+	}
+	return nil
+}
+
+func (s *fileFeedback) List(ctx context.Context, since time.Time) ([]data.KnowledgeFeedback, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %v", s.path, err)
+	}
+	defer file.Close()
+
+	var records []data.KnowledgeFeedback
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record data.KnowledgeFeedback
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			log.Warnf("skipping malformed feedback in %s: %v", s.path, err)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", s.path, err)
+	}
+	return ratedSince(records, since), nil
+}
+
+// configMapFeedback stores the most recent ratings in a ConfigMap as JSONL, so they're shared between replicas
+// and kept across restarts.
+type configMapFeedback struct {
+	records *configmap.JSONL[data.KnowledgeFeedback]
+}
+
+// newConfigMapFeedback returns a store which keeps ratings in the ConfigMap namespace/name. The ConfigMap is
+// created when the first answer is rated.
+func newConfigMapFeedback(namespace, name string, maxRecords int) (*configMapFeedback, error) {
+	store, err := configmap.NewStore(namespace, name, feedbackConfigMapKey)
+	if err != nil {
+		return nil, err
+	}
+	return &configMapFeedback{records: configmap.NewJSONL[data.KnowledgeFeedback](store, maxRecords)}, nil
+}
+
+func newConfigMapFeedbackWithClient(k8sclient client.Client, namespace, name string, maxRecords int) *configMapFeedback {
+	store := configmap.NewStoreWithClient(k8sclient, namespace, name, feedbackConfigMapKey)
+	return &configMapFeedback{records: configmap.NewJSONL[data.KnowledgeFeedback](store, maxRecords)}
+}
+
+// Record stores a rating with the rated message shortened as it's quoted, so the ratings the ConfigMap keeps
+// fit in it.
+func (s *configMapFeedback) Record(ctx context.Context, feedback data.KnowledgeFeedback) error {
+	feedback.Message = shortenMessage(feedback.Message)
+	return s.records.Append(ctx, feedback)
+}
+
+func (s *configMapFeedback) List(ctx context.Context, since time.Time) ([]data.KnowledgeFeedback, error) {
+	records, err := s.records.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ratedSince(records, since), nil
+}
+
+// feedbackValue identifies the answer a feedback button rates. It's the value of the button.
+type feedbackValue struct {
+	Assets  []string `json:"assets"`
+	Message string   `json:"message"`
+}
+
+// encodeFeedbackValue encodes the assets which answered a message as the value of a button. The message is
+// shortened until the value fits in a button.
+func encodeFeedbackValue(assets []string, message string) (string, error) {
+	runes := []rune(message)
+	for {
+		value, err := json.Marshal(feedbackValue{Assets: assets, Message: string(runes)})
+		if err != nil {
+			return "", err
+		}
+		if len(value) <= maxButtonValue {
+			return string(value), nil
+		}
+		if len(runes) == 0 {
+			return "", fmt.Errorf("the names of the assets don't fit in a button")
+		}
+		runes = runes[:len(runes)*3/4]
+	}
+}
+
+// responseActions returns the buttons which rate an answer of assets to a question and explain why it was answered.
+// There are no buttons if there's no question.
+func responseActions(question string, assets []string) []slack.Block {
+	if len(strings.TrimSpace(question)) == 0 {
+		return nil
+	}
+	var elements []slack.BlockElement
+	if value, err := encodeFeedbackValue(assets, question); err == nil {
+		for _, rating := range feedbackRatings {
+			elements = append(elements, slack.NewButtonBlockElement(FeedbackActionID+":"+rating.rating, value,
+				slack.NewTextBlockObject(slack.PlainTextType, rating.label, true, false)))
+		}
+	} else {
+		log.Warnf("leaving the feedback buttons out of the answer of %s: %v", strings.Join(assets, ", "), err)
+	}
+	elements = append(elements, explainButton(question))
+	return []slack.Block{slack.NewActionBlock("", elements...)}
+}
+
+// recordFeedback records a rating of the answer of each asset to a message.
+func recordFeedback(ctx context.Context, rating string, value feedbackValue, user, channel string) error {
+	now := time.Now()
+	for _, asset := range value.Assets {
+		err := answerFeedback.Record(ctx, data.KnowledgeFeedback{
+			Time:    now,
+			Asset:   asset,
+			Rating:  rating,
+			User:    user,
+			Channel: channel,
+			Message: value.Message,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to record feedback for %s: %v", asset, err)
+		}
+		metrics.KnowledgeFeedback(asset, rating)
+	}
+	return nil
+}
+
+// FeedbackActionAttributes records a rating of a knowledge answer. The rating follows the action ID after a colon.
+var FeedbackActionAttributes = data.ActionAttributes{
+	ActionID:           FeedbackActionID,
+	AllowNonSplatUsers: true,
+	Callback: func(ctx context.Context, client util.SlackClientInterface, interaction *slack.InteractionCallback, action *slack.BlockAction) ([]slack.MsgOption, error) {
+		_, rating, _ := strings.Cut(action.ActionID, ":")
+		known := false
+		for _, feedbackRating := range feedbackRatings {
+			known = known || feedbackRating.rating == rating
+		}
+		var value feedbackValue
+		if err := json.Unmarshal([]byte(action.Value), &value); !known || err != nil {
+			return nil, fmt.Errorf("invalid feedback %s: %s", action.ActionID, action.Value)
+		}
+		if err := recordFeedback(ctx, rating, value, interaction.User.ID, interaction.Channel.ID); err != nil {
+			return util.StringToBlock("Your feedback couldn't be recorded. Please try again later.", false), err
+		}
+		return util.StringToBlock("Thanks for the feedback! It helps improve the answers.", false), nil
+	},
+}
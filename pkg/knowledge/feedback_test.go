@@ -0,0 +1,131 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+func testFeedbackStore(t *testing.T, store feedbackStore) {
+	ctx := context.TODO()
+	now := time.Now()
+	for _, record := range []data.KnowledgeFeedback{
+		{Time: now.Add(-48 * time.Hour), Asset: "ufo", Rating: data.KnowledgeFeedbackHelpful, User: "U1", Message: "did you see a ufo"},
+		{Time: now.Add(-time.Hour), Asset: "ufo", Rating: data.KnowledgeFeedbackWrongTopic, User: "U2", Message: "ufo sighting"},
+		{Time: now, Asset: "car", Rating: data.KnowledgeFeedbackNotHelpful, User: "U1", Message: "fix my car"},
+	} {
+		if err := store.Record(ctx, record); err != nil {
+			t.Fatalf("unable to record: %v", err)
+		}
+	}
+
+	records, err := store.List(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("unable to list: %v", err)
+	}
+	if len(records) != 2 || records[0].Message != "ufo sighting" || records[1].Asset != "car" {
+		t.Errorf("expected the ratings of the last day, oldest first: %+v", records)
+	}
+}
+
+func TestFeedbackStores(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		testFeedbackStore(t, newMemoryFeedback())
+	})
+	t.Run("file", func(t *testing.T) {
+		testFeedbackStore(t, newFileFeedback(filepath.Join(t.TempDir(), "feedback.jsonl")))
+	})
+	t.Run("configmap", func(t *testing.T) {
+		testFeedbackStore(t, newConfigMapFeedbackWithClient(fake.NewClientBuilder().Build(), "splat", "feedback", 1000))
+
+		// older ratings are dropped once the ConfigMap is full
+		store := newConfigMapFeedbackWithClient(fake.NewClientBuilder().Build(), "splat", "feedback", 1)
+		for _, asset := range []string{"ufo", "car"} {
+			if err := store.Record(context.TODO(), data.KnowledgeFeedback{Time: time.Now(), Asset: asset}); err != nil {
+				t.Fatalf("unable to record: %v", err)
+			}
+		}
+		records, err := store.List(context.TODO(), time.Time{})
+		if err != nil {
+			t.Fatalf("unable to list: %v", err)
+		}
+		if len(records) != 1 || records[0].Asset != "car" {
+			t.Errorf("expected the oldest rating to be dropped: %+v", records)
+		}
+
+		// long messages are shortened so the ratings fit in the ConfigMap
+		message := "did you see a ufo " + strings.Repeat("really ", 500)
+		if err := store.Record(context.TODO(), data.KnowledgeFeedback{Time: time.Now(), Asset: "ufo", Message: message}); err != nil {
+			t.Fatalf("unable to record: %v", err)
+		}
+		records, err = store.List(context.TODO(), time.Time{})
+		if err != nil {
+			t.Fatalf("unable to list: %v", err)
+		}
+		if len(records) != 1 || records[0].Message != shortenMessage(message) || len([]rune(records[0].Message)) != maxQuotedMessage {
+			t.Errorf("expected the message to be shortened: %+v", records)
+		}
+	})
+}
+
+func TestFeedbackAction(t *testing.T) {
+	previous := answerFeedback
+	store := newMemoryFeedback()
+	answerFeedback = store
+	t.Cleanup(func() { answerFeedback = previous })
+
+	matches := []rankedMatch{
+		{asset: data.KnowledgeAsset{Name: "ufo", MarkdownPrompt: "off topic"}},
+		{asset: data.KnowledgeAsset{Name: "spacecraft", MarkdownPrompt: "off topic"}},
+	}
+	question := "did you see a ufo " + strings.Repeat("really ", 500)
+	response := knowledgeResponse(context.Background(), matches, question)
+	_, params, err := slack.UnsafeApplyMsgOptions("", "", "", response...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var blocks slack.Blocks
+	if err := json.Unmarshal([]byte(params.Get("blocks")), &blocks); err != nil {
+		t.Fatalf("unable to unmarshal blocks: %v", err)
+	}
+	actions, ok := blocks.BlockSet[len(blocks.BlockSet)-1].(*slack.ActionBlock)
+	if !ok || len(actions.Elements.ElementSet) != len(feedbackRatings)+1 {
+		t.Fatalf("expected the answer to end with the feedback and explain buttons: %s", params.Get("blocks"))
+	}
+
+	wrongTopic := actions.Elements.ElementSet[2].(*slack.ButtonBlockElement)
+	if len(wrongTopic.Value) > maxButtonValue {
+		t.Fatalf("expected the value to fit in a button, got %d characters", len(wrongTopic.Value))
+	}
+	interaction := &slack.InteractionCallback{
+		User:    slack.User{ID: "U1"},
+		Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+	}
+	action := &slack.BlockAction{ActionID: wrongTopic.ActionID, Value: wrongTopic.Value}
+	if _, err := FeedbackActionAttributes.Callback(context.TODO(), nil, interaction, action); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, _ := store.List(context.TODO(), time.Time{})
+	if len(records) != 2 || records[0].Asset != "ufo" || records[1].Asset != "spacecraft" {
+		t.Fatalf("expected each answering asset to be rated: %+v", records)
+	}
+	for _, record := range records {
+		if record.Rating != data.KnowledgeFeedbackWrongTopic || record.User != "U1" || record.Channel != "C1" ||
+			!strings.HasPrefix(record.Message, "did you see a ufo really") {
+			t.Errorf("unexpected rating: %+v", record)
+		}
+	}
+
+	action = &slack.BlockAction{ActionID: FeedbackActionID + ":great", Value: wrongTopic.Value}
+	if _, err := FeedbackActionAttributes.Callback(context.TODO(), nil, interaction, action); err == nil {
+		t.Errorf("expected an unknown rating to be rejected")
+	}
+}
@@ -6,17 +6,19 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/expr-lang/expr"
 	"github.com/openshift-splat-team/splat-bot/data"
-	"github.com/openshift-splat-team/splat-bot/pkg/commands"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 	"github.com/openshift-splat-team/splat-bot/pkg/knowledge/platforms"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 	"github.com/openshift-splat-team/splat-bot/pkg/util"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
-	"go.uber.org/thriftrw/ptr"
 	"gopkg.in/yaml.v2"
 )
 
@@ -24,72 +26,44 @@ const (
 	DEFAULT_URL_PROMPT = `This may be a topic that I can help with.
 
 %s`
-	DEFAULT_LLM_PROMPT       = `Can you provide a short response that attempts to answer this question: `
-	DEBUG_CONDITION_MATCHING = false
+	DEFAULT_LLM_PROMPT = `Can you provide a short response that attempts to answer this question: `
 )
 
 var (
-	knowledgeAssets  = []data.KnowledgeAsset{}
+	// assetsMu guards knowledgeAssets and loadedFiles, which are replaced when the assets are reloaded.
+	assetsMu sync.RWMutex
+	// reloadMu serializes reloads so each builds on the files the last one loaded.
+	reloadMu        sync.Mutex
+	knowledgeAssets = []data.KnowledgeAsset{}
+	// loadedFiles the last version of the asset in each file which loaded.
+	loadedFiles = map[string]data.KnowledgeAsset{}
+	// knowledgeDir the directory the assets were last loaded from.
+	knowledgeDir     string
 	knowledgeEntries = []data.Knowledge{}
 	channelIDMap     = map[string]string{}
 	slackClient      util.SlackClientInterface
 	exprOptions      = []expr.Option{}
+	// debugMatching logs the match tree of each asset evaluated against a message. Set with
+	// KNOWLEDGE_DEBUG_MATCHING.
+	debugMatching = false
 )
 
-func DumpMatchTree(match data.TokenMatch, depth *int64, messages []string) []string {
-	if depth == nil {
-		depth = ptr.Int64(0)
-	} else {
-		*depth++
-	}
-	if messages == nil {
-		messages = []string{}
-	}
-	padding := strings.Repeat(" ", int(*depth))
-	matchType := "OR"
-	if len(match.Type) > 0 {
-		matchType = match.Type
-	}
-	messages = append(messages, fmt.Sprintf("%s Match: %t; Match Type: %s", padding, match.Satisfied, matchType))
-	messages = append(messages, fmt.Sprintf("%s Immediate Tokens: %s", padding, strings.Join(match.Tokens, ",")))
-	if len(match.Terms) > 0 {
-		messages = append(messages, fmt.Sprintf("%s Number of Descendant Terms(all terms must match in addition to tokens): %d", padding, len(match.Terms)))
-		for _, term := range match.Terms {
-			messages = DumpMatchTree(term, depth, messages)
-		}
-	}
-	*depth--
-	return messages
-}
-
 func getCachedClient() (util.SlackClientInterface, error) {
 	if slackClient == nil {
-		return util.GetClient()
+		return util.GetSlackClient()
 	}
 	return slackClient, nil
 }
 
 func IsMatch(asset data.KnowledgeAsset, tokens []string) bool {
-	if DEBUG_CONDITION_MATCHING {
-		log.Printf("+++++++++++++++++++++++++++++++++++++++IsMatch")
-		log.Printf("checking if message: %s is relevant to %s", strings.Join(tokens, " "), asset.Name)
-
-		defer func() {
-			log.Printf("---------------------------------------IsMatch")
-		}()
+	normalized := util.NormalizeTokens(tokens)
+	if debugMatching {
+		logMatchTree(asset, normalized)
 	}
-	return isTokenMatch(&asset.On, util.NormalizeTokens(tokens))
+	return isTokenMatch(&asset.On, normalized)
 }
 
 func IsStringMatch(asset data.KnowledgeAsset, str string) bool {
-	if DEBUG_CONDITION_MATCHING {
-		log.Printf("+++++++++++++++++++++++++++++++++++++++IsStringMatch")
-		defer func() {
-			log.Printf("---------------------------------------IsStringMatch")
-		}()
-
-		log.Printf("checking if message: %s is relevant to %s", str, asset.Name)
-	}
 	tokens := strings.Split(str, " ")
 	return IsMatch(asset, tokens)
 }
@@ -172,13 +146,31 @@ func getChannelName(channelID string) (string, error) {
 	return channel.Name, nil
 }
 
-func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent *slackevents.MessageEvent) ([]slack.MsgOption, error) {
+// assetEvaluation is how an asset was evaluated against a message.
+type assetEvaluation struct {
+	asset data.KnowledgeAsset
+	// skipped why the asset wasn't matched against the message, if it wasn't.
+	skipped string
+	// contextTokens the tokens of the asset's channel context added to the message.
+	contextTokens []string
+	tokens        map[string]string
+	matched       bool
+	specificity   int
+}
+
+// evaluateAssets matches the message against each asset. The tokens of an asset's channel context are added to the
+// message when it's sent to one of the asset's channels.
+func evaluateAssets(args []string, eventsAPIEvent *slackevents.MessageEvent) ([]assetEvaluation, error) {
 	var channel string
 	var err error
-	var matches []data.KnowledgeAsset
+	assets := currentAssets()
+	evaluations := make([]assetEvaluation, 0, len(assets))
 
-	for idx, entry := range knowledgeAssets {
+	for _, entry := range assets {
+		evaluation := assetEvaluation{asset: entry}
 		if !entry.WatchThreads && eventsAPIEvent.ThreadTimeStamp != "" {
+			evaluation.skipped = "it doesn't respond in threads"
+			evaluations = append(evaluations, evaluation)
 			continue
 		}
 		if entry.ChannelContext != nil {
@@ -194,6 +186,7 @@ func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent
 					terms := platforms.GetPathContextTerms(channelContext.ContextPath)
 					for _, term := range terms {
 						args = append(args, term.Tokens...)
+						evaluation.contextTokens = append(evaluation.contextTokens, term.Tokens...)
 					}
 					break
 				}
@@ -214,32 +207,61 @@ func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent
 				}
 			}
 			if !allowed {
+				evaluation.skipped = fmt.Sprintf("it only responds in %s", strings.Join(entry.RequireInChannel, ", "))
+				evaluations = append(evaluations, evaluation)
 				continue
 			}
 		}
-		if isTokenMatch(&knowledgeAssets[idx].On, util.NormalizeTokens(args)) {
-			matches = append(matches, entry)
+		// the condition is copied since matching records which terms were satisfied and the assets are shared
+		on := cloneTokenMatch(entry.On)
+		evaluation.tokens = util.NormalizeTokens(args)
+		if isTokenMatch(&on, evaluation.tokens) {
+			evaluation.matched = true
+			evaluation.specificity = specificity(&on, evaluation.tokens)
+		}
+		if debugMatching {
+			logMatchTree(entry, evaluation.tokens)
 		}
+		evaluations = append(evaluations, evaluation)
 	}
+	return evaluations, nil
+}
 
-	var response []slack.MsgOption
-	// TO-DO: how can we handle multiple matches? for now we'll just use the first one
-	if len(matches) > 0 {
-		match := matches[0]
-		// TO-DO: add support for LLM invocation
-		//if match.InvokeLLM {}
-
-		responseText := fmt.Sprintf(DEFAULT_URL_PROMPT, match.MarkdownPrompt)
-
-		if len(match.URLS) > 0 {
-			//response = append(response, slack.MsgOptionText(strings.Join(match.URLS, "\n"), false))
-			response = append(response, util.StringsToBlockWithURLs([]string{responseText}, match.URLS)...)
-		} else {
-			response = append(response, slack.MsgOptionText(responseText, true))
+// matchAssets returns the assets which match the message.
+func matchAssets(args []string, eventsAPIEvent *slackevents.MessageEvent) ([]rankedMatch, error) {
+	evaluations, err := evaluateAssets(args, eventsAPIEvent)
+	if err != nil {
+		return nil, err
+	}
+	var matches []rankedMatch
+	for _, evaluation := range evaluations {
+		if evaluation.matched {
+			matches = append(matches, rankedMatch{asset: evaluation.asset, specificity: evaluation.specificity})
 		}
+	}
+	return matches, nil
+}
 
+func defaultKnowledgeHandler(ctx context.Context, args []string, eventsAPIEvent *slackevents.MessageEvent) ([]slack.MsgOption, error) {
+	matches, err := matchAssets(args, eventsAPIEvent)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		metrics.KnowledgeMatched(match.asset.Name)
 	}
-	return response, nil
+	return knowledgeResponse(ctx, rankMatches(matches, maxAnswers), eventsAPIEvent.Text), nil
+}
+
+// answersWithLLM returns true if the answer to the message is generated by the LLM, so that it's answered in the
+// worker pool rather than on the event loop.
+func answersWithLLM(eventsAPIEvent *slackevents.MessageEvent, args []string) bool {
+	matches, err := matchAssets(args, eventsAPIEvent)
+	if err != nil {
+		return false
+	}
+	matches = rankMatches(matches, maxAnswers)
+	return len(matches) == 1 && matches[0].asset.InvokeLLM
 }
 
 func getKnowledgeEntryPaths(path string, paths []string) ([]string, error) {
@@ -262,47 +284,94 @@ func getKnowledgeEntryPaths(path string, paths []string) ([]string, error) {
 	return paths, nil
 }
 
+// readKnowledgeAsset reads and validates the asset in a file. The file is decoded with unmarshal.
+func readKnowledgeAsset(filePath string, unmarshal func([]byte, interface{}) error) (data.KnowledgeAsset, error) {
+	var asset data.KnowledgeAsset
+	knowledgeModel, err := os.ReadFile(filePath)
+	if err != nil {
+		return asset, fmt.Errorf("error reading file %s: %v", filePath, err)
+	}
+	err = unmarshal([]byte(knowledgeModel), &asset)
+	if err != nil {
+		return asset, fmt.Errorf("error unmarshalling file %s: %v", filePath, err)
+	}
+	if len(asset.Name) == 0 {
+		return asset, fmt.Errorf("knowledge asset in %s has no name", filePath)
+	}
+	// a condition without tokens, terms or an expression would match every message
+	if len(asset.On.Tokens) == 0 && len(asset.On.Terms) == 0 && len(asset.On.Expr) == 0 {
+		return asset, fmt.Errorf("knowledge asset %s in %s has no tokens, terms or expression to match", asset.Name, filePath)
+	}
+	asset.Path = filePath
+
+	// if the name of a known platform appears in the path add platform specific terms
+	// to 'On' which must be met before the knowledge asset is considered a match
+	if contextTerms := platforms.GetPathContextTerms(filePath); contextTerms != nil {
+		asset.On.Terms = append(asset.On.Terms, contextTerms...)
+	}
+
+	if len(asset.On.Expr) > 0 {
+		platformExpressions := platforms.GetPathContextExpr(filePath)
+		if len(platformExpressions) > 0 {
+			asset.On.Expr = fmt.Sprintf("%s and %s", platformExpressions, asset.On.Expr)
+		}
+		asset.On.CompiledExpr, err = expr.Compile(asset.On.Expr, exprOptions...)
+		if err != nil {
+			return asset, fmt.Errorf("error compiling knowledge expression in %s: %v", filePath, err)
+		}
+	}
+	return asset, nil
+}
+
+// loadKnowledgeEntries loads the assets in dir and replaces the current assets with them. A file which can't be
+// loaded keeps the last version of it which loaded, or is left out if none did. If dir can't be read, the current
+// assets are kept.
 func loadKnowledgeEntries(dir string) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
 	files, err := getKnowledgeEntryPaths(dir, []string{})
 	if err != nil {
 		return fmt.Errorf("error reading knowledge prompts directory: %v", err)
 	}
 
+	assetsMu.RLock()
+	previous := loadedFiles
+	assetsMu.RUnlock()
+
+	assets := make([]data.KnowledgeAsset, 0, len(files))
+	loaded := make(map[string]data.KnowledgeAsset, len(files))
 	for _, filePath := range files {
 		log.Debugf("loading knowledge entry from %s", filePath)
-		knowledgeModel, err := os.ReadFile(filePath)
+		asset, err := readKnowledgeAsset(filePath, yaml.Unmarshal)
 		if err != nil {
-			return fmt.Errorf("error reading file %s: %v", filePath, err)
-		}
-		var asset data.KnowledgeAsset
-		err = yaml.Unmarshal([]byte(knowledgeModel), &asset)
-		if err != nil {
-			log.Warnf("error unmarshalling file %s: %v", filePath, err)
-			continue
-		}
-		// if the name of a known platform appears in the path add platform specific terms
-		// to 'On' which must be met before the knowledge asset is considered a match
-		if contextTerms := platforms.GetPathContextTerms(filePath); contextTerms != nil {
-			asset.On.Terms = append(asset.On.Terms, contextTerms...)
-		}
-
-		if len(asset.On.Expr) > 0 {
-			platformExpressions := platforms.GetPathContextExpr(filePath)
-			if len(platformExpressions) > 0 {
-				asset.On.Expr = fmt.Sprintf("%s and %s", platformExpressions, asset.On.Expr)
-			}
-			asset.On.CompiledExpr, err = expr.Compile(asset.On.Expr, exprOptions...)
-			if err != nil {
-				return fmt.Errorf("error compiling knowledge expression: %v", err)
+			good, ok := previous[filePath]
+			if !ok {
+				log.Warnf("skipping knowledge asset: %v", err)
+				continue
 			}
+			log.Warnf("keeping the previous version of %s: %v", good.Name, err)
+			asset = good
 		}
-
-		knowledgeAssets = append(knowledgeAssets, asset)
+		assets = append(assets, asset)
+		loaded[filePath] = asset
 	}
 
+	assetsMu.Lock()
+	knowledgeAssets = assets
+	loadedFiles = loaded
+	knowledgeDir = dir
+	assetsMu.Unlock()
+	log.Infof("loaded %d knowledge assets from %s", len(assets), dir)
 	return nil
 }
 
+// currentAssets returns the loaded assets. The assets are replaced rather than changed when they're reloaded.
+func currentAssets() []data.KnowledgeAsset {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+	return knowledgeAssets
+}
+
 func init() {
 	exprOptions = append(exprOptions, expr.Function("containsAny", func(params ...any) (any, error) {
 		tokenMap := params[0].(map[string]string)
@@ -329,19 +398,6 @@ func init() {
 		return result, nil
 	}))
 
-	promptPath := os.Getenv("PROMPT_PATH")
-	if promptPath == "" {
-		promptPath = "/usr/src/app/knowledge_prompts"
-	}
-	err := loadKnowledgeEntries(promptPath)
-	// TODO: Need way for local developers to be able to still start application if they are not testing knowledge stuff.
-	//       For now, we will disable the commands tha require this.
-	if err != nil {
-		log.Debugf("error loading knowledge entries: %v", err)
-		log.Infof("Skipping adding of knowledge-based actions.")
-		return
-	}
-	commands.AddCommand(KnowledgeCommandAttributes)
 }
 
 var KnowledgeCommandAttributes = data.Attributes{
@@ -349,6 +405,13 @@ var KnowledgeCommandAttributes = data.Attributes{
 	DontGlobQuotes:     true,
 	RequireMention:     false,
 	AllowNonSplatUsers: true,
+	HelpArea:           "knowledge",
+	HelpMarkdown:       "ask a question in a channel the bot is in and it will answer when the question matches what it knows",
+	TrackEdits:         true,
+	AsyncWhen:          answersWithLLM,
+	// answers are limited so a busy channel doesn't have every message answered
+	ChannelRateLimit: config.RateLimit{Requests: 10, Per: 10 * time.Minute},
+	UserRateLimit:    config.RateLimit{Requests: 5, Per: 10 * time.Minute},
 	MessageOfInterest: func(args []string, attribute data.Attributes, channel string) bool {
 		for _, entry := range knowledgeEntries {
 			if entry.MessageOfInterest(args, attribute, channel) {
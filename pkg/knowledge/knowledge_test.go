@@ -188,7 +188,7 @@ func TestModelLoading(t *testing.T) {
 				}
 				responses, err := defaultKnowledgeHandler(ctx, tokens, msgEvent)
 				if err != nil || len(responses) == 0 {
-					dump := DumpMatchTree(asset.On, nil, nil)
+					dump := assetMatchTree(asset, util.NormalizeTokens(tokens))
 					t.Fatalf("expected to match: %s\nOn: %s", should, strings.Join(dump, "\n"))
 					return
 				}
@@ -214,7 +214,7 @@ func TestModelLoading(t *testing.T) {
 				}
 				_, err := defaultKnowledgeHandler(ctx, tokens, msgEvent)
 				if err != nil {
-					dump := DumpMatchTree(asset.On, nil, nil)
+					dump := assetMatchTree(asset, util.NormalizeTokens(tokens))
 					t.Fatalf("On: %s", strings.Join(dump, "\n"))
 					t.Fatalf("expected not to match: %s\nOn: %s", shouldnt, strings.Join(dump, "\n"))
 
@@ -0,0 +1,294 @@
+package knowledge
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/knowledge/platforms"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	LintCheckSchema        = "schema"
+	LintCheckExamples      = "examples"
+	LintCheckShouldMatch   = "should_match"
+	LintCheckShouldntMatch = "shouldnt_match"
+	LintCheckOverlap       = "overlap"
+)
+
+// LintProblem is a problem found in a knowledge asset. Warnings, such as assets which match the same examples,
+// only fail a strict lint.
+type LintProblem struct {
+	Check   string
+	Message string
+	Warning bool
+}
+
+// LintResult is the result of linting the asset in a file.
+type LintResult struct {
+	// File the path of the file relative to the linted directory.
+	File string
+	// Asset the name of the asset, if the file could be read.
+	Asset    string
+	Problems []LintProblem
+}
+
+// Failed returns true if the asset has errors, or warnings when strict.
+func (r LintResult) Failed(strict bool) bool {
+	for _, problem := range r.Problems {
+		if !problem.Warning || strict {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LintResult) report(check string, warning bool, format string, args ...any) {
+	r.Problems = append(r.Problems, LintProblem{Check: check, Message: fmt.Sprintf(format, args...), Warning: warning})
+}
+
+// LintReport is the result of linting the assets in a directory.
+type LintReport struct {
+	Dir     string
+	Results []LintResult
+}
+
+// Failed returns true if any asset has errors, or warnings when strict.
+func (r *LintReport) Failed(strict bool) bool {
+	for _, result := range r.Results {
+		if result.Failed(strict) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintedAsset an asset which was read and the result it's reported in.
+type lintedAsset struct {
+	asset  data.KnowledgeAsset
+	result *LintResult
+}
+
+// Lint checks the knowledge assets in dir. Each file must be a valid asset without unknown keys, with conditions
+// the matcher understands and with should_match and shouldnt_match examples which it matches and doesn't match.
+// Assets without examples, and assets which match the examples of another asset, are reported as warnings.
+func Lint(dir string) (*LintReport, error) {
+	files, err := getKnowledgeEntryPaths(dir, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LintReport{Dir: dir, Results: make([]LintResult, len(files))}
+	var assets []lintedAsset
+	names := map[string]string{}
+	for idx, filePath := range files {
+		result := &report.Results[idx]
+		result.File, err = filepath.Rel(dir, filePath)
+		if err != nil {
+			result.File = filePath
+		}
+		asset, err := readKnowledgeAsset(filePath, yaml.UnmarshalStrict)
+		if err != nil {
+			result.report(LintCheckSchema, false, "%v", err)
+			continue
+		}
+		result.Asset = asset.Name
+		if other, ok := names[asset.Name]; ok {
+			result.report(LintCheckSchema, false, "name %q is also used by %s", asset.Name, other)
+		}
+		names[asset.Name] = result.File
+		for _, problem := range lintTokenMatch(asset.On, "on", true) {
+			result.report(LintCheckSchema, false, "%s", problem)
+		}
+
+		if len(asset.ShouldMatch) == 0 {
+			result.report(LintCheckExamples, true, "expected at least one should_match example")
+		}
+		if len(asset.ShouldntMatch) == 0 {
+			result.report(LintCheckExamples, true, "expected at least one shouldnt_match example")
+		}
+		for _, example := range asset.ShouldMatch {
+			if matched, _ := exampleMatches(asset, example, true); !matched {
+				result.report(LintCheckShouldMatch, false, "%q doesn't match", example)
+			}
+		}
+		for _, example := range asset.ShouldntMatch {
+			if matched, _ := exampleMatches(asset, example, false); matched {
+				result.report(LintCheckShouldntMatch, false, "%q matches", example)
+			}
+		}
+		assets = append(assets, lintedAsset{asset: asset, result: result})
+	}
+
+	for _, linted := range assets {
+		lintOverlaps(linted, assets)
+	}
+	return report, nil
+}
+
+// lintTokenMatch returns the problems of a condition and the terms nested in it.
+func lintTokenMatch(match data.TokenMatch, path string, top bool) []string {
+	var problems []string
+	switch match.Type {
+	case "", "and", "or":
+	default:
+		problems = append(problems, fmt.Sprintf("%s.type: %q must be \"and\" or \"or\"", path, match.Type))
+	}
+	if top {
+		if len(match.Expr) > 0 && len(match.Tokens) > 0 {
+			problems = append(problems, fmt.Sprintf("%s.tokens: tokens are ignored when expr is set", path))
+		}
+	} else {
+		if len(match.Expr) > 0 {
+			problems = append(problems, fmt.Sprintf("%s.expr: expressions are only evaluated at the top of on", path))
+		} else if len(match.Tokens) == 0 && len(match.Terms) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: has no tokens or terms to match", path))
+		}
+	}
+	for idx, term := range match.Terms {
+		problems = append(problems, lintTokenMatch(term, fmt.Sprintf("%s.terms[%d]", path, idx), false)...)
+	}
+	return problems
+}
+
+// exampleMatches returns whether an example message matches an asset and how specifically. When inChannel is set,
+// the example is treated as if it was sent to the first channel of the asset's channel context.
+func exampleMatches(asset data.KnowledgeAsset, example string, inChannel bool) (bool, int) {
+	args := strings.Split(example, " ")
+	if inChannel && asset.ChannelContext != nil && len(asset.ChannelContext.Channels) > 0 {
+		for _, term := range platforms.GetPathContextTerms(asset.ChannelContext.ContextPath) {
+			args = append(args, term.Tokens...)
+		}
+	}
+	tokens := util.NormalizeTokens(args)
+	if !isTokenMatch(&asset.On, tokens) {
+		return false, 0
+	}
+	return true, specificity(&asset.On, tokens)
+}
+
+// lintOverlaps warns about assets which also match the should_match examples of an asset, and whether they'd be
+// answered instead of it.
+func lintOverlaps(linted lintedAsset, assets []lintedAsset) {
+	for _, example := range linted.asset.ShouldMatch {
+		matched, score := exampleMatches(linted.asset, example, true)
+		if !matched {
+			continue
+		}
+		matches := []rankedMatch{{asset: linted.asset, specificity: score}}
+		var others []string
+		for _, other := range assets {
+			if other.result == linted.result {
+				continue
+			}
+			if matched, score := exampleMatches(other.asset, example, false); matched {
+				matches = append(matches, rankedMatch{asset: other.asset, specificity: score})
+				others = append(others, other.asset.Name)
+			}
+		}
+		if len(others) == 0 {
+			continue
+		}
+		if best := rankMatches(matches, 1)[0].asset.Name; best != linted.asset.Name {
+			linted.result.report(LintCheckOverlap, true, "%q also matches %s, and %s is answered instead",
+				example, strings.Join(others, ", "), best)
+		} else {
+			linted.result.report(LintCheckOverlap, true, "%q also matches %s", example, strings.Join(others, ", "))
+		}
+	}
+}
+
+// WriteText writes a line for each problem and a summary.
+func (r *LintReport) WriteText(w io.Writer, strict bool) error {
+	errors, warnings := 0, 0
+	for _, result := range r.Results {
+		for _, problem := range result.Problems {
+			level := "ERROR"
+			if problem.Warning && !strict {
+				level = "WARN"
+				warnings++
+			} else {
+				errors++
+			}
+			name := result.File
+			if len(result.Asset) > 0 {
+				name = fmt.Sprintf("%s (%s)", result.File, result.Asset)
+			}
+			if _, err := fmt.Fprintf(w, "%s %s: %s: %s\n", level, name, problem.Check, problem.Message); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "checked %d knowledge assets in %s: %d errors, %d warnings\n", len(r.Results), r.Dir, errors, warnings)
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with a test case for each file. Warnings which don't fail the lint are
+// written to the output of the test case.
+func (r *LintReport) WriteJUnit(w io.Writer, strict bool) error {
+	suite := junitTestSuite{Name: "knowledge lint", Tests: len(r.Results)}
+	for _, result := range r.Results {
+		testCase := junitTestCase{ClassName: "knowledge", Name: result.File}
+		var failures, output []string
+		for _, problem := range result.Problems {
+			line := fmt.Sprintf("%s: %s", problem.Check, problem.Message)
+			if problem.Warning && !strict {
+				output = append(output, line)
+			} else {
+				failures = append(failures, line)
+			}
+		}
+		if len(failures) > 0 {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: failures[0],
+				Type:    "lint",
+				Text:    strings.Join(failures, "\n"),
+			}
+		}
+		testCase.SystemOut = strings.Join(output, "\n")
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,139 @@
+package knowledge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"good.yaml": `name: good
+markdown: spacecraft are off topic
+on:
+  type: or
+  tokens: [spacecraft, ufo]
+should_match: ["did you see a ufo"]
+shouldnt_match: ["how do i install a cluster"]
+`,
+		"unknown-key.yaml": `name: unknown key
+markdown: unknown key
+urlz: ["https://example.com"]
+on:
+  tokens: [unknown]
+`,
+		"bad-type.yaml": `name: bad type
+on:
+  type: OR
+  terms:
+  - tokens: [wheel]
+  - type: and
+should_match: ["my wheel"]
+shouldnt_match: ["my car"]
+`,
+		"bad-expr.yaml": `name: bad expr
+on:
+  expr: containsAny(tokens, [
+should_match: ["anything"]
+shouldnt_match: ["nothing"]
+`,
+		"examples.yaml": `name: examples
+on:
+  type: and
+  tokens: [repair, car]
+should_match: ["repair my car", "fix my car"]
+shouldnt_match: ["repair car engines"]
+`,
+		"no-examples.yaml": `name: no examples
+on:
+  tokens: [boat]
+`,
+		"overlap.yaml": `name: overlap
+priority: 1
+on:
+  tokens: [ufo]
+should_match: ["ufo sighting"]
+shouldnt_match: ["plane sighting"]
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	report, err := Lint(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	problems := map[string][]LintProblem{}
+	for _, result := range report.Results {
+		problems[result.File] = result.Problems
+	}
+	expected := map[string][]string{
+		"good.yaml":        {"overlap: \"did you see a ufo\" also matches overlap, and overlap is answered instead"},
+		"unknown-key.yaml": {"schema: ", "field urlz not found"},
+		"bad-type.yaml":    {"on.type: \"OR\" must be \"and\" or \"or\"", "on.terms[1]: has no tokens or terms to match"},
+		"bad-expr.yaml":    {"error compiling knowledge expression"},
+		"examples.yaml":    {"should_match: \"fix my car\" doesn't match", "shouldnt_match: \"repair car engines\" matches"},
+		"overlap.yaml":     {"overlap: \"ufo sighting\" also matches good"},
+		"no-examples.yaml": {"examples: expected at least one should_match example", "examples: expected at least one shouldnt_match example"},
+	}
+	for file, messages := range expected {
+		var lines []string
+		for _, problem := range problems[file] {
+			lines = append(lines, problem.Check+": "+problem.Message)
+		}
+		joined := strings.Join(lines, "\n")
+		for _, message := range messages {
+			if !strings.Contains(joined, message) {
+				t.Errorf("expected %q to be reported for %s, got:\n%s", message, file, joined)
+			}
+		}
+		if file == "overlap.yaml" && strings.Contains(joined, "instead") {
+			t.Errorf("expected the asset with the higher priority to be answered: %s", joined)
+		}
+	}
+
+	good := LintResult{Problems: problems["good.yaml"]}
+	if good.Failed(false) || !good.Failed(true) {
+		t.Errorf("expected overlaps to only fail a strict lint")
+	}
+	withoutExamples := LintResult{Problems: problems["no-examples.yaml"]}
+	if withoutExamples.Failed(false) || !withoutExamples.Failed(true) {
+		t.Errorf("expected missing examples to only fail a strict lint")
+	}
+	if !report.Failed(false) {
+		t.Errorf("expected the lint to fail")
+	}
+
+	text := &bytes.Buffer{}
+	if err := report.WriteText(text, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text.String(), "WARN good.yaml (good): overlap:") || !strings.Contains(text.String(), "checked 7 knowledge assets") {
+		t.Errorf("unexpected text report:\n%s", text)
+	}
+
+	junit := &bytes.Buffer{}
+	if err := report.WriteJUnit(junit, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(junit.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, junit)
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 7 || suite.Failures != 4 {
+		t.Fatalf("expected 7 tests and 4 failures, got %d and %d", suite.Tests, suite.Failures)
+	}
+	for _, testCase := range suite.TestCases {
+		if testCase.Name == "good.yaml" && (testCase.Failure != nil || !strings.Contains(testCase.SystemOut, "overlap")) {
+			t.Errorf("expected the overlap to be written to the output of good.yaml: %+v", testCase)
+		}
+	}
+}
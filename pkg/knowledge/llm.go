@@ -0,0 +1,243 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	DEFAULT_RAG_PROMPT = `You answer questions in a Slack channel. Answer the question below in a few sentences using only
+the guidance and sources which follow. Cite the sources you use by their number, such as [1]. If they don't
+answer the question, say so.
+
+Guidance:
+%s
+%s
+Question: %s`
+	GENERATED_ANSWER_NOTICE = ":robot_face: _This answer was generated by an LLM and may be inaccurate. Check its sources._"
+
+	// maxDocumentBytes the size of a page which is read when it's fetched.
+	maxDocumentBytes = 1 << 20
+	// maxSourceLength the length of the content of each source included in a prompt.
+	maxSourceLength = 4000
+	fetchTimeout    = 10 * time.Second
+)
+
+var (
+	// generateResponse generates the answer to a prompt. Replaced in tests.
+	generateResponse = util.GenerateResponse
+	documents        = newDocumentCache(config.DEFAULT_KNOWLEDGE_CACHE_DIR, config.DEFAULT_KNOWLEDGE_CACHE_TTL)
+	llmTimeout       = config.DEFAULT_KNOWLEDGE_LLM_TIMEOUT
+
+	ignoredElements = regexp.MustCompile(`(?is)<(script|style|head|nav|footer)[^>]*>.*?</(script|style|head|nav|footer)>`)
+	blockTags       = regexp.MustCompile(`(?i)</?(p|div|br|h[1-6]|li|ul|ol|tr|table|pre|section|article|body)\b[^>]*>`)
+	htmlTags        = regexp.MustCompile(`(?s)<[^>]*>`)
+	lineBreaks      = regexp.MustCompile(`\s*\n\s*`)
+	spaces          = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// source is a URL of an asset and its content.
+type source struct {
+	url     string
+	label   string
+	content string
+}
+
+// parseLink returns the URL and label of a link, which may be in Slack's <url|label> form.
+func parseLink(link string) (string, string) {
+	link = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(link), "<"), ">")
+	url, label, found := strings.Cut(link, "|")
+	if !found {
+		label = url
+	}
+	return url, label
+}
+
+// documentCache fetches the content of URLs and keeps it in a directory so a URL is only fetched again once its
+// content is older than the TTL.
+type documentCache struct {
+	dir    string
+	ttl    time.Duration
+	client *http.Client
+}
+
+func newDocumentCache(dir string, ttl time.Duration) *documentCache {
+	return &documentCache{
+		dir:    dir,
+		ttl:    ttl,
+		client: &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+func (c *documentCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".txt")
+}
+
+// get returns the text content of url. Cached content which is older than the TTL is used when the URL can't be
+// fetched.
+func (c *documentCache) get(ctx context.Context, url string) (string, error) {
+	path := c.path(url)
+	cached, cacheErr := os.ReadFile(path)
+	if cacheErr == nil {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < c.ttl {
+			return string(cached), nil
+		}
+	}
+
+	content, err := c.fetch(ctx, url)
+	if err != nil {
+		if cacheErr == nil {
+			log.Warnf("using stale content of %s: %v", url, err)
+			return string(cached), nil
+		}
+		return "", err
+	}
+	if err := c.store(path, content); err != nil {
+		log.Warnf("unable to cache content of %s: %v", url, err)
+	}
+	return content, nil
+}
+
+func (c *documentCache) fetch(ctx context.Context, url string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %v", url, err)
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch %s: %v", url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", fmt.Errorf("unable to fetch %s: %s", url, response.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxDocumentBytes))
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %v", url, err)
+	}
+	if strings.Contains(response.Header.Get("Content-Type"), "html") {
+		return htmlToText(string(body)), nil
+	}
+	return string(body), nil
+}
+
+// store writes the content to a temporary file which is renamed so a partly written file is never read.
+func (c *documentCache) store(path, content string) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(c.dir, "fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// htmlToText returns the text of a page without its markup, scripts and navigation.
+func htmlToText(page string) string {
+	page = ignoredElements.ReplaceAllString(page, "")
+	page = blockTags.ReplaceAllString(page, "\n")
+	page = htmlTags.ReplaceAllString(page, "")
+	page = html.UnescapeString(page)
+	page = spaces.ReplaceAllString(page, " ")
+	page = lineBreaks.ReplaceAllString(page, "\n")
+	return strings.TrimSpace(page)
+}
+
+// buildPrompt builds a prompt which asks the LLM to answer the question from the asset's markdown and sources.
+func buildPrompt(asset data.KnowledgeAsset, sources []source, question string) string {
+	sourceText := strings.Builder{}
+	if len(sources) > 0 {
+		sourceText.WriteString("\nSources:\n")
+	}
+	for index, source := range sources {
+		content := source.content
+		if len(content) > maxSourceLength {
+			cut := maxSourceLength
+			for cut > 0 && !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			content = content[:cut]
+		}
+		if len(content) == 0 {
+			content = "(the content of this source is unavailable)"
+		}
+		sourceText.WriteString(fmt.Sprintf("[%d] %s\n%s\n\n", index+1, source.url, content))
+	}
+	return fmt.Sprintf(DEFAULT_RAG_PROMPT, asset.MarkdownPrompt, sourceText.String(), question)
+}
+
+// llmResponse answers the question with the LLM from the asset's markdown and the content of its URLs. The content
+// of URLs which can't be fetched is left out of the prompt but they're still cited.
+func llmResponse(ctx context.Context, asset data.KnowledgeAsset, question string) ([]slack.MsgOption, error) {
+	ctx, cancel := context.WithTimeout(ctx, llmTimeout)
+	defer cancel()
+
+	var sources []source
+	for _, link := range asset.URLS {
+		url, label := parseLink(link)
+		content, err := documents.get(ctx, url)
+		if err != nil {
+			log.Warnf("leaving the content of %s out of the answer to %s: %v", url, asset.Name, err)
+		}
+		sources = append(sources, source{url: url, label: label, content: content})
+	}
+
+	answer, err := generateResponse(ctx, buildPrompt(asset, sources, question))
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("the answer took longer than %s: %v", llmTimeout, ctx.Err())
+	}
+	answer = strings.TrimSpace(answer)
+	if len(answer) == 0 {
+		return nil, fmt.Errorf("the LLM returned an empty answer")
+	}
+
+	blocks := []slack.Block{
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, GENERATED_ANSWER_NOTICE, false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, answer, false, false), nil, nil),
+	}
+	if len(sources) > 0 {
+		citations := []string{"*Sources:*"}
+		for index, source := range sources {
+			citations = append(citations, fmt.Sprintf("[%d] <%s|%s>", index+1, source.url, source.label))
+		}
+		blocks = append(blocks, slack.NewDividerBlock(),
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, strings.Join(citations, "\n"), false, false), nil, nil))
+	}
+	blocks = append(blocks, responseActions(question, []string{asset.Name})...)
+	return []slack.MsgOption{
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(answer, false),
+		slack.MsgOptionDisableLinkUnfurl(),
+	}, nil
+}
@@ -0,0 +1,113 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+const testPage = `<html><head><title>ignored</title><script>var ignored = true;</script></head>
+<body><h1>Installing on vSphere</h1><p>Set the <b>datacenter</b> in install-config.yaml &amp; retry.</p></body></html>`
+
+// unescapeJSON undoes the escaping of HTML characters in encoded blocks.
+var unescapeJSON = strings.NewReplacer(`\u003c`, "<", `\u003e`, ">", `\u0026`, "&")
+
+func TestLLMResponse(t *testing.T) {
+	previousAssets, previousGenerate, previousDocuments, previousTimeout := knowledgeAssets, generateResponse, documents, llmTimeout
+	defer func() {
+		knowledgeAssets, generateResponse, documents, llmTimeout = previousAssets, previousGenerate, previousDocuments, previousTimeout
+	}()
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testPage)
+	}))
+	defer server.Close()
+
+	documents = newDocumentCache(t.TempDir(), time.Hour)
+	knowledgeAssets = []data.KnowledgeAsset{{
+		Name:           "vSphere installs",
+		MarkdownPrompt: "check the install-config",
+		URLS:           []string{fmt.Sprintf("<%s/install|Installing on vSphere>", server.URL)},
+		InvokeLLM:      true,
+		On:             data.TokenMatch{Type: "or", Tokens: []string{"datacenter"}},
+	}}
+	question := "which datacenter should I use"
+	args := strings.Split(question, " ")
+	evt := &slackevents.MessageEvent{Channel: "C1", Text: question}
+
+	if !answersWithLLM(evt, args) {
+		t.Fatalf("expected the answer to be generated in the worker pool")
+	}
+	if answersWithLLM(&slackevents.MessageEvent{Channel: "C1", Text: "hello"}, []string{"hello"}) {
+		t.Fatalf("expected messages which don't match to be handled on the event loop")
+	}
+
+	var prompt string
+	generateResponse = func(ctx context.Context, p string, _ ...llms.MessageContent) (string, error) {
+		prompt = p
+		return "Set the datacenter in install-config.yaml [1].", nil
+	}
+	for i := 0; i < 2; i++ {
+		response, err := defaultKnowledgeHandler(context.TODO(), args, evt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", response...)
+		blocks := unescapeJSON.Replace(values.Get("blocks"))
+		for _, expected := range []string{"generated by an LLM", "install-config.yaml [1]", "[1] <" + server.URL + "/install|Installing on vSphere>"} {
+			if !strings.Contains(blocks, expected) {
+				t.Fatalf("expected %q in the answer: %s", expected, blocks)
+			}
+		}
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("expected the page to be fetched once and then cached, got %d fetches", fetches.Load())
+	}
+	for _, expected := range []string{"check the install-config", "Installing on vSphere\nSet the datacenter in install-config.yaml & retry.", question} {
+		if !strings.Contains(prompt, expected) {
+			t.Fatalf("expected %q in the prompt: %s", expected, prompt)
+		}
+	}
+	if strings.Contains(prompt, "ignored") {
+		t.Fatalf("expected the head and scripts to be left out of the prompt: %s", prompt)
+	}
+
+	for name, generate := range map[string]func(ctx context.Context, p string, _ ...llms.MessageContent) (string, error){
+		"the LLM fails": func(ctx context.Context, p string, _ ...llms.MessageContent) (string, error) {
+			return "", errors.New("connection refused")
+		},
+		"the LLM times out": func(ctx context.Context, p string, _ ...llms.MessageContent) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			generateResponse = generate
+			llmTimeout = 10 * time.Millisecond
+			response, err := defaultKnowledgeHandler(context.TODO(), args, evt)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", response...)
+			blocks := values.Get("blocks")
+			if strings.Contains(blocks, "generated by an LLM") || !strings.Contains(blocks, "check the install-config") {
+				t.Fatalf("expected the markdown of the asset: %s", blocks)
+			}
+		})
+	}
+}
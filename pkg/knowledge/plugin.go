@@ -0,0 +1,126 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+// Plugin answers questions which match the knowledge assets.
+type Plugin struct {
+	dir   string
+	watch bool
+	git   *gitSync
+	// pullInterval the time between pulls of the git repository.
+	pullInterval time.Duration
+	wg           sync.WaitGroup
+}
+
+// NewPlugin returns the plugin which answers questions from the knowledge assets.
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Name() string {
+	return "knowledge"
+}
+
+// Configure loads the knowledge assets from the prompt path and sets how answers are built from them. When a git
+// repository is configured, it's cloned or pulled into the prompt path first. If the assets can't be loaded, the
+// plugin answers nothing until they're loaded by a later pull or change.
+func (p *Plugin) Configure(cfg *config.Config) error {
+	p.dir = cfg.Knowledge.PromptPath
+	p.watch = cfg.Knowledge.Watch
+	p.git = nil
+	if len(cfg.Knowledge.GitRepository) > 0 {
+		p.git = &gitSync{
+			repository: cfg.Knowledge.GitRepository,
+			branch:     cfg.Knowledge.GitBranch,
+			dir:        cfg.Knowledge.PromptPath,
+		}
+		p.pullInterval = cfg.Knowledge.GitPullInterval
+		p.dir = filepath.Join(cfg.Knowledge.PromptPath, cfg.Knowledge.GitPath)
+		if _, err := p.git.sync(context.Background()); err != nil {
+			log.Warnf("unable to pull knowledge assets from %s: %v", cfg.Knowledge.GitRepository, err)
+		}
+	}
+
+	if err := loadKnowledgeEntries(p.dir); err != nil {
+		log.Warnf("no knowledge assets were loaded, they're loaded once they're pulled or changed: %v", err)
+	}
+	maxAnswers = cfg.Knowledge.MaxAnswers
+	debugMatching = cfg.Knowledge.DebugMatching
+	llmTimeout = cfg.Knowledge.LLMTimeout
+	documents = newDocumentCache(cfg.Knowledge.CacheDir, cfg.Knowledge.CacheTTL)
+
+	switch {
+	case len(cfg.Knowledge.FeedbackConfigMap) > 0:
+		namespace, name, _ := strings.Cut(cfg.Knowledge.FeedbackConfigMap, "/")
+		store, err := newConfigMapFeedback(namespace, name, cfg.Knowledge.FeedbackConfigMapMaxRecords)
+		if err != nil {
+			return fmt.Errorf("unable to create ConfigMap feedback store: %v", err)
+		}
+		answerFeedback = store
+	case len(cfg.Knowledge.FeedbackPath) > 0:
+		answerFeedback = newFileFeedback(cfg.Knowledge.FeedbackPath)
+	default:
+		answerFeedback = newMemoryFeedback()
+	}
+	return nil
+}
+
+// Start watches the prompt path and pulls the git repository, if they're enabled, until ctx is done.
+func (p *Plugin) Start(ctx context.Context) error {
+	if p.watch {
+		if err := watchKnowledgeEntries(ctx, p.dir); err != nil {
+			log.Warnf("knowledge assets won't be reloaded when they change: %v", err)
+		}
+	}
+	if p.git != nil {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.git.pull(ctx, p.pullInterval, p.dir)
+		}()
+	}
+	return nil
+}
+
+// Stop waits for a pull of the git repository to finish. Pulls stop once the context passed to Start is done.
+func (p *Plugin) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		p.wg.Wait()
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Plugin) Commands() []data.Attributes {
+	return []data.Attributes{
+		KnowledgeCommandAttributes,
+		KnowledgeExplainAttributes,
+		KnowledgeStatsAttributes,
+		KnowledgeReportAttributes,
+	}
+}
+
+func (p *Plugin) Actions() []data.ActionAttributes {
+	return []data.ActionAttributes{
+		ExplainActionAttributes,
+		FeedbackActionAttributes,
+	}
+}
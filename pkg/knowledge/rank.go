@@ -0,0 +1,169 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	DEFAULT_COMBINED_PROMPT = `These may be topics that I can help with.`
+)
+
+// maxAnswers the number of matching assets combined into an answer.
+var maxAnswers = config.DEFAULT_KNOWLEDGE_MAX_ANSWERS
+
+// rankedMatch is an asset which matched a message and how specifically it matched.
+type rankedMatch struct {
+	asset       data.KnowledgeAsset
+	specificity int
+}
+
+// specificity scores how specifically a condition matches the tokens of a message. Each token of the condition
+// which is in the message and each satisfied term scores one, so a term nested deeper scores for each level.
+func specificity(match *data.TokenMatch, tokens map[string]string) int {
+	if match.CompiledExpr != nil {
+		return exprSpecificity(match.CompiledExpr, tokens)
+	}
+	score := 0
+	for _, token := range match.Tokens {
+		if _, exists := tokens[strings.ToLower(token)]; exists {
+			score++
+		}
+	}
+	for idx := range match.Terms {
+		if isTokenMatch(&match.Terms[idx], tokens) {
+			score += 1 + specificity(&match.Terms[idx], tokens)
+		}
+	}
+	return score
+}
+
+// exprScorer scores an expression for each token it names which is in the message and for each condition joined
+// with and.
+type exprScorer struct {
+	tokens map[string]string
+	seen   map[string]bool
+	score  int
+}
+
+func (s *exprScorer) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.BinaryNode:
+		if n.Operator == "and" || n.Operator == "&&" {
+			s.score++
+		}
+	case *ast.StringNode:
+		s.token(n.Value)
+	case *ast.ConstantNode:
+		// lists of tokens are folded into constants when the expression is compiled
+		if values, ok := n.Value.([]any); ok {
+			for _, value := range values {
+				if token, ok := value.(string); ok {
+					s.token(token)
+				}
+			}
+		}
+	}
+}
+
+func (s *exprScorer) token(token string) {
+	token = strings.ToLower(token)
+	if _, exists := s.tokens[token]; exists && !s.seen[token] {
+		s.seen[token] = true
+		s.score++
+	}
+}
+
+func exprSpecificity(program *vm.Program, tokens map[string]string) int {
+	node := program.Node()
+	if node == nil {
+		return 0
+	}
+	scorer := &exprScorer{tokens: tokens, seen: map[string]bool{}}
+	ast.Walk(&node, scorer)
+	return scorer.score
+}
+
+// rankMatches orders matches by priority and then by specificity. Matches which rank the same are ordered by name
+// so the answer doesn't depend on the order the assets were loaded in. When an exclusive asset matched, the best
+// exclusive match is the only one returned. Otherwise at most limit matches are returned.
+func rankMatches(matches []rankedMatch, limit int) []rankedMatch {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.asset.Priority != b.asset.Priority {
+			return a.asset.Priority > b.asset.Priority
+		}
+		if a.specificity != b.specificity {
+			return a.specificity > b.specificity
+		}
+		return a.asset.Name < b.asset.Name
+	})
+	for _, match := range matches {
+		if match.asset.Exclusive {
+			return []rankedMatch{match}
+		}
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// knowledgeResponse answers with the markdown and URLs of the matches. A single match is answered as is, or with
+// an answer generated from its sources if it invokes the LLM. Several matches are combined into one response which
+// lists each asset.
+func knowledgeResponse(ctx context.Context, matches []rankedMatch, question string) []slack.MsgOption {
+	switch len(matches) {
+	case 0:
+		return nil
+	case 1:
+		match := matches[0].asset
+		if match.InvokeLLM {
+			response, err := llmResponse(ctx, match, question)
+			if err == nil {
+				return response
+			}
+			log.Warnf("answering with the markdown of %s: unable to generate an answer: %v", match.Name, err)
+		}
+		responseText := fmt.Sprintf(DEFAULT_URL_PROMPT, match.MarkdownPrompt)
+		blocks := append(util.BlocksWithURLs([]string{responseText}, match.URLS), responseActions(question, []string{match.Name})...)
+		return []slack.MsgOption{
+			slack.MsgOptionBlocks(blocks...),
+			slack.MsgOptionText(responseText, false),
+		}
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, DEFAULT_COMBINED_PROMPT, false, false), nil, nil),
+	}
+	for _, match := range matches {
+		asset := match.asset
+		blocks = append(blocks, slack.NewDividerBlock(),
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("*%s*\n%s", asset.Name, asset.MarkdownPrompt), false, false), nil, nil))
+		if len(asset.URLS) > 0 {
+			blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+				"*Relevant links:*\n"+strings.Join(asset.URLS, "\n"), false, false), nil, nil))
+		}
+	}
+	var names []string
+	for _, match := range matches {
+		names = append(names, match.asset.Name)
+	}
+	blocks = append(blocks, responseActions(question, names)...)
+	return []slack.MsgOption{
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(DEFAULT_COMBINED_PROMPT, false),
+	}
+}
@@ -0,0 +1,158 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/expr-lang/expr"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+func TestSpecificity(t *testing.T) {
+	var nested data.KnowledgeAsset
+	if err := yaml.Unmarshal([]byte(simpleLogicYaml), &nested); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	program, err := expr.Compile(`containsAll(tokens, ["this", "is"]) and containsAny(tokens, ["expressions", "train"])`, exprOptions...)
+	if err != nil {
+		t.Fatalf("unable to compile expression: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		match    data.TokenMatch
+		message  string
+		expected int
+	}{
+		{
+			name:     "tokens",
+			match:    data.TokenMatch{Type: "or", Tokens: []string{"spacecraft", "UFO"}},
+			message:  "did you see a ufo",
+			expected: 1,
+		},
+		{
+			name:     "nested terms",
+			match:    nested.On,
+			message:  "i have a problem with virtx on arm",
+			expected: 4,
+		},
+		{
+			name:     "expression",
+			match:    data.TokenMatch{CompiledExpr: program},
+			message:  "this is a test of expressions",
+			expected: 4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := util.NormalizeTokens(strings.Split(tc.message, " "))
+			if score := specificity(&tc.match, tokens); score != tc.expected {
+				t.Fatalf("expected specificity %d, got %d", tc.expected, score)
+			}
+		})
+	}
+}
+
+func TestRankMatches(t *testing.T) {
+	match := func(name string, priority, specificity int, exclusive bool) rankedMatch {
+		return rankedMatch{
+			asset:       data.KnowledgeAsset{Name: name, Priority: priority, Exclusive: exclusive},
+			specificity: specificity,
+		}
+	}
+	testCases := []struct {
+		name     string
+		matches  []rankedMatch
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "priority is preferred over specificity",
+			matches:  []rankedMatch{match("general", 0, 5, false), match("important", 1, 1, false)},
+			limit:    2,
+			expected: []string{"important", "general"},
+		},
+		{
+			name:     "more specific matches are preferred",
+			matches:  []rankedMatch{match("loose", 0, 1, false), match("specific", 0, 3, false)},
+			limit:    1,
+			expected: []string{"specific"},
+		},
+		{
+			name:     "ties are ordered by name",
+			matches:  []rankedMatch{match("b", 0, 1, false), match("c", 0, 1, false), match("a", 0, 1, false)},
+			limit:    3,
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "exclusive matches suppress others",
+			matches:  []rankedMatch{match("general", 1, 5, false), match("exclusive", 0, 1, true)},
+			limit:    3,
+			expected: []string{"exclusive"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			for _, ranked := range rankMatches(tc.matches, tc.limit) {
+				names = append(names, ranked.asset.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestCombinedResponse(t *testing.T) {
+	previousAssets, previousMaxAnswers := knowledgeAssets, maxAnswers
+	defer func() {
+		knowledgeAssets, maxAnswers = previousAssets, previousMaxAnswers
+	}()
+	knowledgeAssets = []data.KnowledgeAsset{
+		{Name: "UFOs", MarkdownPrompt: "ask in #forum-ufo", URLS: []string{"<https://example.com/ufo|UFOs>"},
+			On: data.TokenMatch{Type: "or", Tokens: []string{"ufo"}}},
+		{Name: "Repairs", MarkdownPrompt: "ask in #forum-repairs",
+			On: data.TokenMatch{Type: "and", Tokens: []string{"repair", "ufo"}}},
+		{Name: "Cars", MarkdownPrompt: "ask in #forum-cars",
+			On: data.TokenMatch{Type: "or", Tokens: []string{"car"}}},
+	}
+	maxAnswers = 2
+
+	args := strings.Split("how do i repair a ufo", " ")
+	response, err := defaultKnowledgeHandler(context.TODO(), args, &slackevents.MessageEvent{Channel: "C1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, values, err := slack.UnsafeApplyMsgOptions("", "", "", response...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blocks := values.Get("blocks")
+	repairs, ufos := strings.Index(blocks, "*Repairs*"), strings.Index(blocks, "*UFOs*")
+	if repairs < 0 || ufos < 0 || repairs > ufos {
+		t.Fatalf("expected the more specific match to be listed first: %s", blocks)
+	}
+	if !strings.Contains(blocks, "https://example.com/ufo") {
+		t.Fatalf("expected the links of each match: %s", blocks)
+	}
+	if strings.Contains(blocks, "Cars") {
+		t.Fatalf("expected only matching assets: %s", blocks)
+	}
+
+	maxAnswers = 1
+	response, err = defaultKnowledgeHandler(context.TODO(), args, &slackevents.MessageEvent{Channel: "C1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, values, _ = slack.UnsafeApplyMsgOptions("", "", "", response...)
+	if text := values.Get("text"); !strings.Contains(text, "#forum-repairs") || strings.Contains(text, "#forum-ufo") {
+		t.Fatalf("expected only the best match: %q", text)
+	}
+}
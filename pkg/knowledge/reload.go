@@ -0,0 +1,153 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// reloadDelay the time the watcher waits for changes to settle before the assets are reloaded, so a change to
+	// several files, such as a git pull, reloads them once.
+	reloadDelay = 500 * time.Millisecond
+	// gitTimeout the time a clone or pull of the repository may take.
+	gitTimeout = 2 * time.Minute
+)
+
+// watchKnowledgeEntries reloads the assets in dir when its files change until ctx is done. Every directory under
+// dir is watched, as well as dir itself so that ConfigMap updates, which swap a symlink, are detected. Until dir
+// exists, such as before the git repository is cloned, the closest directory above it is watched.
+func watchKnowledgeEntries(ctx context.Context, dir string) error {
+	err := util.WatchFiles(ctx, "knowledge", reloadDelay, func(watcher *fsnotify.Watcher) error {
+		return addWatches(watcher, dir)
+	}, func() {
+		if err := loadKnowledgeEntries(dir); err != nil {
+			log.Warnf("keeping the previous knowledge assets: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("unable to watch knowledge prompts directory %s: %v", dir, err)
+	}
+	return nil
+}
+
+// addWatches watches dir and every directory under it, or the closest directory above dir which exists. Directories
+// of git metadata aren't watched.
+func addWatches(watcher *fsnotify.Watcher, dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		parent := filepath.Dir(dir)
+		for {
+			if _, err := os.Stat(parent); err == nil || filepath.Dir(parent) == parent {
+				return watcher.Add(parent)
+			}
+			parent = filepath.Dir(parent)
+		}
+	}
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// gitSync keeps a clone of a branch of a git repository in a directory.
+type gitSync struct {
+	repository string
+	branch     string
+	dir        string
+}
+
+// sync clones the branch into the directory, or updates the clone to the latest commit of the branch. Local
+// changes to the clone are discarded. Returns true if the files changed.
+func (g *gitSync) sync(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+	if _, err := os.Stat(filepath.Join(g.dir, ".git")); errors.Is(err, fs.ErrNotExist) {
+		// git clones into an empty directory, which may be a mounted volume, but not into one with files
+		if err := os.MkdirAll(g.dir, 0o755); err != nil {
+			return false, fmt.Errorf("unable to create %s: %v", g.dir, err)
+		}
+		if _, err := g.git(ctx, "clone", "--depth", "1", "--single-branch", "--branch", g.branch, g.repository, "."); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if _, err := g.git(ctx, "fetch", "--depth", "1", "origin", g.branch); err != nil {
+		return false, err
+	}
+	head, err := g.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	fetched, err := g.git(ctx, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return false, err
+	}
+	if head == fetched {
+		return false, nil
+	}
+	if _, err := g.git(ctx, "reset", "--hard", "FETCH_HEAD"); err != nil {
+		return false, err
+	}
+	if _, err := g.git(ctx, "clean", "-fd"); err != nil {
+		return false, err
+	}
+	log.Infof("updated knowledge assets from %s %s to %s", g.repository, g.branch, fetched)
+	return true, nil
+}
+
+// git runs a git command in the directory and returns its output.
+func (g *gitSync) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.dir
+	// a repository which requires credentials fails rather than waiting for a prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// pull updates the clone every interval until ctx is done and reloads the assets in dir when the clone changes.
+// The assets are kept when the repository can't be pulled.
+func (g *gitSync) pull(ctx context.Context, interval time.Duration, dir string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := g.sync(ctx)
+		if err != nil {
+			log.Warnf("unable to pull knowledge assets from %s: %v", g.repository, err)
+			continue
+		}
+		if changed {
+			if err := loadKnowledgeEntries(dir); err != nil {
+				log.Warnf("keeping the previous knowledge assets: %v", err)
+			}
+		}
+	}
+}
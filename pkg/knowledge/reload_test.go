@@ -0,0 +1,199 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+const reloadTestAsset = `name: %s
+markdown: "test-prompt"
+on:
+  type: or
+  tokens: [%s]
+`
+
+func writeAsset(t *testing.T, path, name, token string) {
+	t.Helper()
+	content := fmt.Sprintf(reloadTestAsset, name, token)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assetNames() string {
+	var names []string
+	for _, asset := range currentAssets() {
+		names = append(names, asset.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func restoreAssets(t *testing.T) {
+	previousAssets, previousFiles := knowledgeAssets, loadedFiles
+	t.Cleanup(func() {
+		assetsMu.Lock()
+		defer assetsMu.Unlock()
+		knowledgeAssets, loadedFiles = previousAssets, previousFiles
+	})
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+	knowledgeAssets, loadedFiles = []data.KnowledgeAsset{}, map[string]data.KnowledgeAsset{}
+}
+
+func TestLoadKnowledgeEntries(t *testing.T) {
+	restoreAssets(t)
+	dir := t.TempDir()
+	writeAsset(t, filepath.Join(dir, "ufo.yaml"), "ufo", "ufo")
+	writeAsset(t, filepath.Join(dir, "cars", "car.yaml"), "car", "car")
+	if err := loadKnowledgeEntries(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := assetNames(); names != "car,ufo" {
+		t.Fatalf("expected both assets to be loaded, got %s", names)
+	}
+
+	// a broken file keeps its previous version while other files are reloaded
+	if err := os.WriteFile(filepath.Join(dir, "ufo.yaml"), []byte("name: ufo\non: [broken"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeAsset(t, filepath.Join(dir, "cars", "car.yaml"), "truck", "truck")
+	writeAsset(t, filepath.Join(dir, "empty.yaml"), "", "boat")
+	if err := loadKnowledgeEntries(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := assetNames(); names != "truck,ufo" {
+		t.Fatalf("expected the previous version of the broken asset and no invalid assets, got %s", names)
+	}
+
+	// removed files are removed from the assets
+	if err := os.Remove(filepath.Join(dir, "ufo.yaml")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := loadKnowledgeEntries(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := assetNames(); names != "truck" {
+		t.Fatalf("expected the removed asset to be removed, got %s", names)
+	}
+
+	if err := loadKnowledgeEntries(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("expected an error loading a missing directory")
+	}
+	if names := assetNames(); names != "truck" {
+		t.Fatalf("expected the assets to be kept when the directory can't be read, got %s", names)
+	}
+}
+
+func TestWatchKnowledgeEntries(t *testing.T) {
+	restoreAssets(t)
+	dir := t.TempDir()
+	writeAsset(t, filepath.Join(dir, "ufo.yaml"), "ufo", "ufo")
+	if err := loadKnowledgeEntries(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watchKnowledgeEntries(ctx, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeAsset(t, filepath.Join(dir, "cars", "car.yaml"), "car", "car")
+
+	waitForAssets(t, "ufo", "car,ufo")
+	// files in directories created after the watch started are watched too
+	writeAsset(t, filepath.Join(dir, "cars", "car.yaml"), "truck", "truck")
+	waitForAssets(t, "car,ufo", "truck,ufo")
+}
+
+func TestPluginWithoutAssets(t *testing.T) {
+	restoreAssets(t)
+	previousFeedback, previousDocuments := answerFeedback, documents
+	t.Cleanup(func() { answerFeedback, documents = previousFeedback, previousDocuments })
+
+	cfg := config.Default()
+	cfg.Knowledge.PromptPath = filepath.Join(t.TempDir(), "prompts", "knowledge")
+	plugin := NewPlugin()
+	if err := plugin.Configure(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plugin.Commands()) == 0 || len(plugin.Actions()) == 0 {
+		t.Fatalf("expected the commands to be added before the assets are loaded")
+	}
+
+	// the assets are loaded once the prompt path is created
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := plugin.Start(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeAsset(t, filepath.Join(cfg.Knowledge.PromptPath, "ufo.yaml"), "ufo", "ufo")
+	waitForAssets(t, "", "ufo")
+}
+
+func waitForAssets(t *testing.T, previous, expected string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if assetNames() == expected {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("expected the assets to be reloaded from %s to %s, got %s", previous, expected, assetNames())
+}
+
+func TestGitSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	repository := t.TempDir()
+	origin := &gitSync{dir: repository}
+	commit := func(name string) {
+		t.Helper()
+		writeAsset(t, filepath.Join(repository, "prompts", name+".yaml"), name, name)
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", name},
+		} {
+			if _, err := origin.git(ctx, args...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+	if _, err := origin.git(ctx, "init", "-q", "-b", "main"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	commit("ufo")
+
+	clone := &gitSync{repository: repository, branch: "main", dir: filepath.Join(t.TempDir(), "clone")}
+	changed, err := clone.sync(ctx)
+	if err != nil || !changed {
+		t.Fatalf("expected the repository to be cloned, got changed=%t err=%v", changed, err)
+	}
+	if changed, err = clone.sync(ctx); err != nil || changed {
+		t.Fatalf("expected no change, got changed=%t err=%v", changed, err)
+	}
+
+	commit("car")
+	if changed, err = clone.sync(ctx); err != nil || !changed {
+		t.Fatalf("expected the clone to be updated, got changed=%t err=%v", changed, err)
+	}
+	if _, err := os.Stat(filepath.Join(clone.dir, "prompts", "car.yaml")); err != nil {
+		t.Fatalf("expected the new asset in the clone: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
+)
+
+const (
+	// reportWindow the ratings the report covers. The report is meant to be scheduled weekly.
+	reportWindow = 7 * 24 * time.Hour
+	// reportMinRatings the ratings an asset needs before the report ranks how helpful it is.
+	reportMinRatings = 3
+	// reportLimit the number of assets and messages in each section of the report.
+	reportLimit = 5
+	// statsLimit the number of assets listed by knowledge stats.
+	statsLimit = 20
+	// maxQuotedMessage the length of the messages quoted in stats and the report.
+	maxQuotedMessage = 200
+)
+
+// assetStats counts the ratings of the answers of an asset.
+type assetStats struct {
+	asset      string
+	helpful    int
+	notHelpful int
+	wrongTopic int
+}
+
+func (s *assetStats) total() int {
+	return s.helpful + s.notHelpful + s.wrongTopic
+}
+
+// helpfulness the share of the ratings which found the answers helpful.
+func (s *assetStats) helpfulness() float64 {
+	if s.total() == 0 {
+		return 0
+	}
+	return float64(s.helpful) / float64(s.total())
+}
+
+func (s *assetStats) String() string {
+	return fmt.Sprintf("*%s*: :+1: %d, :-1: %d, wrong topic %d (%.0f%% helpful)",
+		s.asset, s.helpful, s.notHelpful, s.wrongTopic, 100*s.helpfulness())
+}
+
+// latestRatings keeps the latest rating each user gave to the answer of an asset to a message, so rating an
+// answer again replaces the earlier rating. records are oldest first.
+func latestRatings(records []data.KnowledgeFeedback) []data.KnowledgeFeedback {
+	latest := map[string]int{}
+	var ratings []data.KnowledgeFeedback
+	for _, record := range records {
+		key := strings.Join([]string{record.User, record.Asset, record.Message}, "\x00")
+		if idx, ok := latest[key]; ok {
+			ratings[idx] = record
+			continue
+		}
+		latest[key] = len(ratings)
+		ratings = append(ratings, record)
+	}
+	return ratings
+}
+
+// countRatings returns the ratings of each asset, the most rated first.
+func countRatings(ratings []data.KnowledgeFeedback) []*assetStats {
+	counts := map[string]*assetStats{}
+	var stats []*assetStats
+	for _, rating := range ratings {
+		count, ok := counts[rating.Asset]
+		if !ok {
+			count = &assetStats{asset: rating.Asset}
+			counts[rating.Asset] = count
+			stats = append(stats, count)
+		}
+		switch rating.Rating {
+		case data.KnowledgeFeedbackHelpful:
+			count.helpful++
+		case data.KnowledgeFeedbackNotHelpful:
+			count.notHelpful++
+		case data.KnowledgeFeedbackWrongTopic:
+			count.wrongTopic++
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].total() != stats[j].total() {
+			return stats[i].total() > stats[j].total()
+		}
+		return stats[i].asset < stats[j].asset
+	})
+	return stats
+}
+
+// shortenMessage collapses the whitespace of a rated message and truncates it to maxQuotedMessage runes. A
+// shortened message isn't shortened again.
+func shortenMessage(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > maxQuotedMessage {
+		message = string(runes[:maxQuotedMessage-1]) + "…"
+	}
+	return message
+}
+
+// quoteMessage quotes a rated message, shortened so a list of them fits in a section.
+func quoteMessage(message string) string {
+	return "> " + shortenMessage(message)
+}
+
+// assetStatsResponse describes the ratings of an asset and the messages it most recently answered which were
+// rated as not helpful or the wrong topic.
+func assetStatsResponse(ratings []data.KnowledgeFeedback, name string) []slack.MsgOption {
+	var rated []data.KnowledgeFeedback
+	for _, rating := range ratings {
+		if strings.EqualFold(rating.Asset, name) {
+			rated = append(rated, rating)
+		}
+	}
+	if len(rated) == 0 {
+		return util.StringToBlockUnfurl(fmt.Sprintf("the answers of %s haven't been rated", name), false, false)
+	}
+	lines := []string{countRatings(rated)[0].String()}
+	for _, section := range []struct {
+		title  string
+		rating string
+	}{
+		{title: "*Recent wrong topic messages:*", rating: data.KnowledgeFeedbackWrongTopic},
+		{title: "*Recent not helpful messages:*", rating: data.KnowledgeFeedbackNotHelpful},
+	} {
+		var quotes []string
+		for idx := len(rated) - 1; idx >= 0 && len(quotes) < reportLimit; idx-- {
+			if rated[idx].Rating == section.rating {
+				quotes = append(quotes, quoteMessage(rated[idx].Message))
+			}
+		}
+		if len(quotes) > 0 {
+			lines = append(lines, "", section.title)
+			lines = append(lines, quotes...)
+		}
+	}
+	return util.StringToBlockUnfurl(strings.Join(lines, "\n"), false, false)
+}
+
+// statsResponse lists the ratings of the most rated assets, or of the named asset.
+func statsResponse(ctx context.Context, name string) ([]slack.MsgOption, error) {
+	records, err := answerFeedback.List(ctx, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list feedback: %v", err)
+	}
+	ratings := latestRatings(records)
+	if len(name) > 0 {
+		return assetStatsResponse(ratings, name), nil
+	}
+	stats := countRatings(ratings)
+	if len(stats) == 0 {
+		return util.StringToBlockUnfurl("no knowledge answers have been rated", false, false), nil
+	}
+	lines := []string{fmt.Sprintf("*Knowledge feedback*: %d ratings of %d assets", len(ratings), len(stats))}
+	for idx, stat := range stats {
+		if idx == statsLimit {
+			lines = append(lines, fmt.Sprintf("and %d more assets", len(stats)-idx))
+			break
+		}
+		lines = append(lines, stat.String())
+	}
+	return util.StringToBlockUnfurl(strings.Join(lines, "\n"), false, false), nil
+}
+
+// reportResponse lists the least helpful assets and the messages most often rated as the wrong topic in the
+// report window, so the triggers of the assets can be fixed.
+func reportResponse(ctx context.Context, now time.Time) ([]slack.MsgOption, error) {
+	records, err := answerFeedback.List(ctx, now.Add(-reportWindow))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list feedback: %v", err)
+	}
+	ratings := latestRatings(records)
+	stats := countRatings(ratings)
+	if len(stats) == 0 {
+		return util.StringToBlockUnfurl("no knowledge answers were rated in the last 7 days", false, false), nil
+	}
+
+	lines := []string{fmt.Sprintf("*Knowledge feedback for the last 7 days*: %d ratings of %d assets", len(ratings), len(stats)),
+		"", "*Least helpful assets:*"}
+	var ranked []*assetStats
+	for _, stat := range stats {
+		if stat.total() >= reportMinRatings {
+			ranked = append(ranked, stat)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].helpfulness() < ranked[j].helpfulness()
+	})
+	if len(ranked) == 0 {
+		lines = append(lines, fmt.Sprintf("no asset was rated at least %d times", reportMinRatings))
+	}
+	for idx, stat := range ranked {
+		if idx == reportLimit {
+			break
+		}
+		lines = append(lines, stat.String())
+	}
+
+	type wrongTopic struct {
+		asset   string
+		message string
+		count   int
+	}
+	counts := map[string]*wrongTopic{}
+	var wrongTopics []*wrongTopic
+	for _, rating := range ratings {
+		if rating.Rating != data.KnowledgeFeedbackWrongTopic {
+			continue
+		}
+		key := rating.Asset + "\x00" + strings.ToLower(strings.Join(strings.Fields(rating.Message), " "))
+		if count, ok := counts[key]; ok {
+			count.count++
+			continue
+		}
+		counts[key] = &wrongTopic{asset: rating.Asset, message: rating.Message, count: 1}
+		wrongTopics = append(wrongTopics, counts[key])
+	}
+	sort.SliceStable(wrongTopics, func(i, j int) bool {
+		return wrongTopics[i].count > wrongTopics[j].count
+	})
+	if len(wrongTopics) > 0 {
+		lines = append(lines, "", "*Most frequent wrong topic messages:*")
+	}
+	for idx, topic := range wrongTopics {
+		if idx == reportLimit {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d× answered by *%s*:", topic.count, topic.asset), quoteMessage(topic.message))
+	}
+	lines = append(lines, "", "use `knowledge explain \"<message>\" \"<asset>\"` to see why an asset answered a message")
+	return util.StringToBlockUnfurl(strings.Join(lines, "\n"), false, false), nil
+}
+
+var KnowledgeStatsAttributes = data.Attributes{
+	Commands:       []string{"knowledge", "stats"},
+	RequireMention: true,
+	HelpArea:       "knowledge",
+	Description:    "shows how users rated the answers of each knowledge asset, or of one asset with the messages it was rated on",
+	Arguments: []data.Argument{
+		{Name: "asset", Help: "the name of an asset, in quotes"},
+	},
+	ShouldMatch: []string{
+		"knowledge stats",
+		"knowledge stats \"vSphere install\"",
+	},
+	ShouldntMatch: []string{
+		"knowledge report",
+		"what are the knowledge stats",
+	},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		return statsResponse(ctx, data.GetParsedArgs(ctx).String("asset"))
+	},
+}
+
+var KnowledgeReportAttributes = data.Attributes{
+	Commands:       []string{"knowledge", "report"},
+	RequireMention: true,
+	HelpArea:       "knowledge",
+	Description:    "reports the least helpful knowledge assets and the messages most often rated as the wrong topic in the last 7 days. schedule it weekly with the scheduler",
+	ShouldMatch: []string{
+		"knowledge report",
+	},
+	ShouldntMatch: []string{
+		"knowledge stats",
+		"report knowledge",
+	},
+	Callback: func(ctx context.Context, client util.SlackClientInterface, evt *slackevents.MessageEvent, args []string) ([]slack.MsgOption, error) {
+		return reportResponse(ctx, time.Now())
+	},
+}
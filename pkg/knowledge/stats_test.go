@@ -0,0 +1,80 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+)
+
+func TestFeedbackReport(t *testing.T) {
+	previous := answerFeedback
+	store := newMemoryFeedback()
+	answerFeedback = store
+	t.Cleanup(func() { answerFeedback = previous })
+
+	responseText := func(response []slack.MsgOption, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", response...)
+		return values.Get("text")
+	}
+	ctx := context.TODO()
+	now := time.Now()
+	if text := responseText(reportResponse(ctx, now)); !strings.Contains(text, "no knowledge answers were rated") {
+		t.Fatalf("expected no ratings to be reported: %s", text)
+	}
+
+	rate := func(age time.Duration, asset, rating, user, message string) {
+		err := store.Record(ctx, data.KnowledgeFeedback{Time: now.Add(-age), Asset: asset, Rating: rating, User: user, Message: message})
+		if err != nil {
+			t.Fatalf("unable to record: %v", err)
+		}
+	}
+	// ratings older than the report window are only in the stats
+	rate(30*24*time.Hour, "ufo", data.KnowledgeFeedbackHelpful, "U1", "did you see a ufo")
+	rate(time.Hour, "ufo", data.KnowledgeFeedbackWrongTopic, "U1", "ufo sighting")
+	rate(time.Hour, "ufo", data.KnowledgeFeedbackWrongTopic, "U2", "UFO  sighting")
+	rate(time.Hour, "ufo", data.KnowledgeFeedbackHelpful, "U3", "ufo repairs")
+	// rating an answer again replaces the earlier rating
+	rate(2*time.Hour, "car", data.KnowledgeFeedbackNotHelpful, "U1", "fix my car")
+	rate(time.Hour, "car", data.KnowledgeFeedbackHelpful, "U1", "fix my car")
+	rate(time.Hour, "car", data.KnowledgeFeedbackHelpful, "U2", "fix my car")
+	rate(time.Hour, "car", data.KnowledgeFeedbackNotHelpful, "U3", "fix my truck")
+	rate(time.Hour, "boat", data.KnowledgeFeedbackWrongTopic, "U1", "boat sighting")
+
+	report := responseText(reportResponse(ctx, now))
+	for _, expected := range []string{
+		"*Knowledge feedback for the last 7 days*: 7 ratings of 3 assets",
+		"*Least helpful assets:*\n*ufo*: :+1: 1, :-1: 0, wrong topic 2 (33% helpful)\n*car*: :+1: 2, :-1: 1, wrong topic 0 (67% helpful)\n\n",
+		"*Most frequent wrong topic messages:*\n2× answered by *ufo*:\n> ufo sighting\n1× answered by *boat*:\n> boat sighting",
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("expected %q in the report:\n%s", expected, report)
+		}
+	}
+	if strings.Contains(report, "*boat*: ") {
+		t.Errorf("expected assets with few ratings not to be ranked:\n%s", report)
+	}
+
+	stats := responseText(statsResponse(ctx, ""))
+	if !strings.Contains(stats, "8 ratings of 3 assets\n*ufo*: :+1: 2, :-1: 0, wrong topic 2 (50% helpful)\n*car*:") {
+		t.Errorf("unexpected stats:\n%s", stats)
+	}
+	parsed := data.NewParsedArgs()
+	parsed.Set("asset", "UFO", true)
+	asset := responseText(KnowledgeStatsAttributes.Callback(data.WithParsedArgs(ctx, parsed), nil, &slackevents.MessageEvent{}, nil))
+	if !strings.Contains(asset, "*Recent wrong topic messages:*\n> UFO sighting\n> ufo sighting") || strings.Contains(asset, "not helpful messages") {
+		t.Errorf("unexpected stats of ufo:\n%s", asset)
+	}
+	if missing := responseText(statsResponse(ctx, "plane")); !strings.Contains(missing, "the answers of plane haven't been rated") {
+		t.Errorf("unexpected stats of an asset without ratings: %s", missing)
+	}
+}
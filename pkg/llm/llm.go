@@ -8,7 +8,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/openshift-splat-team/jira-bot/pkg/util"
+	jirautil "github.com/openshift-splat-team/jira-bot/pkg/util"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/util"
 )
 
 const (
@@ -23,7 +25,7 @@ func GetJiraIssueSummary(ctx context.Context, query string) (string, error) {
 
 	issues, resp, err := client.Issue.Search("filter = \"SPLAT - updates in last week\"", nil) //util.GetIssuesInQuery(client, query)
 	if err != nil {
-		responseBody, _ := util.GetResponseBody(resp)
+		responseBody, _ := jirautil.GetResponseBody(resp)
 		return "", fmt.Errorf("unable to get issues: %v\n\n%s", err, responseBody)
 	}
 
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "splat_bot"
+
+var (
+	commandInvocations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "command_invocations_total",
+			Help:      "Command invocations by command and outcome.",
+		},
+		[]string{"command", "outcome"},
+	)
+	commandDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "command_duration_seconds",
+			Help:      "Latency of command callbacks.",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		},
+		[]string{"command"},
+	)
+	knowledgeMatches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "knowledge_matches_total",
+			Help:      "Messages matched by each knowledge asset.",
+		},
+		[]string{"asset"},
+	)
+	knowledgeFeedback = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "knowledge_feedback_total",
+			Help:      "Ratings of the answers of each knowledge asset.",
+		},
+		[]string{"asset", "rating"},
+	)
+	llmDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "llm_request_duration_seconds",
+			Help:      "Latency of requests to the LLM.",
+			Buckets:   []float64{.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+	)
+	llmFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_request_failures_total",
+			Help:      "Requests to the LLM which failed.",
+		},
+	)
+	leaseAcquisitions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lease_acquisitions_total",
+			Help:      "Leases acquired by users.",
+		},
+	)
+	leasePrunes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lease_prunes_total",
+			Help:      "Expired user leases which were pruned.",
+		},
+	)
+	slackAPIErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "slack_api_errors_total",
+			Help:      "Slack API calls which failed, by method.",
+		},
+		[]string{"method"},
+	)
+	socketModeReconnects = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "socket_mode_reconnects_total",
+			Help:      "Times the Socket Mode connection was re-established.",
+		},
+	)
+)
+
+func init() {
+	// the controller-runtime registry also holds the controller and client-go metrics
+	ctrlmetrics.Registry.MustRegister(
+		commandInvocations,
+		commandDuration,
+		knowledgeMatches,
+		knowledgeFeedback,
+		llmDuration,
+		llmFailures,
+		leaseAcquisitions,
+		leasePrunes,
+		slackAPIErrors,
+		socketModeReconnects,
+	)
+}
+
+// CommandInvoked records an invocation of a command.
+func CommandInvoked(command, outcome string) {
+	commandInvocations.WithLabelValues(command, outcome).Inc()
+}
+
+// ObserveCallback records the latency of a command's callback.
+func ObserveCallback(command string, duration time.Duration) {
+	commandDuration.WithLabelValues(command).Observe(duration.Seconds())
+}
+
+// KnowledgeMatched records a message matched by a knowledge asset.
+func KnowledgeMatched(asset string) {
+	knowledgeMatches.WithLabelValues(asset).Inc()
+}
+
+// KnowledgeFeedback records a rating of an answer of a knowledge asset.
+func KnowledgeFeedback(asset, rating string) {
+	knowledgeFeedback.WithLabelValues(asset, rating).Inc()
+}
+
+// ObserveLLM records the latency of a request to the LLM and whether it failed.
+func ObserveLLM(duration time.Duration, err error) {
+	llmDuration.Observe(duration.Seconds())
+	if err != nil {
+		llmFailures.Inc()
+	}
+}
+
+// LeaseAcquired records a lease acquired by a user.
+func LeaseAcquired() {
+	leaseAcquisitions.Inc()
+}
+
+// LeasePruned records an expired user lease which was pruned.
+func LeasePruned() {
+	leasePrunes.Inc()
+}
+
+// SlackAPIError records a failed call to the Slack API.
+func SlackAPIError(method string) {
+	slackAPIErrors.WithLabelValues(method).Inc()
+}
+
+// SocketModeReconnected records the Socket Mode connection being re-established.
+func SocketModeReconnected() {
+	socketModeReconnects.Inc()
+}
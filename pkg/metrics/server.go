@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	shutdownTimeout = 5 * time.Second
+)
+
+var (
+	checksMu        sync.RWMutex
+	readinessChecks = map[string]func() error{}
+)
+
+// AddReadinessCheck adds a check which must pass for the bot to be ready.
+func AddReadinessCheck(name string, check func() error) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	readinessChecks[name] = check
+}
+
+// checkReadiness runs the readiness checks and returns the failures, sorted by name.
+func checkReadiness() []string {
+	checksMu.RLock()
+	defer checksMu.RUnlock()
+	var failures []string
+	for name, check := range readinessChecks {
+		if err := check(); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	sort.Strings(failures)
+	return failures
+}
+
+func readyz(w http.ResponseWriter, _ *http.Request) {
+	if failures := checkReadiness(); len(failures) > 0 {
+		http.Error(w, strings.Join(failures, "\n"), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+func newServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(ctrlmetrics.Registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", healthz)
+	mux.HandleFunc("/readyz", readyz)
+	return mux
+}
+
+// Serve serves /metrics, /healthz and /readyz on address until the context is done.
+func Serve(ctx context.Context, address string) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           newServeMux(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("failed shutting down metrics server: %v", err)
+		}
+	}()
+	log.Infof("serving metrics and health checks on %s", address)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("unable to serve metrics: %v", err)
+	}
+	return nil
+}
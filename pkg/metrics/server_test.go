@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, server *httptest.Server, path string) (int, string) {
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("unable to get %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", path, err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestServer(t *testing.T) {
+	checksMu.Lock()
+	saved := readinessChecks
+	readinessChecks = map[string]func() error{}
+	checksMu.Unlock()
+	defer func() {
+		checksMu.Lock()
+		readinessChecks = saved
+		checksMu.Unlock()
+	}()
+
+	server := httptest.NewServer(newServeMux())
+	defer server.Close()
+
+	if code, _ := get(t, server, "/healthz"); code != http.StatusOK {
+		t.Errorf("expected /healthz to be ok, got %d", code)
+	}
+
+	var connected bool
+	AddReadinessCheck("socket-mode", func() error {
+		if !connected {
+			return errors.New("not connected to Slack")
+		}
+		return nil
+	})
+	code, body := get(t, server, "/readyz")
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "socket-mode: not connected to Slack") {
+		t.Errorf("expected /readyz to report the failed check, got %d: %s", code, body)
+	}
+	connected = true
+	if code, body := get(t, server, "/readyz"); code != http.StatusOK {
+		t.Errorf("expected /readyz to be ok, got %d: %s", code, body)
+	}
+
+	CommandInvoked("ci lease", "success")
+	ObserveCallback("ci lease", time.Second)
+	KnowledgeMatched("vsphere-docs")
+	_, body = get(t, server, "/metrics")
+	for _, expected := range []string{
+		`splat_bot_command_invocations_total{command="ci lease",outcome="success"} 1`,
+		`splat_bot_command_duration_seconds_count{command="ci lease"} 1`,
+		`splat_bot_knowledge_matches_total{asset="vsphere-docs"} 1`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected /metrics to contain %s", expected)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/commands"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+// Plugin is a part of the bot which may be enabled on its own.
+type Plugin interface {
+	// Name the name used to enable the plugin in the configuration.
+	Name() string
+	// Configure is called with the configuration before the commands of the plugin are added.
+	Configure(cfg *config.Config) error
+	// Start starts any background work of the plugin. ctx is cancelled when the bot shuts down.
+	Start(ctx context.Context) error
+	// Stop waits for the background work of the plugin to finish, or for ctx to be done.
+	Stop(ctx context.Context) error
+	// Commands the commands provided by the plugin.
+	Commands() []data.Attributes
+}
+
+// ActionProvider is implemented by plugins which handle Block Kit interactions.
+type ActionProvider interface {
+	// Actions the interactions handled by the plugin.
+	Actions() []data.ActionAttributes
+}
+
+// Manager runs the enabled plugins.
+type Manager struct {
+	plugins []Plugin
+	started []Plugin
+}
+
+// NewManager returns a manager for the plugins named in enabled. An error is returned if a name doesn't match
+// one of the available plugins.
+func NewManager(available []Plugin, enabled []string) (*Manager, error) {
+	byName := map[string]Plugin{}
+	for _, plugin := range available {
+		byName[plugin.Name()] = plugin
+	}
+
+	manager := &Manager{}
+	seen := map[string]bool{}
+	for _, name := range enabled {
+		plugin, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown plugin %q", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		manager.plugins = append(manager.plugins, plugin)
+	}
+	return manager, nil
+}
+
+// Start configures each plugin, adds its commands and actions and starts it. Plugins are started in the
+// order they're enabled. If a plugin fails, the plugins already started are stopped.
+func (m *Manager) Start(ctx context.Context, cfg *config.Config) error {
+	for _, plugin := range m.plugins {
+		if err := m.start(ctx, cfg, plugin); err != nil {
+			if stopErr := m.Stop(context.WithoutCancel(ctx)); stopErr != nil {
+				log.Warnf("failed stopping plugins: %v", stopErr)
+			}
+			return fmt.Errorf("unable to start plugin %s: %v", plugin.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) start(ctx context.Context, cfg *config.Config, plugin Plugin) error {
+	if err := plugin.Configure(cfg); err != nil {
+		return err
+	}
+	for _, attribute := range plugin.Commands() {
+		commands.AddCommand(attribute)
+	}
+	if provider, ok := plugin.(ActionProvider); ok {
+		for _, action := range provider.Actions() {
+			commands.AddAction(action)
+		}
+	}
+	if err := plugin.Start(ctx); err != nil {
+		return err
+	}
+	m.started = append(m.started, plugin)
+	log.Infof("started plugin %s", plugin.Name())
+	return nil
+}
+
+// Stop stops the started plugins in the reverse of the order they were started.
+func (m *Manager) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		plugin := m.started[i]
+		if err := plugin.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("unable to stop plugin %s: %v", plugin.Name(), err))
+			continue
+		}
+		log.Infof("stopped plugin %s", plugin.Name())
+	}
+	m.started = nil
+	return errors.Join(errs...)
+}
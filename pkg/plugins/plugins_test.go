@@ -0,0 +1,107 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openshift-splat-team/splat-bot/data"
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+type fakePlugin struct {
+	name     string
+	startErr error
+	events   *[]string
+}
+
+func (p *fakePlugin) Name() string {
+	return p.name
+}
+
+func (p *fakePlugin) Configure(cfg *config.Config) error {
+	*p.events = append(*p.events, "configure "+p.name)
+	return nil
+}
+
+func (p *fakePlugin) Start(ctx context.Context) error {
+	if p.startErr != nil {
+		return p.startErr
+	}
+	*p.events = append(*p.events, "start "+p.name)
+	return nil
+}
+
+func (p *fakePlugin) Stop(ctx context.Context) error {
+	*p.events = append(*p.events, "stop "+p.name)
+	return nil
+}
+
+func (p *fakePlugin) Commands() []data.Attributes {
+	return nil
+}
+
+func newFakePlugins(events *[]string, names ...string) []Plugin {
+	var plugins []Plugin
+	for _, name := range names {
+		plugins = append(plugins, &fakePlugin{name: name, events: events})
+	}
+	return plugins
+}
+
+func TestNewManager(t *testing.T) {
+	events := []string{}
+	available := newFakePlugins(&events, "a", "b")
+
+	if _, err := NewManager(available, []string{"a", "c"}); err == nil || !strings.Contains(err.Error(), `"c"`) {
+		t.Errorf("expected unknown plugin to be reported: %v", err)
+	}
+
+	manager, err := NewManager(available, []string{"b", "a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(manager.plugins) != 2 || manager.plugins[0].Name() != "b" {
+		t.Errorf("expected plugins in the order they're enabled without duplicates: %v", manager.plugins)
+	}
+}
+
+func TestManagerLifecycle(t *testing.T) {
+	events := []string{}
+	manager, err := NewManager(newFakePlugins(&events, "a", "b"), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.Start(context.TODO(), config.Default()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.Stop(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "configure a,start a,configure b,start b,stop b,stop a"
+	if strings.Join(events, ",") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(events, ","))
+	}
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	events := []string{}
+	available := newFakePlugins(&events, "a", "c")
+	available = append(available, &fakePlugin{name: "b", startErr: errors.New("no cluster"), events: &events})
+	manager, err := NewManager(available, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = manager.Start(context.TODO(), config.Default())
+	if err == nil || !strings.Contains(err.Error(), "unable to start plugin b") {
+		t.Fatalf("expected the failed plugin to be reported: %v", err)
+	}
+	// plugins already started are stopped and later plugins aren't started
+	expected := "configure a,start a,configure b,stop a"
+	if strings.Join(events, ",") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(events, ","))
+	}
+}
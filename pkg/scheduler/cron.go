@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the times a job runs.
+type Schedule interface {
+	// Next returns the first time after t the job runs.
+	Next(t time.Time) time.Time
+}
+
+// descriptors are shorthands for common cron expressions.
+var descriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// cronField describes a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField     = cronField{name: "minute", min: 0, max: 59}
+	hourField       = cronField{name: "hour", min: 0, max: 23}
+	dayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	monthField      = cronField{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// 7 is accepted for Sunday as well as 0
+	dayOfWeekField = cronField{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// cronSchedule runs at the times which match each field of a cron expression. The bits of each field are set
+// for the values which match.
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// when both days are restricted, a time matches if either matches
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// everySchedule runs at a fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+// ParseSchedule parses a cron expression with minute, hour, day of month, month and day of week fields.
+// Fields may be `*`, values, ranges, lists and steps such as `*/15` or `mon-fri`. The descriptors @hourly,
+// @daily, @weekly and @monthly and intervals such as `@every 6h` are accepted too. Times are UTC.
+func ParseSchedule(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if interval, found := strings.CutPrefix(expression, "@every "); found {
+		duration, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %v", interval, err)
+		}
+		if duration < time.Minute {
+			return nil, fmt.Errorf("invalid interval %q: must be at least 1m", interval)
+		}
+		return everySchedule{interval: duration}, nil
+	}
+	if descriptor, ok := descriptors[expression]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", expression, len(fields))
+	}
+	schedule := &cronSchedule{
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}
+	for index, parsed := range []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{minuteField, &schedule.minute},
+		{hourField, &schedule.hour},
+		{dayOfMonthField, &schedule.dayOfMonth},
+		{monthField, &schedule.month},
+		{dayOfWeekField, &schedule.dayOfWeek},
+	} {
+		bits, err := parseField(fields[index], parsed.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", expression, err)
+		}
+		*parsed.bits = bits
+	}
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+	return schedule, nil
+}
+
+// parseField returns the bits of the values which match a field.
+func parseField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangeValue, stepValue, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepValue)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepValue, field.name)
+			}
+		}
+
+		start, end := field.min, field.max
+		if rangeValue != "*" {
+			first, last, isRange := strings.Cut(rangeValue, "-")
+			var err error
+			if start, err = field.parseValue(first); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = field.parseValue(last); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// a step without a range runs from the value to the end of the field
+				end = field.max
+			}
+			if end < start {
+				return 0, fmt.Errorf("invalid range %q in %s", rangeValue, field.name)
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) parseValue(value string) (int, error) {
+	for index, name := range f.names {
+		if strings.EqualFold(value, name) {
+			return f.min + index, nil
+		}
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < f.min || parsed > f.max {
+		return 0, fmt.Errorf("invalid %s %q, must be between %d and %d", f.name, value, f.min, f.max)
+	}
+	return parsed, nil
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<t.Day()) != 0
+	dayOfWeek := s.dayOfWeek&(1<<int(t.Weekday())) != 0
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// Next returns the first minute after t which matches the expression. The zero time is returned if no time in
+// the next 5 years matches, such as the 31st of February.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
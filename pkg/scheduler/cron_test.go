@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.May, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 13 * * mon-fri", time.Date(2024, time.May, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2024, time.May, 20, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC)},
+		{"30 10 1,15 * *", time.Date(2024, time.June, 1, 10, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// either day matches when both are restricted
+		{"0 12 1 * fri", time.Date(2024, time.May, 17, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 6h", now.Add(6 * time.Hour)},
+	} {
+		schedule, err := ParseSchedule(tc.expression)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", tc.expression, err)
+			continue
+		}
+		if next := schedule.Next(now); !next.Equal(tc.expected) {
+			t.Errorf("%q: expected %s, got %s", tc.expression, tc.expected, next)
+		}
+	}
+
+	schedule, err := ParseSchedule("0 0 31 feb *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next := schedule.Next(now); !next.IsZero() {
+		t.Errorf("expected a schedule which never matches to have no next run, got %s", next)
+	}
+
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * * * mon-sun-tue", "*/0 * * * *", "5-1 * * * *", "@every 10s", "@yearly"} {
+		if _, err := ParseSchedule(expression); err == nil {
+			t.Errorf("expected %q to be invalid", expression)
+		}
+	}
+}
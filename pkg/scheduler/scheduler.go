@@ -0,0 +1,257 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+// Runner runs the command of a job.
+type Runner func(ctx context.Context, job config.ScheduledJob) error
+
+// Status describes a job. The last run is only known to the replica which ran the job.
+type Status struct {
+	// Job the configuration of the job. Paused is set if the job is paused now.
+	Job       config.ScheduledJob
+	Next      time.Time
+	LastRun   time.Time
+	LastError error
+	Running   bool
+}
+
+// Scheduler runs the commands of jobs on their schedules.
+type Scheduler struct {
+	run      Runner
+	state    State
+	isLeader func() bool
+	now      func() time.Time
+
+	mu   sync.Mutex
+	ctx  context.Context
+	jobs []*job
+	wg   sync.WaitGroup
+}
+
+type job struct {
+	config   config.ScheduledJob
+	schedule Schedule
+	next     time.Time
+	lastRun  time.Time
+	lastErr  error
+	running  bool
+}
+
+// New returns a scheduler for the jobs. Due jobs are run with run, but only while isLeader returns true, so
+// that a job runs once when several replicas are deployed. Every invalid schedule is reported.
+func New(jobs []config.ScheduledJob, run Runner, state State, isLeader func() bool) (*Scheduler, error) {
+	s := &Scheduler{
+		run:      run,
+		state:    state,
+		isLeader: isLeader,
+		now:      time.Now,
+	}
+	var problems []string
+	for _, jobConfig := range jobs {
+		schedule, err := ParseSchedule(jobConfig.Schedule)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("job %s: %v", jobConfig.Name, err))
+			continue
+		}
+		s.jobs = append(s.jobs, &job{config: jobConfig, schedule: schedule})
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid scheduled jobs:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return s, nil
+}
+
+// Run runs jobs when they're due until ctx is done. Running jobs are waited for before Run returns.
+func (s *Scheduler) Run(ctx context.Context) {
+	defer s.wg.Wait()
+
+	s.mu.Lock()
+	s.ctx = ctx
+	now := s.now()
+	for _, j := range s.jobs {
+		j.next = j.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for {
+		var wait <-chan time.Time
+		var timer *time.Timer
+		if next := s.nextDue(); !next.IsZero() {
+			timer = time.NewTimer(next.Sub(s.now()))
+			wait = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-wait:
+		}
+		s.runDue(ctx)
+	}
+}
+
+// nextDue returns the time the next job is due.
+func (s *Scheduler) nextDue() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && (next.IsZero() || j.next.Before(next)) {
+			next = j.next
+		}
+	}
+	return next
+}
+
+// runDue starts the jobs which are due. Jobs are skipped when they're paused or this replica isn't the leader.
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := s.now()
+	var due []*job
+	s.mu.Lock()
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && !j.next.After(now) {
+			due = append(due, j)
+			j.next = j.schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(due) == 0 || !s.isLeader() {
+		return
+	}
+	paused, err := s.paused(ctx)
+	if err != nil {
+		log.Warnf("not running scheduled jobs: %v", err)
+		return
+	}
+	for _, j := range due {
+		if paused[j.config.Name] {
+			log.Debugf("scheduled job %s is paused", j.config.Name)
+			continue
+		}
+		if err := s.start(ctx, j); err != nil {
+			log.Warnf("%v", err)
+		}
+	}
+}
+
+// paused returns whether each job is paused. Jobs paused or resumed from Slack override the configuration.
+func (s *Scheduler) paused(ctx context.Context) (map[string]bool, error) {
+	state, err := s.state.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load scheduler state: %v", err)
+	}
+	paused := map[string]bool{}
+	for _, j := range s.jobs {
+		value, ok := state[j.config.Name]
+		if !ok {
+			value = j.config.Paused
+		}
+		paused[j.config.Name] = value
+	}
+	return paused, nil
+}
+
+// start runs a job in the background unless it's already running.
+func (s *Scheduler) start(ctx context.Context, j *job) error {
+	s.mu.Lock()
+	if j.running {
+		s.mu.Unlock()
+		return fmt.Errorf("scheduled job %s is still running", j.config.Name)
+	}
+	j.running = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		start := s.now()
+		log.Infof("running scheduled job %s: %s", j.config.Name, j.config.Command)
+		err := s.run(ctx, j.config)
+		if err != nil {
+			log.Warnf("scheduled job %s failed: %v", j.config.Name, err)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		j.running = false
+		j.lastRun = start
+		j.lastErr = err
+	}()
+	return nil
+}
+
+func (s *Scheduler) find(name string) (*job, error) {
+	for _, j := range s.jobs {
+		if j.config.Name == name {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no scheduled job named %s", name)
+}
+
+// Jobs returns the status of each job.
+func (s *Scheduler) Jobs(ctx context.Context) ([]Status, error) {
+	paused, err := s.paused(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var statuses []Status
+	for _, j := range s.jobs {
+		status := Status{
+			Job:       j.config,
+			Next:      j.next,
+			LastRun:   j.lastRun,
+			LastError: j.lastErr,
+			Running:   j.running,
+		}
+		status.Job.Paused = paused[j.config.Name]
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pause stops a job from running on its schedule until it's resumed.
+func (s *Scheduler) Pause(ctx context.Context, name string) error {
+	if _, err := s.find(name); err != nil {
+		return err
+	}
+	return s.state.Save(ctx, name, true)
+}
+
+// Resume runs a paused job on its schedule again.
+func (s *Scheduler) Resume(ctx context.Context, name string) error {
+	if _, err := s.find(name); err != nil {
+		return err
+	}
+	return s.state.Save(ctx, name, false)
+}
+
+// RunNow starts a job in the background, even if it's paused.
+func (s *Scheduler) RunNow(name string) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx == nil || ctx.Err() != nil {
+		return errors.New("the scheduler is not running")
+	}
+	return s.start(ctx, j)
+}
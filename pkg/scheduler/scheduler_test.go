@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+// recorder records the jobs which ran.
+type recorder struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *recorder) run(ctx context.Context, job config.ScheduledJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = append(r.ran, job.Name)
+	return nil
+}
+
+func (r *recorder) jobs() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.ran, ",")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New([]config.ScheduledJob{
+		{Name: "valid", Schedule: "@daily"},
+		{Name: "invalid", Schedule: "every day"},
+	}, nil, NewMemoryState(), nil)
+	if err == nil || !strings.Contains(err.Error(), "job invalid") {
+		t.Errorf("expected the invalid schedule to be reported: %v", err)
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	jobs := []config.ScheduledJob{
+		{Name: "daily", Schedule: "@daily"},
+		{Name: "hourly", Schedule: "@hourly"},
+		{Name: "paused", Schedule: "@hourly", Paused: true},
+	}
+	for _, tc := range []struct {
+		name     string
+		state    State
+		leader   bool
+		expected string
+	}{
+		{name: "leader", state: NewMemoryState(), leader: true, expected: "hourly"},
+		{name: "follower", state: NewMemoryState(), leader: false, expected: ""},
+		{name: "stored in a ConfigMap", state: newConfigMapState(fake.NewClientBuilder().Build(), "splat", "scheduler"), leader: true, expected: "hourly"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{}
+			s, err := New(jobs, r.run, tc.state, func() bool { return tc.leader })
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			now := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+			s.now = func() time.Time { return now }
+			for _, j := range s.jobs {
+				j.next = j.schedule.Next(now)
+			}
+
+			now = now.Add(30 * time.Minute)
+			s.runDue(context.TODO())
+			s.wg.Wait()
+			if r.jobs() != tc.expected {
+				t.Errorf("expected %q to run, got %q", tc.expected, r.jobs())
+			}
+
+			// jobs paused from Slack override the configuration
+			ctx := context.TODO()
+			if err := s.Pause(ctx, "hourly"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := s.Resume(ctx, "paused"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := s.Pause(ctx, "unknown"); err == nil {
+				t.Errorf("expected unknown jobs to be reported")
+			}
+			statuses, err := s.Jobs(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !statuses[1].Job.Paused || statuses[2].Job.Paused || !statuses[1].Next.Equal(time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected statuses: %+v", statuses)
+			}
+
+			now = now.Add(time.Hour)
+			s.runDue(ctx)
+			s.wg.Wait()
+			if tc.leader && !strings.HasSuffix(r.jobs(), ",paused") {
+				t.Errorf("expected the resumed job to run, got %q", r.jobs())
+			}
+		})
+	}
+}
+
+func TestRunNow(t *testing.T) {
+	r := &recorder{}
+	s, err := New([]config.ScheduledJob{{Name: "weekly", Schedule: "@weekly", Paused: true}}, r.run, NewMemoryState(), func() bool { return false })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.RunNow("weekly"); err == nil {
+		t.Errorf("expected jobs not to run before the scheduler is running")
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.Run(ctx)
+	}()
+	for s.RunNow("weekly") != nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-stopped
+	if r.jobs() != "weekly" {
+		t.Errorf("expected the paused job to run on request, got %q", r.jobs())
+	}
+}
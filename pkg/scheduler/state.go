@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/configmap"
+)
+
+const (
+	configMapKey = "paused.json"
+)
+
+// State stores the jobs which were paused or resumed from Slack. Jobs which aren't in the state are paused or
+// not as configured.
+type State interface {
+	// Load returns the jobs in the state mapped to whether they're paused.
+	Load(ctx context.Context) (map[string]bool, error)
+	// Save records that a job was paused or resumed.
+	Save(ctx context.Context, name string, paused bool) error
+}
+
+// MemoryState holds the state in memory. The state is lost on restart and isn't shared between replicas.
+type MemoryState struct {
+	mu     sync.Mutex
+	paused map[string]bool
+}
+
+// NewMemoryState returns an empty state held in memory.
+func NewMemoryState() *MemoryState {
+	return &MemoryState{paused: map[string]bool{}}
+}
+
+func (s *MemoryState) Load(ctx context.Context) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paused := make(map[string]bool, len(s.paused))
+	for name, value := range s.paused {
+		paused[name] = value
+	}
+	return paused, nil
+}
+
+func (s *MemoryState) Save(ctx context.Context, name string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused[name] = paused
+	return nil
+}
+
+// ConfigMapState stores the state in a ConfigMap so it's shared between replicas and kept across restarts.
+type ConfigMapState struct {
+	store *configmap.Store
+}
+
+// NewConfigMapState returns a state stored in the ConfigMap namespace/name. The ConfigMap is created when a job
+// is first paused or resumed.
+func NewConfigMapState(namespace, name string) (*ConfigMapState, error) {
+	store, err := configmap.NewStore(namespace, name, configMapKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigMapState{store: store}, nil
+}
+
+func newConfigMapState(k8sclient client.Client, namespace, name string) *ConfigMapState {
+	return &ConfigMapState{store: configmap.NewStoreWithClient(k8sclient, namespace, name, configMapKey)}
+}
+
+func decodeState(contents string) (map[string]bool, error) {
+	paused := map[string]bool{}
+	if len(contents) == 0 {
+		return paused, nil
+	}
+	if err := json.Unmarshal([]byte(contents), &paused); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal scheduler state: %v", err)
+	}
+	return paused, nil
+}
+
+func (s *ConfigMapState) Load(ctx context.Context) (map[string]bool, error) {
+	contents, err := s.store.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeState(contents)
+}
+
+func (s *ConfigMapState) Save(ctx context.Context, name string, paused bool) error {
+	return s.store.Update(ctx, func(contents string) (string, error) {
+		state, err := decodeState(contents)
+		if err != nil {
+			return "", err
+		}
+		state[name] = paused
+		updated, err := json.Marshal(state)
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal scheduler state: %v", err)
+		}
+		return string(updated), nil
+	})
+}
@@ -12,6 +12,14 @@ var (
 		"random": "random",
 		"vmware": "vmware",
 	}
+
+	userGroupsTest = []slack.UserGroup{
+		{
+			ID:     "S0SPLAT",
+			Handle: "splat-team",
+			Users:  []string{"alloweduser1"},
+		},
+	}
 )
 
 type SlackClientInterface interface {
@@ -20,6 +28,12 @@ type SlackClientInterface interface {
 	OpenConversation(params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error)
 	GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error)
 	GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error)
+	GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	GetUserGroupMembers(userGroup string) ([]string, error)
+	UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+	DeleteMessage(channel, messageTimestamp string) (string, string, error)
+	AddReaction(name string, item slack.ItemRef) error
+	RemoveReaction(name string, item slack.ItemRef) error
 }
 
 type StubInterface struct {
@@ -51,3 +65,32 @@ func (s *StubInterface) GetConversationInfo(input *slack.GetConversationInfoInpu
 	}
 	return nil, fmt.Errorf("GetConversationInfo")
 }
+
+func (s *StubInterface) GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return userGroupsTest, nil
+}
+
+func (s *StubInterface) GetUserGroupMembers(userGroup string) ([]string, error) {
+	for _, group := range userGroupsTest {
+		if group.ID == userGroup {
+			return group.Users, nil
+		}
+	}
+	return nil, fmt.Errorf("GetUserGroupMembers")
+}
+
+func (s *StubInterface) UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return "", "", "", fmt.Errorf("UpdateMessage")
+}
+
+func (s *StubInterface) DeleteMessage(channel, messageTimestamp string) (string, string, error) {
+	return "", "", fmt.Errorf("DeleteMessage")
+}
+
+func (s *StubInterface) AddReaction(name string, item slack.ItemRef) error {
+	return fmt.Errorf("AddReaction")
+}
+
+func (s *StubInterface) RemoveReaction(name string, item slack.ItemRef) error {
+	return fmt.Errorf("RemoveReaction")
+}
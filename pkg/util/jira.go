@@ -0,0 +1,66 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	jirautil "github.com/openshift-splat-team/jira-bot/pkg/util"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+var jiraConfig = config.Default().Jira
+
+// SetJiraConfig sets the configuration of the Jira client.
+func SetJiraConfig(cfg config.JiraConfig) {
+	jiraConfig = cfg
+}
+
+// GetJiraClient returns a client for the Jira at JIRA_BASE_URL.
+func GetJiraClient() (*jira.Client, error) {
+	transport := jira.BearerAuthTransport{
+		Token: jiraConfig.PersonalAccessToken,
+	}
+	return jira.NewClient(transport.Client(), strings.TrimSuffix(jiraConfig.BaseURL, "/")+"/")
+}
+
+// JiraIssueURL returns the link to an issue in the Jira at JIRA_BASE_URL.
+func JiraIssueURL(key string) string {
+	return fmt.Sprintf("%s/browse/%s", strings.TrimSuffix(jiraConfig.BaseURL, "/"), key)
+}
+
+// CreateJiraIssue creates an issue in a project of the Jira at JIRA_BASE_URL. The reporter of the issue is the
+// owner of the token.
+func CreateJiraIssue(projectKey, summary, description, issueType string) (*jira.Issue, error) {
+	client, err := GetJiraClient()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get Jira client: %v", err)
+	}
+	project, err := jirautil.GetProject(client, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get Jira project: %v", err)
+	}
+	projectIssueType, err := jirautil.GetIssueType(project, issueType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get Jira issue type: %v", err)
+	}
+
+	issue, resp, err := client.Issue.Create(&jira.Issue{
+		Fields: &jira.IssueFields{
+			Summary:     summary,
+			Description: description,
+			Project:     *project,
+			Type:        *projectIssueType,
+		},
+	})
+	if err != nil && resp != nil {
+		responseBody, _ := jirautil.GetResponseBody(resp)
+		return nil, fmt.Errorf("unable to create issue: %v. response body: %s", err, responseBody)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to create issue: %v", err)
+	}
+	log.Infof("created issue: %s", issue.Key)
+	return issue, nil
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -16,44 +14,31 @@ import (
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/ollama"
 	"github.com/tmc/langchaingo/schema"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
 )
 
 const (
 	PROMPT_RESPONSE_TIMEOUT = time.Second * 120
 )
 
-var (
-	TEMPERATURE float64
-)
+var llmConfig = config.Default().LLM
 
-func init() {
-	temp := os.Getenv("MODEL_TEMPERATURE")
-	if len(temp) == 0 {
-		TEMPERATURE = 0.7
-	} else {
-		var err error
-		TEMPERATURE, err = strconv.ParseFloat(temp, 64)
-		if err != nil {
-			TEMPERATURE = 0.7
-		}
-	}
+// SetLLMConfig sets the configuration of the LLM used to generate responses.
+func SetLLMConfig(cfg config.LLMConfig) {
+	llmConfig = cfg
 }
 
 type Prompt string
 
 // GenerateResponse generates a response from an ollama API endpoint
 func GenerateResponse(ctx context.Context, prompt string, conversationContext ...llms.MessageContent) (string, error) {
-	endpoint := os.Getenv("OLLAMA_ENDPOINT")
-	if len(endpoint) == 0 {
+	if len(llmConfig.Endpoint) == 0 {
 		return "", errors.New("OLLAMA_ENDPOINT must be exported")
 	}
 
-	model := os.Getenv("OLLAMA_MODEL")
-	if len(model) == 0 {
-		model = "tinyllama"
-	}
-
-	llm, err := ollama.New(ollama.WithModel(model), ollama.WithServerURL(endpoint))
+	llm, err := ollama.New(ollama.WithModel(llmConfig.Model), ollama.WithServerURL(llmConfig.Endpoint))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -70,11 +55,13 @@ func GenerateResponse(ctx context.Context, prompt string, conversationContext ..
 		},
 	})
 
-	log.Printf("calling model with temp: %f\n", TEMPERATURE)
+	log.Printf("calling model with temp: %f\n", llmConfig.Temperature)
 
+	start := time.Now()
 	response, err := llm.GenerateContent(timedCtx, conversationContext, func(co *llms.CallOptions) {
-		co.Temperature = TEMPERATURE
+		co.Temperature = llmConfig.Temperature
 	})
+	metrics.ObserveLLM(time.Since(start), err)
 	if err != nil {
 		return "", fmt.Errorf("unable to generate response from LLM: %v", err)
 	}
@@ -0,0 +1,298 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// MAX_MESSAGE_BLOCKS the number of blocks Slack accepts in a message.
+	MAX_MESSAGE_BLOCKS = 50
+	// MAX_SECTION_TEXT_LENGTH the length of text Slack accepts in a section block.
+	MAX_SECTION_TEXT_LENGTH = 3000
+	// MAX_MESSAGE_TEXT_LENGTH the length of message text Slack shows without truncating it.
+	MAX_MESSAGE_TEXT_LENGTH = 4000
+	// MAX_RESPONSE_MESSAGES the number of messages a response is split into. Content beyond it is left out.
+	MAX_RESPONSE_MESSAGES = 5
+
+	codeFence           = "```"
+	postMessageEndpoint = "chat.postMessage"
+	// truncationReserve room kept in each message for the truncation notice.
+	truncationReserve = 200
+)
+
+// splittableValues the values of a message which SplitResponse knows how to rebuild. Responses with other values,
+// such as attachments, aren't split.
+var splittableValues = map[string]bool{
+	"token":        true,
+	"channel":      true,
+	"text":         true,
+	"blocks":       true,
+	"unfurl_links": true,
+	"unfurl_media": true,
+	"mrkdwn":       true,
+}
+
+// ResponseBuilder builds a response which fits within Slack's limits. Content which doesn't fit in one message
+// is split across several messages and content beyond MAX_RESPONSE_MESSAGES is replaced with a notice which tells
+// the user the response was truncated.
+type ResponseBuilder struct {
+	text    string
+	blocks  []slack.Block
+	footer  []slack.Block
+	options []slack.MsgOption
+}
+
+// NewResponseBuilder returns an empty response.
+func NewResponseBuilder() *ResponseBuilder {
+	return &ResponseBuilder{}
+}
+
+// Text sets the text of the response. When the response has blocks, the text is only used for notifications.
+func (b *ResponseBuilder) Text(text string) *ResponseBuilder {
+	b.text = text
+	return b
+}
+
+// Blocks adds blocks to the response. Sections with more text than Slack accepts are split into several sections.
+func (b *ResponseBuilder) Blocks(blocks ...slack.Block) *ResponseBuilder {
+	for _, block := range blocks {
+		section, ok := block.(*slack.SectionBlock)
+		if !ok || section.Text == nil || len(section.Text.Text) <= MAX_SECTION_TEXT_LENGTH || len(section.Fields) > 0 || section.Accessory != nil {
+			b.blocks = append(b.blocks, block)
+			continue
+		}
+		for _, chunk := range SplitText(section.Text.Text, MAX_SECTION_TEXT_LENGTH) {
+			b.blocks = append(b.blocks, slack.NewSectionBlock(
+				slack.NewTextBlockObject(section.Text.Type, chunk, section.Text.Emoji, section.Text.Verbatim), nil, nil))
+		}
+	}
+	return b
+}
+
+// Footer sets blocks, such as a Close button, which end every message of the response.
+func (b *ResponseBuilder) Footer(blocks ...slack.Block) *ResponseBuilder {
+	b.footer = blocks
+	return b
+}
+
+// Options adds options, such as disabling link unfurling, which apply to every message of the response.
+func (b *ResponseBuilder) Options(options ...slack.MsgOption) *ResponseBuilder {
+	b.options = append(b.options, options...)
+	return b
+}
+
+// Build returns the messages of the response.
+func (b *ResponseBuilder) Build() [][]slack.MsgOption {
+	var pages [][]slack.MsgOption
+	if len(b.blocks) == 0 {
+		chunks := SplitText(b.text, MAX_MESSAGE_TEXT_LENGTH-truncationReserve)
+		dropped := len(chunks) - MAX_RESPONSE_MESSAGES
+		if dropped > 0 {
+			chunks = chunks[:MAX_RESPONSE_MESSAGES]
+			chunks[len(chunks)-1] = fmt.Sprintf("%s\n\n%s", chunks[len(chunks)-1], truncationNotice(dropped))
+		}
+		for _, chunk := range chunks {
+			pages = append(pages, append([]slack.MsgOption{slack.MsgOptionText(chunk, false)}, b.options...))
+		}
+		return pages
+	}
+
+	capacity := MAX_MESSAGE_BLOCKS - len(b.footer)
+	blockPages := splitBlocks(b.blocks, capacity)
+	dropped := len(blockPages) - MAX_RESPONSE_MESSAGES
+	if dropped > 0 {
+		blockPages = blockPages[:MAX_RESPONSE_MESSAGES]
+		last := blockPages[len(blockPages)-1]
+		if len(last) == capacity {
+			last = last[:len(last)-1]
+		}
+		blockPages[len(blockPages)-1] = append(last, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, truncationNotice(dropped), false, false)))
+	}
+	for index, blocks := range blockPages {
+		page := []slack.MsgOption{slack.MsgOptionBlocks(append(blocks, b.footer...)...)}
+		// the text is only shown in notifications so it isn't repeated for each message
+		if index == 0 && len(b.text) > 0 {
+			page = append(page, slack.MsgOptionText(truncateText(b.text, MAX_MESSAGE_TEXT_LENGTH), false))
+		}
+		pages = append(pages, append(page, b.options...))
+	}
+	return pages
+}
+
+// SplitResponse splits a response which exceeds Slack's limits into several messages, see ResponseBuilder.
+// Responses which fit in one message, or which have content other than text and blocks, are returned as is. A
+// response which ends with an actions block, such as a Close button, has it at the end of every message.
+func SplitResponse(response []slack.MsgOption) [][]slack.MsgOption {
+	endpoint, values, err := slack.UnsafeApplyMsgOptions("", "", "", response...)
+	if err != nil || endpoint != postMessageEndpoint {
+		// responses to response URLs and updates are sent as they are
+		return [][]slack.MsgOption{response}
+	}
+	for key := range values {
+		if !splittableValues[key] {
+			return [][]slack.MsgOption{response}
+		}
+	}
+
+	text := strings.Join(values["text"], "")
+	var blocks slack.Blocks
+	if encoded := values.Get("blocks"); len(encoded) > 0 {
+		if err := json.Unmarshal([]byte(encoded), &blocks); err != nil {
+			log.Warnf("unable to split response: %v", err)
+			return [][]slack.MsgOption{response}
+		}
+	}
+	if fitsInMessage(text, blocks.BlockSet) {
+		return [][]slack.MsgOption{response}
+	}
+
+	builder := NewResponseBuilder().Text(text)
+	if last := len(blocks.BlockSet) - 1; last > 0 && blocks.BlockSet[last].BlockType() == slack.MBTAction {
+		builder.Footer(blocks.BlockSet[last])
+		blocks.BlockSet = blocks.BlockSet[:last]
+	}
+	builder.Blocks(blocks.BlockSet...)
+	switch values.Get("unfurl_links") {
+	case "true":
+		builder.Options(slack.MsgOptionEnableLinkUnfurl())
+	case "false":
+		builder.Options(slack.MsgOptionDisableLinkUnfurl())
+	}
+	if values.Get("unfurl_media") == "false" {
+		builder.Options(slack.MsgOptionDisableMediaUnfurl())
+	}
+	if values.Get("mrkdwn") == "false" {
+		builder.Options(slack.MsgOptionDisableMarkdown())
+	}
+	return builder.Build()
+}
+
+// SplitText splits text into chunks no longer than limit. Text is split between lines where possible. A code
+// block which is split is closed at the end of one chunk and reopened at the start of the next.
+func SplitText(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	inCode := false
+	// the length of the chunk before any content is added to it
+	base := 0
+	flush := func() {
+		chunk := current.String()
+		if inCode {
+			chunk += "\n" + codeFence
+		}
+		chunks = append(chunks, chunk)
+		current.Reset()
+		base = 0
+		if inCode {
+			current.WriteString(codeFence + "\n")
+			base = current.Len()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		opensOrClosesCode := strings.Count(line, codeFence)%2 == 1
+		for len(line) > 0 {
+			// room is kept to close a code block
+			room := limit - current.Len() - len(codeFence) - 1
+			if len(line) <= room {
+				current.WriteString(line)
+				break
+			}
+			if current.Len() > base {
+				flush()
+				continue
+			}
+			// the line is longer than a whole chunk
+			cut := room
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut <= 0 {
+				cut = len(line)
+			}
+			current.WriteString(line[:cut])
+			line = line[cut:]
+			flush()
+		}
+		if opensOrClosesCode {
+			inCode = !inCode
+		}
+	}
+	if current.Len() > base {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// fitsInMessage returns true if the text and blocks can be sent in one message.
+func fitsInMessage(text string, blocks []slack.Block) bool {
+	if len(blocks) == 0 {
+		return len(text) <= MAX_MESSAGE_TEXT_LENGTH
+	}
+	if len(blocks) > MAX_MESSAGE_BLOCKS {
+		return false
+	}
+	for _, block := range blocks {
+		if section, ok := block.(*slack.SectionBlock); ok && section.Text != nil && len(section.Text.Text) > MAX_SECTION_TEXT_LENGTH {
+			return false
+		}
+	}
+	return true
+}
+
+// splitBlocks groups blocks into messages of up to capacity blocks. Dividers aren't kept at the start or end of a
+// message.
+func splitBlocks(blocks []slack.Block, capacity int) [][]slack.Block {
+	var pages [][]slack.Block
+	var current []slack.Block
+	for _, block := range blocks {
+		if len(current) == capacity {
+			pages = append(pages, trimDividers(current))
+			current = nil
+		}
+		if len(current) == 0 && block.BlockType() == slack.MBTDivider {
+			continue
+		}
+		current = append(current, block)
+	}
+	if len(current) > 0 {
+		pages = append(pages, trimDividers(current))
+	}
+	return pages
+}
+
+func trimDividers(blocks []slack.Block) []slack.Block {
+	for len(blocks) > 1 && blocks[len(blocks)-1].BlockType() == slack.MBTDivider {
+		blocks = blocks[:len(blocks)-1]
+	}
+	return blocks
+}
+
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit - len("…")
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "…"
+}
+
+func truncationNotice(dropped int) string {
+	if dropped == 1 {
+		return "_the response was truncated. 1 more message was left out._"
+	}
+	return fmt.Sprintf("_the response was truncated. %d more messages were left out._", dropped)
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// applyPage returns the text and blocks of a message.
+func applyPage(t *testing.T, page []slack.MsgOption) (string, []slack.Block) {
+	t.Helper()
+	_, values, err := slack.UnsafeApplyMsgOptions("", "", "", page...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var blocks slack.Blocks
+	if encoded := values.Get("blocks"); len(encoded) > 0 {
+		if err := json.Unmarshal([]byte(encoded), &blocks); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return values.Get("text"), blocks.BlockSet
+}
+
+func TestSplitText(t *testing.T) {
+	if chunks := SplitText("short", 10); len(chunks) != 1 || chunks[0] != "short" {
+		t.Errorf("expected short text to be kept: %q", chunks)
+	}
+
+	text := "intro\n```\n" + strings.Repeat("some output\n", 20) + "```\noutro"
+	chunks := SplitText(text, 100)
+	if len(chunks) < 3 {
+		t.Fatalf("expected the text to be split, got %q", chunks)
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 100 {
+			t.Errorf("chunk longer than the limit: %q", chunk)
+		}
+		if strings.Count(chunk, "```")%2 != 0 {
+			t.Errorf("expected code blocks to be balanced: %q", chunk)
+		}
+	}
+	if !strings.HasPrefix(chunks[1], "```\n") {
+		t.Errorf("expected the code block to be reopened: %q", chunks[1])
+	}
+
+	long := strings.Repeat("é", 100)
+	for _, chunk := range SplitText(long, 51) {
+		if !strings.HasPrefix(chunk, "é") || len(chunk) > 51 {
+			t.Errorf("expected long lines to be split between runes: %q", chunk)
+		}
+	}
+}
+
+func TestSplitResponse(t *testing.T) {
+	short := StringToBlock("hello", false)
+	if pages := SplitResponse(short); len(pages) != 1 {
+		t.Errorf("expected responses which fit to be kept, got %d messages", len(pages))
+	}
+
+	pages := SplitResponse(StringToBlock(strings.Repeat("a line of text\n", 500), false))
+	if len(pages) != 2 {
+		t.Fatalf("expected long text to be split in 2 messages, got %d", len(pages))
+	}
+	for _, page := range pages {
+		if text, _ := applyPage(t, page); len(text) > MAX_MESSAGE_TEXT_LENGTH {
+			t.Errorf("message text is %d long", len(text))
+		}
+	}
+
+	var blocks []slack.Block
+	for i := 0; i < 120; i++ {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "result", false, false), nil, nil), slack.NewDividerBlock())
+	}
+	pages = SplitResponse([]slack.MsgOption{slack.MsgOptionText("results", false), slack.MsgOptionBlocks(blocks...)})
+	if len(pages) != 5 {
+		t.Fatalf("expected 240 blocks to be split in 5 messages, got %d", len(pages))
+	}
+	if text, _ := applyPage(t, pages[0]); text != "results" {
+		t.Errorf("expected the first message to keep the text: %q", text)
+	}
+	for _, page := range pages {
+		if _, blocks := applyPage(t, page); len(blocks) > MAX_MESSAGE_BLOCKS || blocks[0].BlockType() == slack.MBTDivider {
+			t.Errorf("unexpected message with %d blocks", len(blocks))
+		}
+	}
+
+	var many []slack.Block
+	for i := 0; i < 7*MAX_MESSAGE_BLOCKS; i++ {
+		many = append(many, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "result", false, false), nil, nil))
+	}
+	pages = SplitResponse([]slack.MsgOption{slack.MsgOptionBlocks(many...)})
+	if len(pages) != MAX_RESPONSE_MESSAGES {
+		t.Fatalf("expected the response to be truncated to %d messages, got %d", MAX_RESPONSE_MESSAGES, len(pages))
+	}
+	_, last := applyPage(t, pages[len(pages)-1])
+	notice, ok := last[len(last)-1].(*slack.ContextBlock)
+	if !ok || !strings.Contains(notice.ContextElements.Elements[0].(*slack.TextBlockObject).Text, "2 more messages were left out") {
+		t.Errorf("expected the user to be told the response was truncated: %+v", last[len(last)-1])
+	}
+
+	closeButton := slack.NewActionBlock("", slack.NewButtonBlockElement("close", "", slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false)))
+	pages = SplitResponse([]slack.MsgOption{slack.MsgOptionBlocks(append(blocks[:2*MAX_MESSAGE_BLOCKS+10], closeButton)...)})
+	if len(pages) != 3 {
+		t.Fatalf("expected the blocks and the footer to be split in 3 messages, got %d", len(pages))
+	}
+	for _, page := range pages {
+		if _, blocks := applyPage(t, page); len(blocks) > MAX_MESSAGE_BLOCKS || blocks[len(blocks)-1].BlockType() != slack.MBTAction {
+			t.Errorf("expected each message to end with the Close button: %d blocks", len(blocks))
+		}
+	}
+
+	attachment := []slack.MsgOption{slack.MsgOptionText(strings.Repeat("a", 5000), false), slack.MsgOptionAttachments(slack.Attachment{Text: "attached"})}
+	if pages := SplitResponse(attachment); len(pages) != 1 {
+		t.Errorf("expected responses with attachments to be kept, got %d messages", len(pages))
+	}
+}
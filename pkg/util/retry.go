@@ -0,0 +1,180 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/metrics"
+)
+
+const (
+	// DEFAULT_SLACK_MAX_RETRIES the number of times a call rate limited by Slack is retried.
+	DEFAULT_SLACK_MAX_RETRIES = 3
+	// DEFAULT_SLACK_MAX_RETRY_WAIT the total time a call waits for retries. A call which Slack asks to wait
+	// longer fails instead.
+	DEFAULT_SLACK_MAX_RETRY_WAIT = time.Minute
+)
+
+// RetryingClient retries calls which Slack rejects with a rate_limited error. Each retry waits for the
+// Retry-After period returned by Slack, up to a total wait, unless the client's context is done first.
+type RetryingClient struct {
+	ctx        context.Context
+	client     SlackClientInterface
+	maxRetries int
+	maxWait    time.Duration
+	// wait is replaced in tests
+	wait func(context.Context, time.Duration) error
+}
+
+// NewRetryingClient wraps a client so rate limited calls are retried. Calls stop waiting to be retried once ctx
+// is done.
+func NewRetryingClient(ctx context.Context, client SlackClientInterface) *RetryingClient {
+	return &RetryingClient{
+		ctx:        ctx,
+		client:     client,
+		maxRetries: DEFAULT_SLACK_MAX_RETRIES,
+		maxWait:    DEFAULT_SLACK_MAX_RETRY_WAIT,
+		wait:       waitContext,
+	}
+}
+
+// waitContext waits for d or until ctx is done.
+func waitContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withRetry calls fn until it succeeds, fails with an error other than rate_limited, the retries or the time
+// to wait for them are exhausted, or the client's context is done.
+func withRetry[T any](c *RetryingClient, method string, fn func() (T, error)) (T, error) {
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		result, err := fn()
+		var rateLimited *slack.RateLimitedError
+		if err == nil || !errors.As(err, &rateLimited) || attempt >= c.maxRetries {
+			if err != nil {
+				metrics.SlackAPIError(method)
+			}
+			return result, err
+		}
+		wait := rateLimited.RetryAfter
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if waited+wait > c.maxWait {
+			log.Warnf("%s was rate limited by Slack, not retrying as it would wait more than %s", method, c.maxWait)
+			metrics.SlackAPIError(method)
+			return result, err
+		}
+		log.Warnf("%s was rate limited by Slack, retrying in %s (attempt %d of %d)", method, wait, attempt+1, c.maxRetries)
+		if waitErr := c.wait(c.ctx, wait); waitErr != nil {
+			metrics.SlackAPIError(method)
+			return result, err
+		}
+		waited += wait
+	}
+}
+
+type postResult struct {
+	channel   string
+	timestamp string
+	text      string
+}
+
+func (c *RetryingClient) PostEphemeral(channelID string, userID string, options ...slack.MsgOption) (string, error) {
+	return withRetry(c, "PostEphemeral", func() (string, error) {
+		return c.client.PostEphemeral(channelID, userID, options...)
+	})
+}
+
+func (c *RetryingClient) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	result, err := withRetry(c, "PostMessage", func() (postResult, error) {
+		channel, timestamp, err := c.client.PostMessage(channelID, options...)
+		return postResult{channel: channel, timestamp: timestamp}, err
+	})
+	return result.channel, result.timestamp, err
+}
+
+func (c *RetryingClient) OpenConversation(params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	type result struct {
+		channel     *slack.Channel
+		noOp        bool
+		alreadyOpen bool
+	}
+	r, err := withRetry(c, "OpenConversation", func() (result, error) {
+		channel, noOp, alreadyOpen, err := c.client.OpenConversation(params)
+		return result{channel: channel, noOp: noOp, alreadyOpen: alreadyOpen}, err
+	})
+	return r.channel, r.noOp, r.alreadyOpen, err
+}
+
+func (c *RetryingClient) GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
+	type result struct {
+		msgs       []slack.Message
+		hasMore    bool
+		nextCursor string
+	}
+	r, err := withRetry(c, "GetConversationReplies", func() (result, error) {
+		msgs, hasMore, nextCursor, err := c.client.GetConversationReplies(params)
+		return result{msgs: msgs, hasMore: hasMore, nextCursor: nextCursor}, err
+	})
+	return r.msgs, r.hasMore, r.nextCursor, err
+}
+
+func (c *RetryingClient) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	return withRetry(c, "GetConversationInfo", func() (*slack.Channel, error) {
+		return c.client.GetConversationInfo(input)
+	})
+}
+
+func (c *RetryingClient) GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return withRetry(c, "GetUserGroups", func() ([]slack.UserGroup, error) {
+		return c.client.GetUserGroups(options...)
+	})
+}
+
+func (c *RetryingClient) GetUserGroupMembers(userGroup string) ([]string, error) {
+	return withRetry(c, "GetUserGroupMembers", func() ([]string, error) {
+		return c.client.GetUserGroupMembers(userGroup)
+	})
+}
+
+func (c *RetryingClient) UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	result, err := withRetry(c, "UpdateMessage", func() (postResult, error) {
+		channel, timestamp, text, err := c.client.UpdateMessage(channelID, timestamp, options...)
+		return postResult{channel: channel, timestamp: timestamp, text: text}, err
+	})
+	return result.channel, result.timestamp, result.text, err
+}
+
+func (c *RetryingClient) DeleteMessage(channel, messageTimestamp string) (string, string, error) {
+	result, err := withRetry(c, "DeleteMessage", func() (postResult, error) {
+		channel, timestamp, err := c.client.DeleteMessage(channel, messageTimestamp)
+		return postResult{channel: channel, timestamp: timestamp}, err
+	})
+	return result.channel, result.timestamp, err
+}
+
+func (c *RetryingClient) AddReaction(name string, item slack.ItemRef) error {
+	_, err := withRetry(c, "AddReaction", func() (struct{}, error) {
+		return struct{}{}, c.client.AddReaction(name, item)
+	})
+	return err
+}
+
+func (c *RetryingClient) RemoveReaction(name string, item slack.ItemRef) error {
+	_, err := withRetry(c, "RemoveReaction", func() (struct{}, error) {
+		return struct{}{}, c.client.RemoveReaction(name, item)
+	})
+	return err
+}
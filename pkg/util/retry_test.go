@@ -0,0 +1,83 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// rateLimitedClient is rate limited by Slack a number of times before posting succeeds.
+type rateLimitedClient struct {
+	StubInterface
+	limited int
+	calls   int
+}
+
+func (c *rateLimitedClient) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	c.calls++
+	if c.calls <= c.limited {
+		return "", "", &slack.RateLimitedError{RetryAfter: 2 * time.Second}
+	}
+	return channelID, "1234.5678", nil
+}
+
+func TestRetryingClient(t *testing.T) {
+	inner := &rateLimitedClient{limited: 2}
+	client := NewRetryingClient(context.Background(), inner)
+	var waited time.Duration
+	client.wait = func(ctx context.Context, d time.Duration) error {
+		waited += d
+		return nil
+	}
+
+	_, timestamp, err := client.PostMessage("C1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timestamp != "1234.5678" || inner.calls != 3 || waited != 4*time.Second {
+		t.Errorf("expected two retries honoring Retry-After, calls: %d waited: %s", inner.calls, waited)
+	}
+
+	inner = &rateLimitedClient{limited: DEFAULT_SLACK_MAX_RETRIES + 1}
+	client = NewRetryingClient(context.Background(), inner)
+	client.wait = func(context.Context, time.Duration) error { return nil }
+	_, _, err = client.PostMessage("C1")
+	var rateLimited *slack.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Errorf("expected the rate limit error once retries are exhausted: %v", err)
+	}
+
+	// other errors aren't retried
+	_, err = client.PostEphemeral("C1", "U1")
+	if err == nil || err.Error() != "PostEphemeral" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRetryingClientStopsWaiting(t *testing.T) {
+	// the total wait is capped
+	inner := &rateLimitedClient{limited: 2}
+	client := NewRetryingClient(context.Background(), inner)
+	client.maxWait = 3 * time.Second
+	client.wait = func(context.Context, time.Duration) error { return nil }
+	var rateLimited *slack.RateLimitedError
+	if _, _, err := client.PostMessage("C1"); !errors.As(err, &rateLimited) || inner.calls != 2 {
+		t.Errorf("expected the call to fail once the wait is exhausted, calls: %d err: %v", inner.calls, err)
+	}
+
+	// retries stop when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inner = &rateLimitedClient{limited: 1}
+	client = NewRetryingClient(ctx, inner)
+	start := time.Now()
+	if _, _, err := client.PostMessage("C1"); !errors.As(err, &rateLimited) || inner.calls != 1 {
+		t.Errorf("expected the call not to be retried once the context is done, calls: %d err: %v", inner.calls, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the call not to wait for Retry-After, waited %s", elapsed)
+	}
+}
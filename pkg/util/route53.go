@@ -3,7 +3,6 @@ package util
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,7 +12,7 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func InvokeRecordActionsFromVIPS(ctx context.Context, action types.ChangeAction, vips []string, domainName string) error {
+func InvokeRecordActionsFromVIPS(ctx context.Context, action types.ChangeAction, vips []string, domainName, hostedZoneID string) error {
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
 		log.Printf("failed to load configuration, %v", err)
@@ -22,9 +21,6 @@ func InvokeRecordActionsFromVIPS(ctx context.Context, action types.ChangeAction,
 	// Create a Route 53 client.
 	svc := route53.NewFromConfig(cfg)
 
-	// Specify the hosted zone ID and the domain name.
-	hostedZoneID := os.Getenv("HOSTED_ZONE_ID")
-
 	recordType := types.RRTypeA // The record type you want to check (e.g., A, CNAME, TXT).
 
 	// List the records and check if the specific record exists.
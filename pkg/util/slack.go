@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -8,17 +9,25 @@ import (
 	"os"
 	"strings"
 
-	"github.com/openshift-splat-team/jira-bot/pkg/util"
 	logrus "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
 )
 
 var (
-// obfuscators = []obfuscator.ReportingObfuscator{}
+	// obfuscators = []obfuscator.ReportingObfuscator{}
+
+	slackConfig = config.Default().Slack
 )
 
+// SetSlackConfig sets the configuration used to connect to Slack and recognize mentions of the bot.
+func SetSlackConfig(cfg config.SlackConfig) {
+	slackConfig = cfg
+}
+
 func init() {
 	// tracker := obfuscator.NewSimpleTracker()
 	// newObfuscator, err := obfuscator.NewIPObfuscator(schema.ObfuscateReplacementTypeConsistent, tracker)
@@ -42,7 +51,7 @@ func init() {
 }
 
 func GetClient() (*socketmode.Client, error) {
-	appToken := os.Getenv("SLACK_APP_TOKEN")
+	appToken := slackConfig.AppToken
 	if appToken == "" {
 		return nil, errors.New("SLACK_APP_TOKEN must be set")
 
@@ -52,7 +61,7 @@ func GetClient() (*socketmode.Client, error) {
 		return nil, errors.New("SLACK_APP_TOKEN must have the prefix \"xapp-\"")
 	}
 
-	botToken := os.Getenv("SLACK_BOT_TOKEN")
+	botToken := slackConfig.BotToken
 	if botToken == "" {
 		return nil, errors.New("SLACK_BOT_TOKEN must be set")
 	}
@@ -61,11 +70,6 @@ func GetClient() (*socketmode.Client, error) {
 		return nil, errors.New("SLACK_BOT_TOKEN must have the prefix \"xoxb-\"")
 	}
 
-	err := util.BindEnvVars()
-	if err != nil {
-		return nil, fmt.Errorf("unable to bind env vars: %v", err)
-	}
-
 	api := slack.New(
 		botToken,
 		slack.OptionDebug(true),
@@ -86,7 +90,12 @@ func GetSlackClient() (SlackClientInterface, error) {
 		testClient := &StubInterface{}
 		return testClient, nil
 	} else {
-		return GetClient()
+		client, err := GetClient()
+		if err != nil {
+			return nil, err
+		}
+		// the client is used for the life of the process, so retries are only bounded by the total wait
+		return NewRetryingClient(context.Background(), client), nil
 	}
 
 }
@@ -120,9 +129,8 @@ func AnonymizeMessages(msgs []slack.Message) []slack.Message {
 
 func GetThreadUrl(event *slackevents.MessageEvent) string {
 	if event.ThreadTimeStamp != "" {
-		workspace := "redhat-internal" // Replace with your Slack workspace name
 		threadURL := fmt.Sprintf("https://%s.slack.com/archives/%s/p%s",
-			workspace, event.Channel, strings.Replace(event.ThreadTimeStamp, ".", "", 1))
+			slackConfig.Workspace, event.Channel, strings.Replace(event.ThreadTimeStamp, ".", "", 1))
 
 		return threadURL
 	}
@@ -130,8 +138,8 @@ func GetThreadUrl(event *slackevents.MessageEvent) string {
 }
 
 func IsSPLATBotID(botID string) bool {
-	userID, ok := os.LookupEnv("SPLAT_BOT_USER_ID")
-	if !ok {
+	userID := slackConfig.BotUserID
+	if len(userID) == 0 {
 		logrus.Warn("no bot user id specified with SPLAT_BOT_USER_ID")
 		return false
 	}
@@ -139,8 +147,8 @@ func IsSPLATBotID(botID string) bool {
 }
 
 func ContainsBotMention(messageText string) bool {
-	userID, ok := os.LookupEnv("SPLAT_BOT_USER_ID")
-	if !ok {
+	userID := slackConfig.BotUserID
+	if len(userID) == 0 {
 		logrus.Warn("no bot user id specified with SPLAT_BOT_USER_ID")
 		return false
 	}
@@ -174,6 +182,13 @@ func StringsToBlockUnfurl(messages []string, useMarkdown, unfurlLinks bool) []sl
 }
 
 func StringsToBlockWithURLs(messages []string, urls []string) []slack.MsgOption {
+	return []slack.MsgOption{
+		slack.MsgOptionBlocks(BlocksWithURLs(messages, urls)...),
+	}
+}
+
+// BlocksWithURLs returns a section for each message followed by the relevant links.
+func BlocksWithURLs(messages []string, urls []string) []slack.Block {
 	messageBlocks := []slack.Block{}
 
 	for _, message := range messages {
@@ -199,9 +214,7 @@ func StringsToBlockWithURLs(messages []string, urls []string) []slack.MsgOption
 			),
 		)
 	}
-	return []slack.MsgOption{
-		slack.MsgOptionBlocks(messageBlocks...),
-	}
+	return messageBlocks
 }
 
 func StringToBlockUnfurl(message string, useMarkdown, unfurlLinks bool) []slack.MsgOption {
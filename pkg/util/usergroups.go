@@ -0,0 +1,158 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift-splat-team/splat-bot/pkg/config"
+)
+
+type userGroupMembers struct {
+	users   map[string]bool
+	fetched time.Time
+}
+
+// UserGroupResolver resolves the membership of Slack user groups. Membership is cached for a TTL and is
+// updated when Slack reports that the members of a group changed.
+type UserGroupResolver struct {
+	client SlackClientInterface
+	ttl    time.Duration
+
+	mu sync.Mutex
+	// handles maps user group handles, such as splat-team, and IDs to user group IDs.
+	handles map[string]string
+	// missing maps the groups which weren't found to when they were looked up, so a misconfigured group isn't
+	// looked up on every check.
+	missing map[string]time.Time
+	// members maps user group IDs to their members.
+	members map[string]*userGroupMembers
+}
+
+// NewUserGroupResolver returns a resolver which caches membership for ttl.
+func NewUserGroupResolver(client SlackClientInterface, ttl time.Duration) *UserGroupResolver {
+	if ttl <= 0 {
+		ttl = config.DEFAULT_SLACK_GROUP_CACHE_TTL
+	}
+	return &UserGroupResolver{
+		client:  client,
+		ttl:     ttl,
+		handles: map[string]string{},
+		missing: map[string]time.Time{},
+		members: map[string]*userGroupMembers{},
+	}
+}
+
+// cachedGroupID returns the ID of a user group if it's cached. found is false if the group wasn't found when it was
+// last looked up. r.mu must be held.
+func (r *UserGroupResolver) cachedGroupID(handle string) (id string, found, ok bool) {
+	if id, ok := r.handles[handle]; ok {
+		return id, true, true
+	}
+	if _, ok := r.members[handle]; ok {
+		return handle, true, true
+	}
+	if lookedUp, ok := r.missing[handle]; ok && time.Since(lookedUp) <= r.ttl {
+		return "", false, true
+	}
+	return "", false, false
+}
+
+// resolveGroupID returns the ID of a user group. group may be an ID or a handle, with or without a leading @.
+// The user groups are listed without holding r.mu.
+func (r *UserGroupResolver) resolveGroupID(group string) (string, error) {
+	handle := strings.TrimPrefix(group, "@")
+	r.mu.Lock()
+	id, found, ok := r.cachedGroupID(handle)
+	r.mu.Unlock()
+	if ok {
+		if !found {
+			return "", fmt.Errorf("user group %s not found", group)
+		}
+		return id, nil
+	}
+
+	groups, err := r.client.GetUserGroups()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user groups: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, userGroup := range groups {
+		r.handles[userGroup.Handle] = userGroup.ID
+		r.handles[userGroup.ID] = userGroup.ID
+	}
+	if id, ok := r.handles[handle]; ok {
+		delete(r.missing, handle)
+		return id, nil
+	}
+	r.missing[handle] = time.Now()
+	return "", fmt.Errorf("user group %s not found", group)
+}
+
+// IsMember returns true if the user is a member of the user group. group may be an ID or a handle. Slack is
+// called without holding r.mu so a slow call doesn't hold up checks of cached groups.
+func (r *UserGroupResolver) IsMember(group, user string) (bool, error) {
+	id, err := r.resolveGroupID(group)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	cached, ok := r.members[id]
+	if ok && time.Since(cached.fetched) <= r.ttl {
+		member := cached.users[user]
+		r.mu.Unlock()
+		return member, nil
+	}
+	r.mu.Unlock()
+
+	users, err := r.client.GetUserGroupMembers(id)
+	if err != nil {
+		return false, fmt.Errorf("unable to get members of user group %s: %v", group, err)
+	}
+	cached = &userGroupMembers{
+		users:   map[string]bool{},
+		fetched: time.Now(),
+	}
+	for _, member := range users {
+		cached.users[member] = true
+	}
+	r.mu.Lock()
+	r.members[id] = cached
+	r.mu.Unlock()
+	log.Debugf("cached %d members of user group %s", len(users), group)
+	return cached.users[user], nil
+}
+
+// HandleMembersChanged applies a subteam_members_changed event to the cache.
+func (r *UserGroupResolver) HandleMembersChanged(evt *slackevents.SubteamMembersChangedEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached, ok := r.members[evt.SubteamID]
+	if !ok {
+		return
+	}
+	for _, user := range evt.AddedUsers {
+		cached.users[user] = true
+	}
+	for _, user := range evt.RemovedUsers {
+		delete(cached.users, user)
+	}
+	log.Infof("user group %s changed. added: %v, removed: %v", evt.SubteamID, evt.AddedUsers, evt.RemovedUsers)
+}
+
+// GroupMention returns the Slack mention for a user group handle or ID.
+func GroupMention(group string) string {
+	group = strings.TrimPrefix(group, "@")
+	if strings.HasPrefix(group, "S") && strings.ToUpper(group) == group {
+		return fmt.Sprintf("<!subteam^%s>", group)
+	}
+	return fmt.Sprintf("@%s", group)
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// countingClient counts the calls listing user groups.
+type countingClient struct {
+	StubInterface
+	listed int
+}
+
+func (c *countingClient) GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	c.listed++
+	return c.StubInterface.GetUserGroups(options...)
+}
+
+func TestUserGroupResolver(t *testing.T) {
+	resolver := NewUserGroupResolver(&StubInterface{}, 0)
+
+	for _, group := range []string{"splat-team", "@splat-team", "S0SPLAT"} {
+		member, err := resolver.IsMember(group, "alloweduser1")
+		if err != nil {
+			t.Fatalf("unable to resolve %s: %v", group, err)
+		}
+		if !member {
+			t.Errorf("expected alloweduser1 to be a member of %s", group)
+		}
+	}
+
+	if _, err := resolver.IsMember("not-a-team", "alloweduser1"); err == nil {
+		t.Errorf("expected unknown group to fail to resolve")
+	}
+
+	resolver.HandleMembersChanged(&slackevents.SubteamMembersChangedEvent{
+		SubteamID:    "S0SPLAT",
+		AddedUsers:   []string{"newuser"},
+		RemovedUsers: []string{"alloweduser1"},
+	})
+	if member, _ := resolver.IsMember("splat-team", "newuser"); !member {
+		t.Errorf("expected added user to be a member")
+	}
+	if member, _ := resolver.IsMember("splat-team", "alloweduser1"); member {
+		t.Errorf("expected removed user not to be a member")
+	}
+}
+
+func TestUserGroupResolverCachesMissingGroups(t *testing.T) {
+	client := &countingClient{}
+	resolver := NewUserGroupResolver(client, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := resolver.IsMember("not-a-team", "alloweduser1"); err == nil {
+			t.Fatalf("expected unknown group to fail to resolve")
+		}
+	}
+	if client.listed != 1 {
+		t.Errorf("expected the user groups to be listed once, got %d", client.listed)
+	}
+
+	// a group which wasn't found is looked up again once the TTL expires
+	resolver.missing["not-a-team"] = time.Now().Add(-2 * time.Hour)
+	if _, err := resolver.IsMember("not-a-team", "alloweduser1"); err == nil {
+		t.Fatalf("expected unknown group to fail to resolve")
+	}
+	if client.listed != 2 {
+		t.Errorf("expected the user groups to be listed again after the TTL, got %d", client.listed)
+	}
+}
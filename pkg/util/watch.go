@@ -0,0 +1,58 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+// WatchFiles calls reload when files in the directories added by watch change until ctx is done. reload is called
+// once the changes have settled for delay, so a change to several files, such as a ConfigMap update or a git pull,
+// reloads once. watch is called again before each reload so that directories created since are watched too. name
+// describes what's watched in logs.
+func WatchFiles(ctx context.Context, name string, delay time.Duration, watch func(*fsnotify.Watcher) error, reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create %s watcher: %v", name, err)
+	}
+	if err := watch(watcher); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		timer := time.NewTimer(delay)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				log.Debugf("%s watcher event: %v", name, event)
+				timer.Reset(delay)
+			case <-timer.C:
+				if err := watch(watcher); err != nil {
+					log.Warnf("unable to watch %s: %v", name, err)
+				}
+				reload()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("%s watcher error: %v", name, err)
+			}
+		}
+	}()
+	return nil
+}